@@ -4,61 +4,126 @@ Copyright © 2025 Guilli guiliankasandikromo@gmail.com
 package cmd
 
 import (
-	"fmt"
-	"log"
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/jubel075/cli-cobra/todo"
 	"github.com/spf13/cobra"
 )
 
-var priority int
-
-// addCmd represents the add command
-var addCmd = &cobra.Command{
-	Use:   "add",
-	Short: "Add a new task to your to-do list",
-	Long: `The add command lets you create a new task and store it in your to-do list.
+// newAddCmd builds the add command.
+func newAddCmd(a *app) *cobra.Command {
+	var (
+		priority int
+		due      string
+		assignee string
+		fromFile string
+	)
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a new task to your to-do list",
+		Long: `The add command lets you create a new task and store it in your to-do list.
 
 You can provide a short description of the task directly as an argument.
 Optionally, you can attach metadata such as priority, due date, or tags
 using flags. Tasks are saved in your local database or file storage so
 they persist between sessions.
 
+To add many tasks at once, read them one per line from a file with
+--from-file, or from standard input by passing "-" as the task. Blank
+lines and lines starting with # are skipped.
+
 Examples:
   mytodo add "Buy groceries"
   mytodo add "Finish project report" --priority high --due tomorrow
   mytodo add "Call Alice" --tag personal --tag urgent
+  mytodo add --from-file tasks.txt
+  grep -h TODO notes/*.md | mytodo add -`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && fromFile == "" {
+				return a.loc.Error(`nothing to add: give the task as an argument, "-" to read it from standard input, or --from-file`)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			var dueDate time.Time
+			if due != "" {
+				dueDate, err = a.parseDate(due)
+				if err != nil {
+					return err
+				}
+			}
+			if assignee == "" {
+				assignee = a.User
+			}
+			texts, err := a.taskTexts(args, fromFile, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			state := a.workflow().Initial()
+			for _, x := range texts {
+				item := todo.Item{Text: x, State: state, Due: dueDate, Assignee: assignee, CreatedBy: a.User}
+				item.SetPtiority(priority)
+				items = append(items, item)
+				a.loc.Fprintf(cmd.OutOrStdout(), "Added task: %q\n", item.Text)
+			}
+			return a.save(items)
+		},
+	}
+	cmd.Flags().IntVarP(&priority, "priority", "p", 2, "Priority of the task (1=high, 2=medium, 3=low)")
+	cmd.Flags().StringVar(&assignee, "assignee", "", "User the task is assigned to (default is you)")
+	cmd.Flags().StringVarP(&fromFile, "from-file", "f", "", "Read tasks from a file, one per line")
+	cmd.Flags().StringVar(&due, "due", "", "Due date: today, tomorrow, a weekday, 3d, 2w or a date in the configured date_format")
+	return cmd
+}
 
-If no description is provided, the command will prompt you to enter one interactively.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		var items = []todo.Item{}
-		items, err := todo.ReadItems(dataFile)
+// taskTexts collects the tasks to add: the arguments, with "-" standing
+// for the lines of stdin, followed by the lines of file if given.
+func (a *app) taskTexts(args []string, file string, stdin io.Reader) ([]string, error) {
+	var texts []string
+	for _, arg := range args {
+		if arg != "-" {
+			texts = append(texts, arg)
+			continue
+		}
+		lines, err := readLines(stdin)
 		if err != nil {
-			log.Printf("%v", err)
+			return nil, a.loc.Errorf("reading stdin: %w", err)
 		}
-		for _, x := range args {
-			item := todo.Item{Text: x}
-			item.SetPtiority(priority)
-			items = append(items, item)
-			fmt.Println("Added task:", item)
+		texts = append(texts, lines...)
+	}
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
 		}
-		err = todo.SaveItems(dataFile, items)
+		defer f.Close()
+		lines, err := readLines(f)
 		if err != nil {
-			log.Printf("%v", err)
+			return nil, a.loc.Errorf("reading %s: %w", file, err)
 		}
-	},
+		texts = append(texts, lines...)
+	}
+	return texts, nil
 }
 
-func init() {
-	rootCmd.AddCommand(addCmd)
-	addCmd.Flags().IntVarP(&priority, "priority", "p", 2, "Priority of the task (1=high, 2=medium, 3=low)")
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// addCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// addCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+// readLines returns the non-blank lines of r that are not # comments.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, s.Err()
 }
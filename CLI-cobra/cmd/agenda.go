@@ -0,0 +1,154 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jubel075/cli-cobra/render"
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/cobra"
+)
+
+// newAgendaCmd builds the agenda command.
+func newAgendaCmd(a *app) *cobra.Command {
+	var (
+		top      int
+		markdown bool
+	)
+	cmd := &cobra.Command{
+		Use:   "agenda",
+		Short: "Show a focused plan for today",
+		Long: `The agenda command prints a short plan for the day: tasks that are
+overdue, tasks due today, the next most urgent tasks and the timers that are
+running. Snoozed and finished tasks are left out.
+
+Urgency weighs the priority of a task, how close its due date is, whether
+it is in progress and whether its timer runs.
+
+With --markdown the plan is printed as a Markdown checklist, ready to paste
+into stand-up notes.
+
+Examples:
+  cli-cobra agenda
+  cli-cobra agenda --top 3
+  cli-cobra agenda --markdown | pbcopy`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			sort.Sort(todo.ByPriority(items))
+
+			out := cmd.OutOrStdout()
+			o := a.renderOptions(out, false)
+			now := o.Now
+			var overdue, today, next, running []todo.Item
+			for _, i := range items {
+				if i.Running() {
+					running = append(running, i)
+				}
+				switch {
+				case i.Done || i.Waiting(now):
+				case i.Overdue(now):
+					overdue = append(overdue, i)
+				case i.DueToday(now):
+					today = append(today, i)
+				default:
+					next = append(next, i)
+				}
+			}
+			sort.SliceStable(next, func(x, y int) bool {
+				return next[x].Urgency(o.Workflow, now) > next[y].Urgency(o.Workflow, now)
+			})
+			next = next[:min(max(top, 0), len(next))]
+
+			p := plan{w: out, markdown: markdown, o: o}
+			p.title(o.Locale.Sprintf("Agenda for %s", o.Locale.Format(now, "Mon "+o.DateLayout)))
+			p.section("Overdue", overdue)
+			p.section("Due today", today)
+			p.section("Next up", next)
+			p.section("Running timers", running)
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&top, "top", "n", 5, "Number of most urgent tasks to show besides those that are due")
+	cmd.Flags().BoolVar(&markdown, "markdown", false, "Print the agenda as Markdown")
+	return cmd
+}
+
+// plan writes the output of agenda and review as plain text or Markdown.
+type plan struct {
+	w        io.Writer
+	markdown bool
+	o        render.Options
+}
+
+func (p plan) title(s string) {
+	if p.markdown {
+		fmt.Fprintf(p.w, "## %s\n", s)
+		return
+	}
+	fmt.Fprintln(p.w, s)
+}
+
+// section writes a translated heading and a line per item, or "nothing".
+func (p plan) section(name string, items []todo.Item) {
+	name = p.o.Locale.T(name)
+	if p.markdown {
+		fmt.Fprintf(p.w, "\n### %s\n", name)
+	} else {
+		fmt.Fprintf(p.w, "\n%s\n", name)
+	}
+	if len(items) == 0 {
+		p.bullet(p.o.Locale.T("nothing"))
+	}
+	for _, i := range items {
+		if p.markdown {
+			fmt.Fprintf(p.w, "- %s %s\n", strings.TrimSpace(i.PrettyDone()), markdownEscape(itemSummary(i, p.o)))
+		} else {
+			fmt.Fprintf(p.w, "  %s%s\n", i.Label(), itemSummary(i, p.o))
+		}
+	}
+}
+
+func (p plan) bullet(s string) {
+	if p.markdown {
+		fmt.Fprintf(p.w, "- %s\n", markdownEscape(s))
+		return
+	}
+	fmt.Fprintf(p.w, "  %s\n", s)
+}
+
+// itemSummary describes an item in one line: its text followed by its
+// priority, due date, state when in progress, and running timer.
+func itemSummary(i todo.Item, o render.Options) string {
+	l := o.Locale
+	details := []string{l.T(i.PriorityIn(o.Scheme))}
+	switch days := i.DaysUntilDue(o.Now); {
+	case i.Due.IsZero():
+	case i.Overdue(o.Now):
+		details = append(details, l.Sprintf("due %s, %s overdue", l.Format(i.Due, o.DateLayout), l.Plural(-days, "1 day", "%d days")))
+	case days == 0:
+		details = append(details, l.T("due today"))
+	default:
+		details = append(details, l.Sprintf("due %s", l.Format(i.Due, o.DateLayout)))
+	}
+	if s := i.StateIn(o.Workflow); s != o.Workflow.Initial() && s != o.Workflow.Final() {
+		details = append(details, s)
+	}
+	if i.Running() {
+		details = append(details, l.Sprintf("timer running for %s", formatDuration(o.Now.Sub(i.Started))))
+	}
+	return fmt.Sprintf("%s (%s)", i.Text, strings.Join(details, ", "))
+}
+
+// markdownEscape keeps task text from being read as Markdown markup.
+var markdownEscape = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`,
+).Replace
@@ -0,0 +1,46 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// newAssignCmd builds the assign command.
+func newAssignCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "assign <id> <user>",
+		Short: "Assign a task to a user",
+		Long: `The assign command hands a task over to someone else, which is useful
+when a team shares one task file on a network mount.
+
+Use "me" as the user to take a task yourself.
+
+Examples:
+  cli-cobra assign 3 alice
+  cli-cobra assign 3 me
+  cli-cobra list --assignee alice`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			i, err := a.taskIndex(args[0], items)
+			if err != nil {
+				return err
+			}
+			who := args[1]
+			if who == "me" {
+				who = a.User
+			}
+			items[i].Assignee = who
+			if err := a.save(items); err != nil {
+				return err
+			}
+			a.loc.Fprintf(cmd.OutOrStdout(), "%q assigned to %s\n", items[i].Text, who)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"github.com/jubel075/cli-cobra/render"
+	"github.com/spf13/cobra"
+)
+
+// newBoardCmd builds the board command.
+func newBoardCmd(a *app) *cobra.Command {
+	var wrap bool
+	cmd := &cobra.Command{
+		Use:   "board",
+		Short: "Show tasks as a Kanban board",
+		Long: `The board command shows one column per workflow state, side by side,
+sized to fit the terminal. Each column header shows how many tasks it
+holds and, when a WIP limit is configured, the limit as well. Columns over
+their limit are highlighted and listed as warnings below the board.
+
+Examples:
+  cli-cobra board
+  cli-cobra board --wrap
+  cli-cobra move 4 doing`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			o := a.renderOptions(out, wrap)
+			if err := render.Board(out, items, o); err != nil {
+				return err
+			}
+
+			wf := o.Workflow
+			for _, state := range wf.OverLimit(items) {
+				a.loc.Fprintf(cmd.ErrOrStderr(), "Warning: %s holds %d tasks, over its WIP limit of %d\n", state, wf.Count(items, state), wf.WIP[state])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&wrap, "wrap", "w", false, "Wrap long tasks instead of truncating them")
+	return cmd
+}
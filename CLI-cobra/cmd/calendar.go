@@ -0,0 +1,107 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"github.com/jubel075/cli-cobra/render"
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/cobra"
+)
+
+// Tasks listed per day before the rest is summed up, when --lines is not
+// given.
+const (
+	monthLines = 3
+	weekLines  = 8
+)
+
+// newCalendarCmd builds the calendar command.
+func newCalendarCmd(a *app) *cobra.Command {
+	var (
+		month string
+		week  bool
+		lines int
+		all   bool
+	)
+	cmd := &cobra.Command{
+		Use:     "calendar [date]",
+		Aliases: []string{"cal"},
+		Short:   "Show tasks on a month or week calendar",
+		Long: `The calendar command draws a month as a grid of weeks, Monday first,
+with every pending task listed on its due date and colored by priority.
+Today is marked, overdue tasks are highlighted, and a day with more tasks
+than fit ends in "+N more".
+
+--month picks another month, as 2026-11 or relative to this one as +1 or
+-1. --week shows a single week instead, with room for more tasks per day.
+A date argument, in any form --due accepts, shows the month or week
+holding that day.
+
+Examples:
+  cli-cobra calendar
+  cli-cobra calendar --month 2026-11
+  cli-cobra calendar --month +1
+  cli-cobra calendar --week
+  cli-cobra calendar --week 2026-11-09
+  cli-cobra cal --week 1w --all`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if month != "" && (week || len(args) > 0) {
+				return a.loc.Error("--month cannot be combined with --week or a date")
+			}
+
+			day := a.Now()
+			if month != "" {
+				first, err := a.parseMonth(month)
+				if err != nil {
+					return err
+				}
+				day = first
+			}
+			if len(args) > 0 {
+				var err error
+				if day, err = a.parseDate(args[0]); err != nil {
+					return err
+				}
+			}
+			p := render.MonthPeriod(day, monthLines)
+			if week {
+				p = render.WeekPeriod(day, weekLines)
+			}
+			if cmd.Flags().Changed("lines") {
+				p.Lines = lines
+			}
+
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			var shown []todo.Item
+			undated := 0
+			for _, item := range items {
+				switch {
+				case item.Done && !all:
+				case item.Due.IsZero():
+					undated++
+				default:
+					shown = append(shown, item)
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			if err := render.Calendar(out, shown, p, a.renderOptions(out, false)); err != nil {
+				return err
+			}
+			if undated > 0 {
+				a.loc.Fprintf(out, "\n%s without a due date not shown.\n", a.loc.Plural(undated, "1 task", "%d tasks"))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&month, "month", "m", "", "Month to show, as 2026-11, +1 or -1 (default this month)")
+	cmd.Flags().BoolVarP(&week, "week", "w", false, "Show a week instead of a month")
+	cmd.Flags().IntVarP(&lines, "lines", "n", 0, "Tasks listed per day, 0 for all (default 3 for a month, 8 for a week)")
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Also show completed tasks")
+	return cmd
+}
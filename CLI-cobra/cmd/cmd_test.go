@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jubel075/cli-cobra/config"
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/viper"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// now is the clock every test runs at, a Saturday.
+var now = time.Date(2026, time.March, 14, 9, 30, 0, 0, time.UTC)
+
+func day(d int) time.Time {
+	return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC)
+}
+
+// seed is the task list the done and list tests start from.
+func seed() []todo.Item {
+	return []todo.Item{
+		{ID: "a1", Text: "Write the report", Priority: 1, State: "doing", Due: day(12), Assignee: "tester"},
+		{ID: "b2", Text: "Water the plants", Priority: 3, State: "todo", Assignee: "alice"},
+		{ID: "c3", Text: "Book the train", Priority: 2, State: "done", Done: true, Assignee: "tester"},
+		{ID: "d4", Text: "Review the budget", Priority: 2, State: "backlog", Due: day(20), Assignee: "tester"},
+	}
+}
+
+// session runs commands against one store and records a transcript of
+// what they print, for comparison with a golden file.
+type session struct {
+	t      *testing.T
+	store  *todo.MemStore
+	config map[string]string
+	now    time.Time
+	// plugins is the plugin search path, empty for none.
+	plugins string
+	// interrupt, when set, is when the next Sleep past it is cut short as
+	// if by Ctrl-C.
+	interrupt time.Time
+	// asleep, when set, runs during each Sleep, as another terminal would.
+	asleep func()
+	out    strings.Builder
+}
+
+func newSession(t *testing.T, items []todo.Item) *session {
+	return &session{t: t, store: &todo.MemStore{Items: items}, now: now, plugins: t.TempDir()}
+}
+
+// advance moves the session clock forward by d.
+func (s *session) advance(d time.Duration) {
+	s.now = s.now.Add(d)
+	s.out.WriteString("# " + s.now.Format("Mon 2006-01-02 15:04") + "\n")
+}
+
+// sleep advances the session clock instead of waiting.
+func (s *session) sleep(ctx context.Context, d time.Duration) error {
+	if s.asleep != nil {
+		s.asleep()
+	}
+	if !s.interrupt.IsZero() && !s.now.Add(d).Before(s.interrupt) {
+		s.now, s.interrupt = s.interrupt, time.Time{}
+		return context.Canceled
+	}
+	s.now = s.now.Add(d)
+	return nil
+}
+
+// run executes one command line, with stdin as its standard input.
+func (s *session) run(stdin string, args ...string) {
+	v := viper.New()
+	for k, val := range s.config {
+		v.Set(k, val)
+	}
+	var stdout, stderr bytes.Buffer
+	root := NewRootCmd(Options{
+		In:         strings.NewReader(stdin),
+		Out:        &stdout,
+		Err:        &stderr,
+		Store:      s.store,
+		Now:        func() time.Time { return s.now },
+		Sleep:      s.sleep,
+		User:       "tester",
+		Config:     v,
+		PluginPath: s.plugins,
+	})
+	root.SetArgs(args)
+	err := root.Execute()
+
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = a
+		if a == "" || strings.ContainsAny(a, " \"'") {
+			quoted[i] = strconv.Quote(a)
+		}
+	}
+	s.out.WriteString("$ cli-cobra " + strings.Join(quoted, " ") + "\n")
+	s.out.WriteString(stdout.String())
+	for _, line := range strings.SplitAfter(stderr.String(), "\n") {
+		if line != "" {
+			s.out.WriteString("stderr: " + line)
+		}
+	}
+	if err != nil {
+		s.out.WriteString("error: " + err.Error() + "\n")
+	}
+}
+
+// check compares the transcript with testdata/<name>.golden, or rewrites
+// the file when the tests run with -update.
+func (s *session) check(name string) {
+	s.t.Helper()
+	golden := filepath.Join("testdata", name+".golden")
+	got := s.out.String()
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			s.t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		s.t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		s.t.Errorf("output differs from %s:\n--- got\n%s--- want\n%s", golden, got, want)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	s := newSession(t, nil)
+	s.run("", "add", "Buy milk")
+	s.run("", "add", "Fix the bike", "--priority", "1", "--due", "tomorrow")
+	s.run("", "add", "Call the bank", "--assignee", "alice", "--due", "2026-03-20", "-p", "3")
+	s.run("Pack bags\n\n# not a task\nCheck tickets\n", "add", "-")
+	s.run("", "add", "Never added", "--due", "someday")
+	s.run("", "add")
+	s.run("", "list", "--columns", "label,priority,task,state,assignee,due")
+	s.check("add")
+
+	if n := len(s.store.Items); n != 5 {
+		t.Fatalf("store holds %d items, want 5", n)
+	}
+	for _, item := range s.store.Items {
+		if item.CreatedBy != "tester" {
+			t.Errorf("%q created by %q, want tester", item.Text, item.CreatedBy)
+		}
+	}
+}
+
+func TestDone(t *testing.T) {
+	s := newSession(t, seed())
+	s.run("", "done", "1")
+	s.run("", "list", "--columns", "label,task,status,state")
+	// The last task used to be out of range.
+	s.run("", "done", "4")
+	s.run("", "do", "0")
+	s.run("", "done", "first")
+	s.run("", "done")
+	s.run("", "list", "--done", "-o", "plain")
+	s.check("done")
+}
+
+func TestList(t *testing.T) {
+	s := newSession(t, seed())
+	s.run("", "list")
+	s.run("", "list", "--pending")
+	s.run("", "list", "--done")
+	s.run("", "list", "--mine", "--columns", "label,task,assignee")
+	s.run("", "list", "--assignee", "alice", "-o", "plain")
+	s.run("", "list", "--state", "doing", "-o", "json")
+	s.run("", "list", "--state", "someday")
+	s.run("", "list", "--columns", "label,oops")
+	s.check("list")
+}
+
+func TestListSettings(t *testing.T) {
+	s := newSession(t, seed())
+	s.config = map[string]string{
+		config.KeyDefaultList:    "pending",
+		config.KeyPriorityScheme: "letters",
+		config.KeyDateFormat:     "eu",
+		config.KeyColor:          "always",
+		config.KeyTheme:          "mono",
+	}
+	s.run("", "list")
+	s.config[config.KeyStates] = "open,closed"
+	s.run("", "list", "--columns", "label,task,state")
+	s.config[config.KeyOutput] = "plain"
+	s.run("", "list", "--all")
+	s.config[config.KeyPriorityScheme] = "roman"
+	s.run("", "list")
+	s.check("list-settings")
+}
+
+func TestSnooze(t *testing.T) {
+	s := newSession(t, seed())
+	s.run("", "snooze", "2", "monday")
+	s.run("", "snooze", "4", "3d")
+	s.run("", "list")
+	s.run("", "list", "--waiting", "--columns", "label,task,wait")
+	s.run("", "list", "--all", "-o", "plain")
+	s.run("", "defer", "4", "today")
+	s.run("", "list", "--pending", "-o", "plain")
+	s.run("", "snooze", "1", "whenever")
+	s.run("", "snooze", "9", "2w")
+	s.advance(48 * time.Hour)
+	s.run("", "list", "--waiting", "-o", "plain")
+	s.run("", "list", "--pending", "-o", "plain")
+	s.check("snooze")
+}
+
+func TestAgenda(t *testing.T) {
+	s := newSession(t, seed())
+	s.run("", "agenda")
+	s.run("", "add", "Pay rent", "--due", "today", "-p", "1")
+	s.run("", "add", "Plan the trip", "--due", "4d", "-p", "3")
+	s.run("", "snooze", "2", "tomorrow")
+	s.run("", "timer", "start", "4")
+	s.advance(25 * time.Minute)
+	s.run("", "agenda", "--top", "2")
+	s.run("", "agenda", "--markdown")
+	s.run("", "timer", "start", "4")
+	s.run("", "timer", "stop")
+	s.run("", "timer", "stop")
+	s.run("", "timer", "start", "1")
+	s.advance(time.Hour + 5*time.Minute)
+	s.run("", "timer", "stop", "1")
+	s.check("agenda")
+}
+
+func TestReview(t *testing.T) {
+	s := newSession(t, seed())
+	s.run("k\np\n5\np\n1\nx\ns\nyesterday\ns\nmonday\n", "review")
+	s.run("", "list", "--all", "-o", "plain")
+	s.run("", "review")
+	s.advance(8 * 24 * time.Hour)
+	s.run("d\nk\n", "review", "--markdown")
+	s.run("q\n", "review", "--days", "3")
+	s.run("", "list", "--all", "-o", "plain")
+	s.run("", "review", "--days", "30")
+	s.check("review")
+}
+
+// helloPlugin greets, reads the tasks, replaces them with one of its own
+// and fails, printing the protocol answers it gets.
+const helloPlugin = `#!/bin/sh
+echo "hello $* (protocol $CLI_COBRA_PLUGIN_PROTOCOL, user $CLI_COBRA_USER, scheme $CLI_COBRA_PRIORITY_SCHEME)"
+echo '{"id":1,"method":"read"}'
+read -r reply
+echo "$reply"
+echo '{"id":2,"method":"write","items":[{"ID":"e5","Text":"Written by a plugin","Priority":1}]}'
+read -r reply
+echo "$reply"
+echo '{"id":3,"method":"shout"}'
+read -r reply
+echo "$reply"
+echo "datafile $CLI_COBRA_DATAFILE" >&2
+exit 3
+`
+
+func TestFocus(t *testing.T) {
+	s := newSession(t, seed())
+	s.config = map[string]string{config.KeyFocusPomodoros: "2"}
+	s.run("", "focus", "1")
+	s.run("", "focus", "2", "-n", "1", "--work", "50m", "--done")
+	s.run("", "timer", "start", "4")
+	s.run("", "focus", "4", "-n", "1")
+	s.run("", "timer", "stop")
+	s.interrupt = s.now.Add(10 * time.Minute)
+	s.run("", "focus", "4")
+	s.interrupt = s.now.Add(27 * time.Minute)
+	s.run("", "focus", "4")
+	s.run("", "focus", "3")
+	s.run("", "focus", "1", "--work", "0s")
+	s.run("", "stats")
+	s.advance(48 * time.Hour)
+	s.run("", "stats", "--days", "3")
+	s.check("focus")
+}
+
+func TestMove(t *testing.T) {
+	s := newSession(t, seed())
+	s.run("", "move", "2", "Doing")
+	s.run("", "move", "2", " Review ")
+	s.run("", "move", "2", "later")
+	s.run("", "board")
+	s.config = map[string]string{config.KeyStates: "todo,todo,done"}
+	s.run("", "move", "2", "done")
+	s.config = map[string]string{config.KeyStates: "todo,done", config.KeyWIPLimits: "doing=2"}
+	s.run("", "move", "2", "done")
+	s.check("move")
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		p := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	kept := write("kept.go", "package x\n\n// TODO: still here\n")
+	write("tight.go", "package x\n\n// TODO:fix this\n// TODOs are not markers\n")
+	gone := write("gone.go", "package x\n")
+	broken := write("broken.go", "package x\n\n// TODO: behind a syntax error\nfunc {\n")
+	hidden := write("_tools/tools.go", "package tools\n\n// TODO: in a skipped directory\n")
+
+	s := newSession(t, []todo.Item{
+		{ID: "a1", Text: "still here", Priority: 2, State: "todo", Source: kept + ":3"},
+		{ID: "b2", Text: "was here", Priority: 2, State: "todo", Source: gone + ":3"},
+		{ID: "c3", Text: "behind a syntax error", Priority: 2, State: "todo", Source: broken + ":3"},
+		{ID: "d4", Text: "in a skipped directory", Priority: 2, State: "todo", Source: hidden + ":3"},
+	})
+	s.run("", "scan", dir)
+	out := s.out.String()
+	for _, want := range []string{
+		`Added TODO: "fix this" (` + filepath.Join(dir, "tight.go") + ":3)",
+		`Closed "was here"`,
+		"stderr: Warning: could not read " + broken,
+		`stderr: Left "behind a syntax error" open`,
+		`stderr: Left "in a skipped directory" open`,
+		"Scanned 3 files: 1 created, 0 updated, 1 closed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	for _, item := range s.store.Items {
+		if item.Done != (item.ID == "b2") {
+			t.Errorf("%q done = %v", item.Text, item.Done)
+		}
+	}
+}
+
+func TestFocusKeepsOtherChanges(t *testing.T) {
+	s := newSession(t, seed())
+	added := false
+	s.asleep = func() {
+		if !added {
+			s.store.Items = append(s.store.Items, todo.Item{ID: "e5", Text: "Added elsewhere", Priority: 2, State: "todo"})
+			added = true
+		}
+	}
+	s.run("", "focus", "2", "-n", "2", "--done")
+
+	items := s.store.Items
+	if len(items) != 5 || items[4].Text != "Added elsewhere" {
+		t.Fatalf("store holds %d items, want the one added during focus kept", len(items))
+	}
+	if !items[1].Done || len(items[1].Pomodoros) != 2 {
+		t.Errorf("focused task: done %v, %d pomodoros", items[1].Done, len(items[1].Pomodoros))
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	s := newSession(t, seed())
+	s.run("", "add", "Pay rent", "--due", "monday", "-p", "1")
+	s.run("", "add", "Call the plumber", "--due", "monday", "-p", "3")
+	s.run("", "add", "Pick up the parcel", "--due", "monday")
+	s.run("", "add", "Renew the passport", "--due", "monday")
+	s.run("", "add", "Plan the trip", "--due", "2026-04-02")
+	s.run("", "calendar")
+	s.run("", "calendar", "--week")
+	s.run("", "calendar", "--week", "2d", "--lines", "2", "--all")
+	s.run("", "cal", "--month", "+1")
+	s.run("", "calendar", "--month", "2026-13")
+	s.run("", "calendar", "--month", "2026-03", "--week")
+	s.run("", "calendar", "2026-05-01")
+	s.config = map[string]string{config.KeyLanguage: "nl"}
+	s.run("", "calendar", "--week", "maandag")
+	s.check("calendar")
+}
+
+func TestDutch(t *testing.T) {
+	s := newSession(t, seed())
+	s.config = map[string]string{config.KeyLanguage: "nl"}
+	s.run("", "list")
+	s.run("", "add", "Bel de huisarts", "--due", "overmorgen", "-p", "1")
+	s.run("", "add", "Koop een cadeau", "--due", "20 maart 2026")
+	s.run("", "add", "Plan de reis", "--due", "ooit")
+	s.run("", "snooze", "2", "vrijdag")
+	s.run("", "done", "9")
+	s.run("", "done", "1")
+	s.run("", "list", "--all", "--columns", "label,priority,task,due,wait")
+	s.run("", "agenda")
+	s.run("k\n", "review")
+	s.run("", "move", "2", "nergens")
+	s.run("", "list", "--columns", "task,kleur")
+	s.config[config.KeyLanguage] = "en"
+	s.run("", "agenda", "--top", "1")
+	s.check("dutch")
+}
+
+func TestPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugin is a shell script")
+	}
+	s := newSession(t, seed()[:2])
+	s.config = map[string]string{config.KeyPriorityScheme: "letters"}
+	for name, script := range map[string]string{
+		"cli-cobra-hello": helloPlugin,
+		"cli-cobra-list":  "#!/bin/sh\necho shadowed\n",
+		"cli-cobra-quiet": "#!/bin/sh\necho just output\n",
+	} {
+		if err := os.WriteFile(filepath.Join(s.plugins, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(s.plugins, "cli-cobra-noexec"), []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s.run("", "--datafile", "/tmp/tasks.json", "hello", "world", "--loud")
+	s.run("", "list", "-o", "plain")
+	s.run("", "quiet")
+	s.run("", "noexec")
+	s.check("plugin")
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/jubel075/cli-cobra/config"
+	"github.com/spf13/cobra"
+)
+
+// settingsHelp lists every setting of config.Settings with its
+// description, accepted values and default, wrapped to 80 columns.
+func settingsHelp() string {
+	width := 0
+	for _, s := range config.Settings {
+		width = max(width, len(s.Key))
+	}
+	var b strings.Builder
+	for _, s := range config.Settings {
+		var notes []string
+		if s.Allowed != nil {
+			last := len(s.Allowed) - 1
+			notes = append(notes, strings.Join(s.Allowed[:last], ", ")+" or "+s.Allowed[last])
+		}
+		if s.Default != "" {
+			notes = append(notes, "default "+s.Default)
+		}
+		text := s.Description
+		if notes != nil {
+			text += " (" + strings.Join(notes, "; ") + ")"
+		}
+		line := fmt.Sprintf("  %-*s", width, s.Key)
+		for _, word := range strings.Fields(text) {
+			if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > 80 && strings.TrimSpace(line) != "" {
+				b.WriteString(line + "\n")
+				line = strings.Repeat(" ", width+2)
+			}
+			line += " " + word
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// newConfigCmd builds the config command and its subcommands.
+func newConfigCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show and change configuration settings",
+		Long: `The config command reads and writes the settings stored in your config
+file ($HOME/.cli-cobra.yaml unless --config is given).
+
+Every setting can also be overridden with an environment variable named
+CLI_COBRA_<KEY>, for example CLI_COBRA_OUTPUT=json. Flags win over
+environment variables, which win over the config file.
+
+Settings:
+` + settingsHelp() + `
+Examples:
+  cli-cobra config list
+  cli-cobra config get datafile
+  cli-cobra config set output json
+  cli-cobra config unset output`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all settings with their value and source",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfgFile, err := a.configFile()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			a.loc.Fprintf(out, "Config file: %s\n", cfgFile)
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			header := a.loc.T("KEY\tVALUE\tSOURCE\tENV")
+			fmt.Fprintln(w, header)
+			fmt.Fprintln(w, rule(header))
+			for _, s := range config.Settings {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Key, a.v.GetString(s.Key), a.loc.T(a.settingSource(s)), s.EnvVar())
+			}
+			w.Flush()
+
+			if a.configErr != nil {
+				fmt.Fprintln(out)
+				fmt.Fprintln(out, a.configErr)
+			}
+			return nil
+		},
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := config.Lookup(args[0]); !ok {
+				return config.UnknownKeyError(args[0])
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), a.v.GetString(args[0]))
+			return nil
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Store a setting in the config file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			s, ok := config.Lookup(key)
+			if !ok {
+				return config.UnknownKeyError(key)
+			}
+			if err := s.Validate(value); err != nil {
+				return err
+			}
+			if key == config.KeyStates || key == config.KeyWIPLimits {
+				get := func(k string) string {
+					if k == key {
+						return value
+					}
+					return a.v.GetString(k)
+				}
+				if _, err := config.Workflow(get); err != nil {
+					return err
+				}
+			}
+			cfgFile, err := a.configFile()
+			if err != nil {
+				return err
+			}
+			values, err := config.ReadFile(cfgFile)
+			if err != nil {
+				return err
+			}
+			values[key] = value
+			if err := config.WriteFile(cfgFile, values); err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			a.loc.Fprintf(out, "Set %s = %s in %s\n", key, value, cfgFile)
+			if env := os.Getenv(s.EnvVar()); env != "" && a.readsEnv() {
+				a.loc.Fprintf(out, "Note: %s=%s overrides this value\n", s.EnvVar(), env)
+			}
+			return nil
+		},
+	}
+
+	unsetCmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a setting from the config file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+			if _, ok := config.Lookup(key); !ok {
+				return config.UnknownKeyError(key)
+			}
+			cfgFile, err := a.configFile()
+			if err != nil {
+				return err
+			}
+			values, err := config.ReadFile(cfgFile)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			if _, ok := values[key]; !ok {
+				a.loc.Fprintf(out, "%s is not set in %s\n", key, cfgFile)
+				return nil
+			}
+			delete(values, key)
+			if err := config.WriteFile(cfgFile, values); err != nil {
+				return err
+			}
+			a.loc.Fprintf(out, "Unset %s in %s\n", key, cfgFile)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(listCmd, getCmd, setCmd, unsetCmd)
+	return cmd
+}
+
+// rule underlines the tab-separated column names of header.
+func rule(header string) string {
+	cols := strings.Split(header, "\t")
+	for i, c := range cols {
+		cols[i] = strings.Repeat("-", utf8.RuneCountInString(c))
+	}
+	return strings.Join(cols, "\t")
+}
+
+// settingSource reports where the effective value of s comes from.
+func (a *app) settingSource(s config.Setting) string {
+	if f := a.root.PersistentFlags().Lookup(s.Key); f != nil && f.Changed {
+		return "flag"
+	}
+	if a.readsEnv() && os.Getenv(s.EnvVar()) != "" {
+		return "env"
+	}
+	if a.v.InConfig(s.Key) {
+		return "file"
+	}
+	return "default"
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jubel075/cli-cobra/config"
+	"github.com/jubel075/cli-cobra/i18n"
+)
+
+// parseDate reads a date given as "today", "tomorrow", a weekday such as
+// "monday" or "fri" (the next one after today), a number of days or weeks
+// from today such as "3d" or "2w", or in the date_format setting format.
+// The words may also be in the language of l, see i18n.Locale.ParseDate.
+func parseDate(s string, now time.Time, format string, l *i18n.Locale) (time.Time, error) {
+	layout, err := config.DateLayout(format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return l.ParseDate(s, now, layout)
+}
+
+// parseDate reads a date typed by the user in the configured language and
+// date format.
+func (a *app) parseDate(s string) (time.Time, error) {
+	return parseDate(s, a.Now(), a.v.GetString(config.KeyDateFormat), a.loc)
+}
+
+// parseMonth reads a month given as 2026-11, or as a number of months from
+// the current one such as +1 or -1, and returns its first day. An empty
+// string is the current month.
+func (a *app) parseMonth(s string) (time.Time, error) {
+	now := a.Now()
+	this := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return this, nil
+	}
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		if n, err := strconv.Atoi(s); err == nil {
+			return this.AddDate(0, n, 0), nil
+		}
+	}
+	t, err := time.ParseInLocation("2006-01", s, now.Location())
+	if err != nil {
+		return time.Time{}, a.loc.Errorf("invalid month %q: use the format 2026-11, or +1 and -1 for the next and previous month", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jubel075/cli-cobra/i18n"
+)
+
+func TestParseDate(t *testing.T) {
+	// now is a Saturday.
+	tests := []struct {
+		in, format string
+		lang       string
+		want       time.Time
+	}{
+		{"today", "iso", "en", day(14)},
+		{"Tomorrow", "iso", "en", day(15)},
+		{"3d", "iso", "en", day(17)},
+		{"+1d", "iso", "en", day(15)},
+		{"10 days", "iso", "en", day(24)},
+		{"2w", "iso", "en", day(28)},
+		{"1 week", "iso", "en", day(21)},
+		{"monday", "iso", "en", day(16)},
+		{"fri", "iso", "en", day(20)},
+		{"saturday", "iso", "en", day(21)},
+		{"2026-03-20", "iso", "en", day(20)},
+		{"20-03-2026", "eu", "en", day(20)},
+		{"03/20/2026", "us", "en", day(20)},
+		{"March 20, 2026", "iso", "en", day(20)},
+		{"morgen", "iso", "nl", day(15)},
+		{"Overmorgen", "iso", "nl", day(16)},
+		{"3 dagen", "iso", "nl", day(17)},
+		{"2 weken", "iso", "nl", day(28)},
+		{"maandag", "iso", "nl", day(16)},
+		{"vr", "iso", "nl", day(20)},
+		{"20-3-2026", "iso", "nl", day(20)},
+		{"20 maart 2026", "iso", "nl", day(20)},
+		{"20 mrt 2026", "iso", "nl", day(20)},
+		{"tomorrow", "iso", "nl", day(15)},
+		{"2026-03-20", "iso", "nl", day(20)},
+	}
+	for _, tt := range tests {
+		l, _ := i18n.Lookup(tt.lang)
+		got, err := parseDate(tt.in, now, tt.format, l)
+		if err != nil {
+			t.Errorf("parseDate(%q, %q, %s): %v", tt.in, tt.format, tt.lang, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q, %q, %s) = %s, want %s", tt.in, tt.format, tt.lang, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+
+	for _, in := range []string{"", "someday", "3x", "d3", "20-03-2026", "morgen"} {
+		if got, err := parseDate(in, now, "iso", nil); err == nil {
+			t.Errorf("parseDate(%q) = %s, want an error", in, got.Format(time.DateOnly))
+		}
+	}
+	nl, _ := i18n.Lookup("nl")
+	if _, err := parseDate("ooit", now, "iso", nl); err == nil || err.Error() != `ongeldige datum "ooit": gebruik vandaag, morgen, een weekdag, een aantal dagen of weken zoals 3d of 2w, of het formaat 2006-01-02` {
+		t.Errorf("parseDate(%q, nl) error = %v, want it in Dutch", "ooit", err)
+	}
+}
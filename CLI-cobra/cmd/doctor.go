@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/cobra"
+)
+
+// newDoctorCmd builds the doctor command.
+func newDoctorCmd(a *app) *cobra.Command {
+	var fix bool
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the data file for corruption and repair it",
+		Long: `The doctor command validates your data file: that it decodes, which
+format version it is stored in, and that every task has a unique ID, text,
+a valid priority and a workflow state that matches its done flag.
+
+Without flags it only reports. With --fix it repairs what it can: older
+formats are upgraded, broken tasks are corrected, and a file that no longer
+decodes is rebuilt from the tasks that can still be read. The original is
+kept next to it as <datafile>.doctor.bak.
+
+Examples:
+  cli-cobra doctor
+  cli-cobra doctor --fix`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dataFile, err := a.dataFile()
+			if err != nil {
+				return err
+			}
+			check := todo.Check
+			if fix {
+				check = todo.Repair
+			}
+			r, err := check(dataFile, a.workflow())
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if !r.Exists {
+				a.loc.Fprintf(out, "%s does not exist yet; it is created when you add a task.\n", dataFile)
+				return nil
+			}
+			version := a.loc.T("unknown")
+			if r.Version > 0 {
+				version = fmt.Sprint(r.Version)
+			}
+			a.loc.Fprintf(out, "Data file: %s (format version %s, current %d)\n", dataFile, version, todo.CurrentVersion)
+			if len(r.Problems) == 0 {
+				a.loc.Fprintf(out, "No problems found in %d tasks.\n", len(r.Items))
+				return nil
+			}
+
+			for _, p := range r.Problems {
+				where := a.loc.T("file")
+				if p.Item > 0 {
+					where = a.loc.Sprintf("task %d", p.Item)
+				}
+				note := ""
+				if !p.Fixable {
+					note = a.loc.T(" (cannot be fixed automatically)")
+				}
+				fmt.Fprintf(out, "  %s: %s%s\n", where, a.loc.Text(p.Message), note)
+			}
+
+			switch {
+			case r.Written:
+				a.loc.Fprintf(out, "Repaired %s, %d tasks kept. The original is in %s.doctor.bak.\n", dataFile, len(r.Items), dataFile)
+				return nil
+			case !r.Fixable():
+				return a.loc.Error("some problems need manual attention; the file was not changed")
+			default:
+				return a.loc.Error(`run "cli-cobra doctor --fix" to repair them`)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&fix, "fix", false, "Repair the problems found, keeping a backup of the original")
+	return cmd
+}
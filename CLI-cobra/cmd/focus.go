@@ -0,0 +1,219 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/jubel075/cli-cobra/config"
+	"github.com/jubel075/cli-cobra/render"
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/cobra"
+)
+
+// newFocusCmd builds the focus command.
+func newFocusCmd(a *app) *cobra.Command {
+	var (
+		pomodoros int
+		work      time.Duration
+		short     time.Duration
+		long      time.Duration
+		done      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "focus <id>",
+		Short: "Work on a task in Pomodoro sessions",
+		Long: `The focus command runs a Pomodoro cycle on a task: focus_pomodoros
+sessions of focus_work each, with a focus_short_break between them and a
+focus_long_break at the end. A countdown shows the time left, updated every
+second on a terminal.
+
+The task's timer runs during each session, so the time is tracked as with
+"timer start", and every finished session is logged on the task for
+"stats". Press Ctrl-C to stop early: the time worked is kept, but the
+unfinished session is not logged. --done marks the task as done once the
+last session is over.
+
+Examples:
+  cli-cobra focus 2
+  cli-cobra focus 2 --pomodoros 1 --done
+  cli-cobra focus 2 --work 50m --short-break 10m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			if !flags.Changed("pomodoros") {
+				pomodoros = a.v.GetInt(config.KeyFocusPomodoros)
+			}
+			if !flags.Changed("work") {
+				work = a.v.GetDuration(config.KeyFocusWork)
+			}
+			if !flags.Changed("short-break") {
+				short = a.v.GetDuration(config.KeyFocusShort)
+			}
+			if !flags.Changed("long-break") {
+				long = a.v.GetDuration(config.KeyFocusLong)
+			}
+			if pomodoros < 1 || work <= 0 || short <= 0 || long <= 0 {
+				return a.loc.Error("the number of pomodoros and every length must be positive")
+			}
+
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			i, err := a.taskIndex(args[0], items)
+			if err != nil {
+				return err
+			}
+			id, text := items[i].ID, items[i].Text
+			if items[i].Done {
+				return a.loc.Errorf("%q is already done", text)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			out := cmd.OutOrStdout()
+			live := render.IsTerminal(out)
+
+			for n := 1; n <= pomodoros; n++ {
+				start := a.Now()
+				// A timer the user started keeps running and covers the session.
+				var own bool
+				if _, err := a.updateTask(id, text, func(item *todo.Item) error {
+					own = item.StartTimer(start)
+					return nil
+				}); err != nil {
+					return err
+				}
+				title := a.loc.Sprintf("Pomodoro %d of %d on %q", n, pomodoros, text)
+				err := a.countdown(ctx, out, live, title, work)
+				now := a.Now()
+				stopTimer := func(item *todo.Item) {
+					if own {
+						item.StopTimer(now)
+					}
+				}
+				if err != nil {
+					if _, err := a.updateTask(id, text, func(item *todo.Item) error {
+						stopTimer(item)
+						return nil
+					}); err != nil {
+						return err
+					}
+					a.loc.Fprintf(out, "Stopped after %s; the unfinished pomodoro is not logged.\n", formatDuration(now.Sub(start)))
+					return nil
+				}
+
+				last := n == pomodoros
+				item, err := a.updateTask(id, text, func(item *todo.Item) error {
+					stopTimer(item)
+					item.LogPomodoro(now, work)
+					if last && done {
+						wf := a.workflow()
+						return item.SetState(wf, wf.Final())
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				if live {
+					fmt.Fprint(out, "\a")
+				}
+				a.loc.Fprintf(out, "Finished pomodoro %d of %d, %d on this task so far\n", n, pomodoros, len(item.Pomodoros))
+				if last && done {
+					a.loc.Fprintf(out, "%q marked as done\n", text)
+				}
+
+				title, length := a.loc.T("Short break"), short
+				if last {
+					title, length = a.loc.T("Long break"), long
+				}
+				if err := a.countdown(ctx, out, live, title, length); err != nil {
+					a.loc.Fprintln(out, "Break cut short.")
+					return nil
+				}
+			}
+			a.loc.Fprintf(out, "Cycle complete: %s of focus on %q\n", formatDuration(time.Duration(pomodoros)*work), text)
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&pomodoros, "pomodoros", "n", 0, "Pomodoros in the cycle (default focus_pomodoros)")
+	cmd.Flags().DurationVar(&work, "work", 0, "Length of a pomodoro (default focus_work)")
+	cmd.Flags().DurationVar(&short, "short-break", 0, "Length of the breaks in between (default focus_short_break)")
+	cmd.Flags().DurationVar(&long, "long-break", 0, "Length of the break at the end (default focus_long_break)")
+	cmd.Flags().BoolVarP(&done, "done", "d", false, "Mark the task as done after the last pomodoro")
+	return cmd
+}
+
+// updateTask applies change to the task with id and saves it. The list is
+// read again first, so tasks added or changed in another terminal during
+// a long command are kept. It returns the task as saved.
+func (a *app) updateTask(id, text string, change func(*todo.Item) error) (todo.Item, error) {
+	items, err := a.load()
+	if err != nil {
+		return todo.Item{}, err
+	}
+	i := slices.IndexFunc(items, func(item todo.Item) bool { return item.ID == id })
+	if i < 0 {
+		return todo.Item{}, a.loc.Errorf("%q was deleted in the meantime", text)
+	}
+	if err := change(&items[i]); err != nil {
+		return todo.Item{}, err
+	}
+	if err := a.save(items); err != nil {
+		return todo.Item{}, err
+	}
+	return items[i], nil
+}
+
+// countdown waits for length, showing title and the time left. On a
+// terminal the line is redrawn every second; otherwise it is printed once
+// with the time the wait ends. It returns the context's error when
+// interrupted.
+func (a *app) countdown(ctx context.Context, w io.Writer, live bool, title string, length time.Duration) error {
+	end := a.Now().Add(length)
+	if !live {
+		a.loc.Fprintf(w, "%s: %s, until %s\n", title, formatDuration(length), end.Format("15:04"))
+	}
+	for {
+		left := end.Sub(a.Now())
+		if left <= 0 {
+			break
+		}
+		step := left
+		if live {
+			fmt.Fprintf(w, "\r\033[K%s  %s", title, formatClock(left))
+			if step = left % time.Second; step == 0 {
+				step = time.Second
+			}
+		}
+		if err := a.Sleep(ctx, step); err != nil {
+			if live {
+				fmt.Fprintln(w)
+			}
+			return err
+		}
+	}
+	if live {
+		fmt.Fprint(w, "\r\033[K")
+	}
+	return nil
+}
+
+// formatClock renders the time left as a countdown, such as 24:59 or
+// 1:05:00.
+func formatClock(d time.Duration) string {
+	s := int((d + time.Second - 1) / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
@@ -4,28 +4,33 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 	"sort"
+	"strings"
 
-	// "strconv"
-	"text/tabwriter"
-
+	"github.com/jubel075/cli-cobra/config"
+	"github.com/jubel075/cli-cobra/render"
 	"github.com/jubel075/cli-cobra/todo"
 	"github.com/spf13/cobra"
 )
 
-var (
-	doneOpt bool
-	allOpt  bool
-)
-
-// listCmd represents the list command
-var listCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List the to-do tasks",
-	Long: `The list command shows all tasks currently stored in your to-do list.
+// newListCmd builds the list command.
+func newListCmd(a *app) *cobra.Command {
+	var (
+		doneOpt  bool
+		allOpt   bool
+		pendOpt  bool
+		wrapOpt  bool
+		mineOpt  bool
+		waitOpt  bool
+		whoOpt   string
+		stateOpt string
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the to-do tasks",
+		Long: `The list command shows all tasks currently stored in your to-do list.
 
 By default, it prints every task in the order they were added, along with
 their status (completed or pending). You can filter the output using flags
@@ -53,41 +58,101 @@ Examples:
   mytodo list --output json
       Prints tasks in JSON format for use in scripts or other programs.
 
+  mytodo list --waiting
+      Shows the snoozed tasks that are hidden until their start date.
+
 If no tasks exist, the command will let you know that your list is empty
 instead of printing a blank table. This ensures you always get useful
 feedback when running the command.`,
 
-	Run: func(cmd *cobra.Command, args []string) {
-		items, err := todo.ReadItems(dataFile)
-		if err != nil {
-			log.Printf("%v", err)
-		}
-		fmt.Printf("You have %d tasks in your to-do list:\n", len(items))
-
-		sort.Sort(todo.ByPriority(items))
-
-		// Print in tabular format
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-
-		// Print header
-		fmt.Fprintln(w, "LABEL\tPRIORITY\tTASK\tSTATUS")
-		fmt.Fprintln(w, "-----\t--------\t----\t------")
-
-		// Print each item with its label
-		for _, i := range items {
-			if i.Done || allOpt == doneOpt {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", i.Label(), i.PrettyP(), i.Text, i.PrettyDone())
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
 			}
-		}
 
-		// Flush to output
-		w.Flush()
-	},
-}
+			sort.Sort(todo.ByPriority(items))
 
-func init() {
-	rootCmd.AddCommand(listCmd)
+			filter := a.v.GetString(config.KeyDefaultList)
+			switch {
+			case allOpt:
+				filter = "all"
+			case doneOpt:
+				filter = "done"
+			case pendOpt, waitOpt:
+				filter = "pending"
+			}
+			if mineOpt {
+				whoOpt = a.User
+			}
+			wf := a.workflow()
+			if stateOpt != "" && !wf.Has(stateOpt) {
+				return a.loc.Errorf("unknown state %q, states are %s", stateOpt, strings.Join(wf.States, ", "))
+			}
+			// Snoozed tasks only show with --waiting or --all.
+			now := a.Now()
+			snoozed := 0
+			shown := []todo.Item{}
+			for _, i := range items {
+				if whoOpt != "" && i.Assignee != whoOpt {
+					continue
+				}
+				waiting := i.Waiting(now)
+				if waitOpt && !waiting {
+					continue
+				}
+				if waiting && !waitOpt && !allOpt {
+					snoozed++
+					continue
+				}
+				if stateOpt != "" {
+					if i.StateIn(wf) == stateOpt {
+						shown = append(shown, i)
+					}
+					continue
+				}
+				if filter == "all" || i.Done == (filter == "done") {
+					shown = append(shown, i)
+				}
+			}
 
-	listCmd.Flags().BoolVarP(&doneOpt, "done", "d", false, "List only completed tasks")
-	listCmd.Flags().BoolVarP(&allOpt, "all", "a", false, "List all tasks")
+			out := cmd.OutOrStdout()
+			scheme := a.v.GetString(config.KeyPriorityScheme)
+			switch a.v.GetString(config.KeyOutput) {
+			case "json":
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(shown)
+			case "plain":
+				for _, i := range shown {
+					fmt.Fprintf(out, "%s%s%s %s\n", i.Label(), i.PrettyDone(), a.loc.T(i.PriorityIn(scheme)), i.Text)
+				}
+				return nil
+			default:
+				if snoozed > 0 {
+					a.loc.Fprintf(out, "You have %d tasks in your to-do list (%d snoozed, see list --waiting):\n", len(items), snoozed)
+				} else {
+					a.loc.Fprintf(out, "You have %d tasks in your to-do list:\n", len(items))
+				}
+
+				o := a.renderOptions(out, wrapOpt)
+				o.Columns = strings.Split(a.v.GetString(config.KeyColumns), ",")
+				return render.Table(out, shown, o)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&doneOpt, "done", "d", false, "List only completed tasks")
+	cmd.Flags().BoolVarP(&allOpt, "all", "a", false, "List all tasks")
+	cmd.Flags().BoolVarP(&pendOpt, "pending", "P", false, "List only unfinished tasks")
+	cmd.Flags().BoolVarP(&mineOpt, "mine", "m", false, "List only tasks assigned to you")
+	cmd.Flags().BoolVar(&waitOpt, "waiting", false, "List only snoozed tasks that are not due to show yet")
+	cmd.Flags().StringVar(&whoOpt, "assignee", "", "List only tasks assigned to this user")
+	cmd.Flags().StringVarP(&stateOpt, "state", "s", "", "List only tasks in this workflow state")
+	cmd.Flags().StringP("output", "o", "table", "Output format: table, json or plain")
+	a.v.BindPFlag(config.KeyOutput, cmd.Flags().Lookup("output"))
+	cmd.Flags().String("columns", strings.Join(render.DefaultColumns, ","), "Comma-separated columns to show: "+strings.Join(render.ColumnNames(), ", "))
+	a.v.BindPFlag(config.KeyColumns, cmd.Flags().Lookup("columns"))
+	cmd.Flags().BoolVarP(&wrapOpt, "wrap", "w", false, "Wrap long tasks instead of truncating them to the terminal width")
+	return cmd
 }
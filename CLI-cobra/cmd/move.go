@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newMoveCmd builds the move command.
+func newMoveCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "move <id> <state>",
+		Short: "Move a task to another workflow state",
+		Long: `The move command changes the workflow state of a task, for example from
+todo to doing. The states are configured with "config set states" and
+default to backlog, todo, doing, review and done. Moving a task to the last
+state marks it as done; moving it out again reopens it.
+
+A warning is printed when the move takes a state over its WIP limit
+(see "config set wip_limits"), but the move is still made.
+
+Examples:
+  cli-cobra move 2 doing
+  cli-cobra move 2 review
+  cli-cobra board`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			i, err := a.taskIndex(args[0], items)
+			if err != nil {
+				return err
+			}
+
+			wf := a.workflow()
+			// States are matched as ParseWorkflow stores them.
+			state := strings.ToLower(strings.TrimSpace(args[1]))
+			if err := items[i].SetState(wf, state); err != nil {
+				return err
+			}
+			if err := a.save(items); err != nil {
+				return err
+			}
+			a.loc.Fprintf(cmd.OutOrStdout(), "%q moved to %s\n", items[i].Text, state)
+
+			if limit, ok := wf.WIP[state]; ok {
+				if n := wf.Count(items, state); n > limit {
+					a.loc.Fprintf(cmd.ErrOrStderr(), "Warning: %s now holds %d tasks, over its WIP limit of %d\n", state, n, limit)
+				}
+			}
+			return nil
+		},
+	}
+}
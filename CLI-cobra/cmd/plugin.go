@@ -0,0 +1,128 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jubel075/cli-cobra/config"
+	"github.com/jubel075/cli-cobra/plugin"
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/cobra"
+)
+
+// pluginAnnotation marks the commands that run a plugin.
+const pluginAnnotation = "plugin"
+
+// newPluginCmd builds the subcommand that runs p. Its arguments are
+// passed on untouched, so the plugin parses its own flags.
+func newPluginCmd(a *app, p plugin.Plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              "Plugin: " + p.Path,
+		Annotations:        map[string]string{pluginAnnotation: p.Path},
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, err := a.pluginEnv()
+			if err != nil {
+				return err
+			}
+			return p.Run(a.pluginArgs, env, &pluginHost{a: a}, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		},
+	}
+}
+
+// addPlugins adds a subcommand for every plugin on the search path whose
+// name is not taken by a built-in command.
+func (a *app) addPlugins() {
+	for _, p := range plugin.Discover(a.PluginPath) {
+		// help and completion are added by cobra when the tree runs.
+		if p.Name == "help" || p.Name == "completion" {
+			continue
+		}
+		if c, _, err := a.root.Find([]string{p.Name}); err == nil && c != a.root {
+			continue
+		}
+		a.root.AddCommand(newPluginCmd(a, p))
+	}
+}
+
+// parsePluginFlags applies the cli-cobra flags given before a plugin's
+// name, which cobra leaves among the plugin's arguments, and returns the
+// remaining arguments.
+func (a *app) parsePluginFlags(args []string) ([]string, error) {
+	flags := a.root.PersistentFlags()
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		name, value, hasValue := strings.Cut(args[0][2:], "=")
+		f := flags.Lookup(name)
+		if f == nil {
+			break
+		}
+		args = args[1:]
+		if !hasValue {
+			if f.NoOptDefVal != "" {
+				value = f.NoOptDefVal
+			} else if len(args) > 0 {
+				value, args = args[0], args[1:]
+			} else {
+				return nil, a.loc.Errorf("flag needs an argument: --%s", name)
+			}
+		}
+		if err := flags.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+// pluginEnv passes the resolved settings to a plugin: every setting as
+// its CLI_COBRA_* variable, with the data file expanded, plus the config
+// file, the user and the protocol version.
+func (a *app) pluginEnv() ([]string, error) {
+	dataFile, err := a.dataFile()
+	if err != nil {
+		return nil, err
+	}
+	cfgFile, err := a.configFile()
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	for _, s := range config.Settings {
+		value := a.v.GetString(s.Key)
+		if s.Key == config.KeyDataFile {
+			value = dataFile
+		}
+		env = append(env, s.EnvVar()+"="+value)
+	}
+	return append(env,
+		config.EnvPrefix+"_CONFIG="+cfgFile,
+		config.EnvPrefix+"_USER="+a.User,
+		fmt.Sprintf("%s_PLUGIN_PROTOCOL=%d", config.EnvPrefix, plugin.ProtocolVersion),
+	), nil
+}
+
+// pluginHost serves a plugin's requests from the task store.
+type pluginHost struct {
+	a    *app
+	read bool
+}
+
+func (h *pluginHost) Read() ([]todo.Item, error) {
+	items, err := h.a.load()
+	h.read = err == nil
+	return items, err
+}
+
+// Write saves items. The list is read first when the plugin did not, so
+// the save merges with the current file instead of overwriting it.
+func (h *pluginHost) Write(items []todo.Item) error {
+	if !h.read {
+		if _, err := h.Read(); err != nil {
+			return err
+		}
+	}
+	return h.a.save(items)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/cobra"
+)
+
+// newReviewCmd builds the review command.
+func newReviewCmd(a *app) *cobra.Command {
+	var (
+		days     int
+		markdown bool
+	)
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Walk through stale tasks one by one",
+		Long: `The review command goes through the unfinished tasks that have not
+changed for a while (7 days unless --days says otherwise), most important
+first, and asks what to do with each:
+
+  k  keep it as it is; it counts as reviewed
+  p  give it another priority
+  s  snooze it until a later date (see "snooze")
+  d  delete it
+  q  stop the review, keeping the answers given so far
+
+At the end it summarizes what changed. With --markdown the summary is
+printed as Markdown, ready to paste into stand-up notes.
+
+Examples:
+  cli-cobra review
+  cli-cobra review --days 14
+  cli-cobra review --markdown`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			o := a.renderOptions(out, false)
+			now := o.Now
+			cutoff := now.AddDate(0, 0, -days)
+			var stale []int
+			for i, item := range items {
+				if !item.Done && !item.Waiting(now) && item.Updated.Before(cutoff) {
+					stale = append(stale, i)
+				}
+			}
+			// The order is only for asking; items stay in file order, as
+			// they are saved.
+			sort.SliceStable(stale, func(x, y int) bool {
+				return items[stale[x]].Urgency(o.Workflow, now) > items[stale[y]].Urgency(o.Workflow, now)
+			})
+			if len(stale) == 0 {
+				a.loc.Fprintf(out, "Nothing to review: every open task changed in the last %s.\n", a.loc.Plural(days, "1 day", "%d days"))
+				return nil
+			}
+
+			in := bufio.NewScanner(cmd.InOrStdin())
+			ask := func(prompt string) (string, bool) {
+				fmt.Fprint(out, a.loc.T(prompt))
+				if !in.Scan() {
+					fmt.Fprintln(out)
+					return "", false
+				}
+				return strings.TrimSpace(in.Text()), true
+			}
+
+			deleted := map[int]bool{}
+			var changes []string
+		review:
+			for n, idx := range stale {
+				item := &items[idx]
+				fmt.Fprintf(out, "\n[%d/%d] %s%s\n", n+1, len(stale), item.Label(), itemSummary(*item, o))
+				for {
+					answer, ok := ask("keep, priority, snooze, delete or quit? [k/p/s/d/q] ")
+					if !ok {
+						break review
+					}
+					switch strings.ToLower(answer) {
+					case "k", "keep":
+						item.Updated = now
+						changes = append(changes, a.loc.Sprintf("Kept: %s", item.Text))
+					case "p", "priority":
+						answer, ok := ask("New priority (1=high, 2=medium, 3=low): ")
+						if !ok {
+							break review
+						}
+						p, err := strconv.Atoi(answer)
+						if err != nil || p < 1 || p > 3 {
+							a.loc.Fprintln(out, "Please enter 1, 2 or 3.")
+							continue
+						}
+						old := a.loc.T(item.PriorityIn(o.Scheme))
+						item.SetPtiority(p)
+						changes = append(changes, a.loc.Sprintf("Reprioritized: %s (%s to %s)", item.Text, old, a.loc.T(item.PriorityIn(o.Scheme))))
+					case "s", "snooze":
+						answer, ok := ask("Snooze until: ")
+						if !ok {
+							break review
+						}
+						wait, err := a.parseDate(answer)
+						if err != nil {
+							fmt.Fprintln(out, err)
+							continue
+						}
+						item.Wait = wait
+						if !item.Waiting(now) {
+							item.Wait = time.Time{}
+							a.loc.Fprintln(out, "Pick a day after today.")
+							continue
+						}
+						changes = append(changes, a.loc.Sprintf("Snoozed: %s until %s", item.Text, a.loc.Format(wait, o.DateLayout)))
+					case "d", "delete":
+						deleted[idx] = true
+						changes = append(changes, a.loc.Sprintf("Deleted: %s", item.Text))
+					case "q", "quit":
+						break review
+					default:
+						a.loc.Fprintln(out, "Please answer k, p, s, d or q.")
+						continue
+					}
+					break
+				}
+			}
+
+			if len(changes) > 0 {
+				kept := make([]todo.Item, 0, len(items))
+				for i, item := range items {
+					if !deleted[i] {
+						kept = append(kept, item)
+					}
+				}
+				if err := a.save(kept); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintln(out)
+			p := plan{w: out, markdown: markdown, o: o}
+			p.title(a.loc.Sprintf("Review of %s: %d of %d stale tasks", a.loc.Format(now, "Mon "+o.DateLayout), len(changes), len(stale)))
+			if len(changes) == 0 {
+				p.bullet(a.loc.T("nothing changed"))
+			}
+			for _, c := range changes {
+				p.bullet(c)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 7, "Review tasks that have not changed for this many days")
+	cmd.Flags().BoolVar(&markdown, "markdown", false, "Print the summary as Markdown")
+	return cmd
+}
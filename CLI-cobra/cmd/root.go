@@ -22,85 +22,341 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jubel075/cli-cobra/config"
+	"github.com/jubel075/cli-cobra/i18n"
+	"github.com/jubel075/cli-cobra/render"
+	"github.com/jubel075/cli-cobra/todo"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
-var dataFile string
-var ignoreConfig bool
+// Options is what a command tree needs from the outside world. Zero fields
+// fall back to the real thing, which is what Execute uses; tests pass
+// buffers, a todo.MemStore and a fixed clock instead.
+type Options struct {
+	In       io.Reader
+	Out, Err io.Writer
+	// Store holds the tasks. When nil, the configured datafile is used.
+	Store todo.Store
+	// Now is the clock used for due dates and overdue checks.
+	Now func() time.Time
+	// Sleep waits for d, or until ctx is done and then returns its error.
+	// focus counts down with it.
+	Sleep func(ctx context.Context, d time.Duration) error
+	// User is who "me", --mine and new tasks refer to.
+	User string
+	// Config holds the settings. When nil, they are read from the config
+	// file and CLI_COBRA_* environment variables; when set, neither is
+	// consulted.
+	Config *viper.Viper
+	// PluginPath lists the directories searched for cli-cobra-<name>
+	// plugins, in PATH format. When empty, $PATH is searched.
+	PluginPath string
+}
+
+// app is the state shared by the commands of one tree.
+type app struct {
+	Options
+	v    *viper.Viper
+	root *cobra.Command
+
+	cfgFile      string
+	ignoreConfig bool
+
+	// configErr holds a config validation failure. It is reported by every
+	// command except config itself, so a broken setting can still be fixed.
+	configErr error
+
+	// loaded holds the items as load read them, by ID, so save can tell
+	// which ones the command changed.
+	loaded map[string]string
+
+	// pluginArgs are the arguments of the plugin being run.
+	pluginArgs []string
+
+	// loc is the language of messages and date input, set by initConfig.
+	loc *i18n.Locale
+}
 
-// rootCmd represents the base command when called without any subcommands
-var rootCmd = &cobra.Command{
-	Use:   "cli-cobra",
-	Short: "This app is a simple CLI application built with Cobra",
-	Long: `cli-cobra uses Cobra to build a simple and powerful
+// NewRootCmd builds the cli-cobra command tree.
+func NewRootCmd(opts Options) *cobra.Command {
+	a := &app{Options: opts, v: opts.Config}
+	if a.v == nil {
+		a.v = viper.New()
+	}
+	if a.Now == nil {
+		a.Now = time.Now
+	}
+	if a.Sleep == nil {
+		a.Sleep = sleep
+	}
+	if a.User == "" {
+		a.User = todo.CurrentUser()
+	}
+	if a.PluginPath == "" {
+		a.PluginPath = os.Getenv("PATH")
+	}
+
+	configCmd := newConfigCmd(a)
+	a.root = &cobra.Command{
+		Use:   "cli-cobra",
+		Short: "This app is a simple CLI application built with Cobra",
+		Long: `cli-cobra uses Cobra to build a simple and powerful
 command line interface application. You can extend this application
 by adding more commands and features as needed. Great for learning
 how to build CLI apps in Go!`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := cmd.Annotations[pluginAnnotation]; ok {
+				var err error
+				if a.pluginArgs, err = a.parsePluginFlags(args); err != nil {
+					return err
+				}
+			}
+			a.initConfig()
+			for c := cmd; c != nil; c = c.Parent() {
+				if c == configCmd {
+					return nil
+				}
+			}
+			if a.configErr != nil {
+				return a.configErr
+			}
+			if a.Store == nil {
+				filename, err := a.dataFile()
+				if err != nil {
+					return err
+				}
+				a.Store = todo.FileStore{Filename: filename}
+			}
+			return nil
+		},
+	}
+	if opts.In != nil {
+		a.root.SetIn(opts.In)
+	}
+	if opts.Out != nil {
+		a.root.SetOut(opts.Out)
+	}
+	if opts.Err != nil {
+		a.root.SetErr(opts.Err)
+	}
+
+	a.root.PersistentFlags().String("datafile", "", "data file to store todos (default is $HOME/.todo.json)")
+	a.v.BindPFlag(config.KeyDataFile, a.root.PersistentFlags().Lookup("datafile"))
+	a.root.PersistentFlags().StringVar(&a.cfgFile, "config", "", "config file (default is $HOME/"+config.FileName+")")
+	a.root.PersistentFlags().BoolVar(&a.ignoreConfig, "ignore-config", false, "ignore configuration file and use default settings")
+
+	a.root.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	a.root.AddCommand(
+		newAddCmd(a),
+		newAgendaCmd(a),
+		newAssignCmd(a),
+		newBoardCmd(a),
+		newCalendarCmd(a),
+		configCmd,
+		newDoctorCmd(a),
+		newDoneCmd(a),
+		newFocusCmd(a),
+		newListCmd(a),
+		newMoveCmd(a),
+		newReviewCmd(a),
+		newScanCmd(a),
+		newSnoozeCmd(a),
+		newStatsCmd(a),
+		newTimerCmd(a),
+	)
+	a.addPlugins()
+	a.translateErrors(a.root)
+	return a.root
+}
+
+// translateErrors makes c and the commands under it return their errors
+// in the user's language, including those of the packages below cmd.
+func (a *app) translateErrors(c *cobra.Command) {
+	if pre := c.PersistentPreRunE; pre != nil {
+		c.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			return a.loc.Translate(pre(cmd, args))
+		}
+	}
+	if run := c.RunE; run != nil {
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			return a.loc.Translate(run(cmd, args))
+		}
+	}
+	for _, sub := range c.Commands() {
+		a.translateErrors(sub)
+	}
 }
 
 func Execute() {
-	err := rootCmd.Execute()
+	err := NewRootCmd(Options{}).Execute()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(-1)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
 
-func init() {
-	cobra.OnInitialize(initConfig)
+// sleep waits for d on the real clock.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-	home, err := homedir.Dir()
-	if err != nil {
-		log.Fatalf("Could not get current working directory: %v", err)
+// initConfig reads in config file and ENV variables if set.
+// Precedence is flag, then CLI_COBRA_* environment variable, then config
+// file, then the defaults from the config schema.
+func (a *app) initConfig() {
+	for _, s := range config.Settings {
+		a.v.SetDefault(s.Key, s.Default)
 	}
 
-	dataFile = filepath.Join(home, ".todo.json")
+	if a.readsEnv() {
+		a.v.SetEnvPrefix(config.EnvPrefix)
+		a.v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+		a.v.AutomaticEnv()
+
+		cfgFile, err := a.configFile()
+		if err != nil {
+			a.configErr = err
+			return
+		}
+		a.v.SetConfigFile(cfgFile)
+		a.v.SetConfigType("yaml")
+		if err := a.v.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			a.configErr = fmt.Errorf("reading config file %s: %w", cfgFile, err)
+		}
+	}
 
-	rootCmd.PersistentFlags().StringVar(&dataFile, "datafile", dataFile, "data file to store todos")
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.cli-cobra.yaml)")
-	rootCmd.PersistentFlags().BoolVar(&ignoreConfig, "ignore-config", false, "ignore configuration file and use default settings")
+	a.loc = i18n.Detect(a.v.GetString(config.KeyLanguage), a.getenv)
 
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	if a.configErr == nil {
+		if err := config.Validate(a.v.GetString); err != nil {
+			a.configErr = a.loc.Errorf("invalid configuration (run \"cli-cobra config list\"):\n%w", err)
+		}
+	}
 }
 
-// initConfig reads in config file and ENV variables if set.
-func initConfig() {
-	if ignoreConfig || os.Getenv("IGNORE_CONFIG") == "1" {
-		fmt.Fprintln(os.Stderr, "Ignoring config file and environment variables, using default settings")
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-		dataFile = filepath.Join(home, ".todo.json")
-		return
+// getenv reads locale variables such as LANG for language "auto". Trees
+// with injected settings do not look at the environment.
+func (a *app) getenv(key string) string {
+	if !a.readsEnv() {
+		return ""
 	}
+	return os.Getenv(key)
+}
 
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
+// readsEnv reports whether settings come from the config file and the
+// environment: not when --ignore-config or IGNORE_CONFIG=1 asked for the
+// built-in defaults only, nor when the settings were injected.
+func (a *app) readsEnv() bool {
+	return a.Config == nil && !a.ignoreConfig && os.Getenv("IGNORE_CONFIG") != "1"
+}
 
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".cli-cobra")
-		viper.SetDefault("datafile", filepath.Join(home, ".todo.json"))
+// configFile is the config file in use, $HOME/.cli-cobra.yaml unless
+// --config was given.
+func (a *app) configFile() (string, error) {
+	if a.cfgFile != "" {
+		return a.cfgFile, nil
 	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, config.FileName), nil
+}
 
-	viper.AutomaticEnv()
-	viper.SetEnvPrefix("new")
+// dataFile is the configured datafile with ~ expanded.
+func (a *app) dataFile() (string, error) {
+	return homedir.Expand(a.v.GetString(config.KeyDataFile))
+}
 
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
-		dataFile = viper.GetString("datafile")
-		fmt.Fprintln(os.Stderr, "Using data file:", dataFile)
-	} else {
-		fmt.Fprintln(os.Stderr, "No config file found, using default data file.")
+// load reads the task list. Anything but a missing file stops the
+// command, so a damaged file is never overwritten.
+func (a *app) load() ([]todo.Item, error) {
+	items, err := a.Store.Load()
+	if err != nil {
+		return nil, a.loc.Errorf("%w\nRun \"cli-cobra doctor\" to inspect or repair it.", err)
+	}
+	a.loaded = map[string]string{}
+	for _, item := range items {
+		a.loaded[item.ID] = itemKey(item)
+	}
+	return items, nil
+}
+
+// save writes the task list, stamping the items that were added or
+// changed since load with the current time.
+func (a *app) save(items []todo.Item) error {
+	now := a.Now()
+	for i := range items {
+		if old, ok := a.loaded[items[i].ID]; !ok || items[i].ID == "" || old != itemKey(items[i]) {
+			items[i].Updated = now
+		}
+	}
+	return a.Store.Save(items)
+}
+
+// itemKey is the stored form of an item, for telling whether it changed.
+func itemKey(i todo.Item) string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
+
+// workflow returns the configured task workflow. Commands only run once
+// the config validated, so it cannot fail here.
+func (a *app) workflow() todo.Workflow {
+	w, _ := config.Workflow(a.v.GetString)
+	return w
+}
+
+// renderOptions returns the configured rendering settings for output
+// written to w.
+func (a *app) renderOptions(w io.Writer, wrap bool) render.Options {
+	theme, _ := render.LookupTheme(a.v.GetString(config.KeyTheme))
+	layout, _ := config.DateLayout(a.v.GetString(config.KeyDateFormat))
+	return render.Options{
+		Width:      render.Width(w),
+		Wrap:       wrap,
+		Color:      render.UseColor(a.v.GetString(config.KeyColor), w),
+		Theme:      theme,
+		Scheme:     a.v.GetString(config.KeyPriorityScheme),
+		DateLayout: layout,
+		Now:        a.Now(),
+		Workflow:   a.workflow(),
+		Locale:     a.loc,
+	}
+}
+
+// taskIndex turns a task number as shown by list into an index in items.
+func (a *app) taskIndex(arg string, items []todo.Item) (int, error) {
+	i, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, a.loc.Errorf("invalid task number %q", arg)
+	}
+	if i < 1 || i > len(items) {
+		return 0, a.loc.Errorf("task number out of range: %d", i)
 	}
+	return i - 1, nil
 }
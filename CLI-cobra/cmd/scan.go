@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jubel075/cli-cobra/scan"
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/cobra"
+)
+
+// newScanCmd builds the scan command.
+func newScanCmd(a *app) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "scan [dir]",
+		Short: "Turn TODO and FIXME comments in Go code into tasks",
+		Long: `The scan command walks a Go source tree (the current directory by
+default) and keeps your task list in sync with its line comments:
+
+  // TODO(owner): text    becomes a medium priority task assigned to owner
+  // FIXME: text          becomes a high priority task
+
+Each task is linked to the file:line of its comment. Running scan again
+updates the location of tasks whose comment moved, adds new comments and
+closes tasks whose comment has disappeared. vendor, testdata, hidden and
+_ directories are skipped; tasks linked to them, or to files that do not
+parse, are left open.
+
+Examples:
+  cli-cobra scan
+  cli-cobra scan ./internal --dry-run
+  cli-cobra list --columns label,task,assignee,source`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			root := "."
+			if len(args) > 0 {
+				root = args[0]
+			}
+			root, err := filepath.Abs(root)
+			if err != nil {
+				return err
+			}
+			found, err := scan.Dir(root)
+			if err != nil {
+				return err
+			}
+			for _, f := range found.Failed {
+				a.loc.Fprintf(cmd.ErrOrStderr(), "Warning: could not read %s: %v\n", f.File, f.Err)
+			}
+
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+
+			// Tasks are matched to comments by file and text, so they
+			// survive the comment moving to another line.
+			linked := map[string][]int{}
+			for i, item := range items {
+				if file, ok := sourceFile(item.Source); ok {
+					k := file + "\x00" + item.Text
+					linked[k] = append(linked[k], i)
+				}
+			}
+
+			wf := a.workflow()
+			me := a.User
+			seen := map[int]bool{}
+			var created, updated, closed int
+			for _, c := range found.Comments {
+				k := c.File + "\x00" + c.Text
+				if idx := linked[k]; len(idx) > 0 {
+					linked[k] = idx[1:]
+					seen[idx[0]] = true
+					if items[idx[0]].Source != c.Location() {
+						items[idx[0]].Source = c.Location()
+						updated++
+					}
+					continue
+				}
+				item := todo.Item{Text: c.Text, State: wf.Initial(), Assignee: c.Owner, CreatedBy: me, Source: c.Location()}
+				if item.Assignee == "" {
+					item.Assignee = me
+				}
+				if c.Kind == "FIXME" {
+					item.SetPtiority(1)
+				} else {
+					item.SetPtiority(2)
+				}
+				items = append(items, item)
+				seen[len(items)-1] = true
+				created++
+				a.loc.Fprintf(out, "Added %s: %q (%s)\n", c.Kind, c.Text, c.Location())
+			}
+
+			for i := range items {
+				file, ok := sourceFile(items[i].Source)
+				if !ok || seen[i] || items[i].Done || !strings.HasPrefix(file, root+string(filepath.Separator)) {
+					continue
+				}
+				if !found.Scanned(file) {
+					a.loc.Fprintf(cmd.ErrOrStderr(), "Left %q open, %s was not scanned\n", items[i].Text, file)
+					continue
+				}
+				items[i].SetState(wf, wf.Final())
+				closed++
+				a.loc.Fprintf(out, "Closed %q, its comment is gone from %s\n", items[i].Text, items[i].Source)
+			}
+
+			a.loc.Fprintf(out, "Scanned %d files: %d created, %d updated, %d closed\n", found.Files, created, updated, closed)
+			if dryRun || created+updated+closed == 0 {
+				return nil
+			}
+			return a.save(items)
+		},
+	}
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Report what would change without saving")
+	return cmd
+}
+
+// sourceFile returns the file part of a file:line source link.
+func sourceFile(source string) (string, bool) {
+	i := strings.LastIndex(source, ":")
+	if i < 0 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(source[i+1:]); err != nil {
+		return "", false
+	}
+	return source[:i], true
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"time"
+
+	"github.com/jubel075/cli-cobra/config"
+	"github.com/spf13/cobra"
+)
+
+// newSnoozeCmd builds the snooze command.
+func newSnoozeCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:     "snooze <id> <when>",
+		Aliases: []string{"defer"},
+		Short:   "Hide a task from the list until a later date",
+		Long: `The snooze command sets a start date on a task. Until that day the task
+is left out of "list", so it does not clutter your view before you can act
+on it. "list --waiting" shows the snoozed tasks and "list --all" shows
+everything.
+
+The date can be today, tomorrow, a weekday (the next one after today), a
+number of days or weeks from today, or a date in the date_format setting.
+Snoozing to today or earlier wakes the task up again.
+
+Examples:
+  cli-cobra snooze 3 monday
+  cli-cobra snooze 3 3d
+  cli-cobra snooze 3 2w
+  cli-cobra snooze 3 2026-12-01
+  cli-cobra snooze 3 today`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			i, err := a.taskIndex(args[0], items)
+			if err != nil {
+				return err
+			}
+			wait, err := a.parseDate(args[1])
+			if err != nil {
+				return err
+			}
+
+			items[i].Wait = wait
+			if !items[i].Waiting(a.Now()) {
+				items[i].Wait = time.Time{}
+			}
+			if err := a.save(items); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if items[i].Wait.IsZero() {
+				a.loc.Fprintf(out, "%q is no longer snoozed\n", items[i].Text)
+				return nil
+			}
+			layout, _ := config.DateLayout(a.v.GetString(config.KeyDateFormat))
+			a.loc.Fprintf(out, "%q snoozed until %s\n", items[i].Text, a.loc.Format(wait, "Mon "+layout))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jubel075/cli-cobra/todo"
+	"github.com/spf13/cobra"
+)
+
+// newStatsCmd builds the stats command.
+func newStatsCmd(a *app) *cobra.Command {
+	var days int
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize the pomodoros and time spent on tasks",
+		Long: `The stats command sums up the focus sessions of the last week (--days
+to change that): the pomodoros finished per day, the time they add up to,
+the current streak of days with at least one, and the tasks they went to
+along with the time tracked on each.
+
+Examples:
+  cli-cobra stats
+  cli-cobra stats --days 30`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days < 1 {
+				return a.loc.Error("--days must be at least 1")
+			}
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+
+			now := a.Now()
+			o := a.renderOptions(cmd.OutOrStdout(), false)
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			from := today.AddDate(0, 0, 1-days)
+			to := today.AddDate(0, 0, 1)
+
+			type taskStats struct {
+				item  todo.Item
+				count int
+				focus time.Duration
+			}
+			var tasks []taskStats
+			perDay := make([]int, days)
+			total, focus := 0, time.Duration(0)
+			for _, item := range items {
+				done := item.PomodorosBetween(from, to)
+				if len(done) == 0 {
+					continue
+				}
+				t := taskStats{item: item, count: len(done)}
+				for _, p := range done {
+					t.focus += p.Length
+					d := p.Finished.In(now.Location())
+					day := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, now.Location())
+					perDay[int(day.Sub(from).Hours()/24+0.5)]++
+				}
+				tasks = append(tasks, t)
+				total += t.count
+				focus += t.focus
+			}
+
+			out := cmd.OutOrStdout()
+			l := a.loc
+			l.Fprintf(out, "Focus from %s to %s\n", l.Format(from, "Mon "+o.DateLayout), l.Format(today, "Mon "+o.DateLayout))
+			for d, n := range perDay {
+				line := l.Format(from.AddDate(0, 0, d), "Mon "+o.DateLayout)
+				if n > 0 {
+					line += "  " + strings.Repeat("#", n) + " " + strconv.Itoa(n)
+				}
+				fmt.Fprintf(out, "  %s\n", line)
+			}
+
+			streak := 0
+			for d := days - 1; d >= 0 && perDay[d] > 0; d-- {
+				streak++
+			}
+			fmt.Fprintln(out)
+			l.Fprintf(out, "Pomodoros: %d, %s of focus\n", total, formatDuration(focus))
+			l.Fprintf(out, "Streak: %s\n", l.Plural(streak, "1 day", "%d days"))
+			if len(tasks) == 0 {
+				return nil
+			}
+
+			sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].count > tasks[j].count })
+			fmt.Fprintln(out)
+			l.Fprintln(out, "Tasks")
+			for _, t := range tasks {
+				l.Fprintf(out, "  %s%s: %s, %s tracked in total\n", t.item.Label(), t.item.Text, l.Plural(t.count, "1 pomodoro", "%d pomodoros"), formatDuration(t.item.Elapsed(now)))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 7, "Number of days to sum up, today included")
+	return cmd
+}
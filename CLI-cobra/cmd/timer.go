@@ -0,0 +1,110 @@
+/*
+Copyright © 2025 Guilli guiliankasandikromo@gmail.com
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newTimerCmd builds the timer command and its subcommands.
+func newTimerCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "timer",
+		Short: "Track the time spent on tasks",
+		Long: `The timer command keeps track of how long you work on a task. Start a
+timer when you pick a task up and stop it when you put it down; the time is
+added to the task. Running timers are shown by "agenda".
+
+Examples:
+  cli-cobra timer start 2
+  cli-cobra timer stop 2
+  cli-cobra timer stop          # stops every running timer`,
+	}
+
+	startCmd := &cobra.Command{
+		Use:   "start <id>",
+		Short: "Start the timer of a task",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			i, err := a.taskIndex(args[0], items)
+			if err != nil {
+				return err
+			}
+			if !items[i].StartTimer(a.Now()) {
+				return a.loc.Errorf("the timer of %q is already running", items[i].Text)
+			}
+			if err := a.save(items); err != nil {
+				return err
+			}
+			a.loc.Fprintf(cmd.OutOrStdout(), "Started the timer of %q\n", items[i].Text)
+			return nil
+		},
+	}
+
+	stopCmd := &cobra.Command{
+		Use:   "stop [id]",
+		Short: "Stop the timer of a task, or every running timer",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := a.load()
+			if err != nil {
+				return err
+			}
+			stop := make([]int, 0, len(items))
+			if len(args) > 0 {
+				i, err := a.taskIndex(args[0], items)
+				if err != nil {
+					return err
+				}
+				if !items[i].Running() {
+					return a.loc.Errorf("the timer of %q is not running", items[i].Text)
+				}
+				stop = append(stop, i)
+			} else {
+				for i := range items {
+					if items[i].Running() {
+						stop = append(stop, i)
+					}
+				}
+				if len(stop) == 0 {
+					return a.loc.Error("no timer is running")
+				}
+			}
+
+			now := a.Now()
+			ran := make([]time.Duration, len(stop))
+			for n, i := range stop {
+				ran[n] = items[i].StopTimer(now)
+			}
+			if err := a.save(items); err != nil {
+				return err
+			}
+			for n, i := range stop {
+				a.loc.Fprintf(cmd.OutOrStdout(), "Stopped the timer of %q after %s (%s in total)\n",
+					items[i].Text, formatDuration(ran[n]), formatDuration(items[i].Tracked))
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(startCmd, stopCmd)
+	return cmd
+}
+
+// formatDuration renders d in hours and minutes, such as 1h05m or 25m.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	if h == 0 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", h, m)
+}
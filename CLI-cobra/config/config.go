@@ -0,0 +1,293 @@
+// Package config describes the settings understood by cli-cobra: their
+// names, defaults, environment variables and how values are validated.
+package config
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jubel075/cli-cobra/i18n"
+	"github.com/jubel075/cli-cobra/render"
+	"github.com/jubel075/cli-cobra/todo"
+	"go.yaml.in/yaml/v3"
+)
+
+// FileName is the name of the config file looked up in the home directory.
+const FileName = ".cli-cobra.yaml"
+
+// EnvPrefix is prepended to every setting to form its environment variable,
+// e.g. CLI_COBRA_DATAFILE.
+const EnvPrefix = "CLI_COBRA"
+
+// Setting keys.
+const (
+	KeyDataFile       = "datafile"
+	KeyDefaultList    = "default_list"
+	KeyPriorityScheme = "priority_scheme"
+	KeyDateFormat     = "date_format"
+	KeyColor          = "color"
+	KeyOutput         = "output"
+	KeyTheme          = "theme"
+	KeyColumns        = "columns"
+	KeyStates         = "states"
+	KeyWIPLimits      = "wip_limits"
+	KeyLanguage       = "language"
+	KeyFocusWork      = "focus_work"
+	KeyFocusShort     = "focus_short_break"
+	KeyFocusLong      = "focus_long_break"
+	KeyFocusPomodoros = "focus_pomodoros"
+)
+
+// Named date formats accepted by date_format besides a raw Go layout.
+var dateFormats = map[string]string{
+	"iso": "2006-01-02",
+	"us":  "01/02/2006",
+	"eu":  "02-01-2006",
+}
+
+// Setting documents a single configuration key.
+type Setting struct {
+	Key         string
+	Default     string
+	Description string
+	// Allowed lists the accepted values. Nil means free-form.
+	Allowed []string
+	check   func(string) error
+}
+
+// Settings is the config schema, in the order it is documented and listed.
+var Settings = []Setting{
+	{
+		Key:         KeyDataFile,
+		Default:     "~/.todo.json",
+		Description: "JSON file the tasks are stored in",
+		check: func(v string) error {
+			if strings.TrimSpace(v) == "" {
+				return i18n.Errorf("must not be empty")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         KeyDefaultList,
+		Default:     "all",
+		Description: "tasks shown by list when no filter flag is given",
+		Allowed:     []string{"pending", "done", "all"},
+	},
+	{
+		Key:         KeyPriorityScheme,
+		Default:     todo.SchemeWords,
+		Description: "how priorities are printed, as in High, 1 or A",
+		Allowed:     []string{todo.SchemeWords, todo.SchemeNumbers, todo.SchemeLetters},
+	},
+	{
+		Key:         KeyDateFormat,
+		Default:     "iso",
+		Description: "date format: iso, us, eu or a Go layout such as 2006-01-02",
+		check: func(v string) error {
+			_, err := DateLayout(v)
+			return err
+		},
+	},
+	{
+		Key:         KeyColor,
+		Default:     "auto",
+		Description: "when to color the output; auto colors it only on a terminal",
+		Allowed:     []string{"auto", "always", "never"},
+	},
+	{
+		Key:         KeyOutput,
+		Default:     "table",
+		Description: "output format of list",
+		Allowed:     []string{"table", "json", "plain"},
+	},
+	{
+		Key:         KeyTheme,
+		Default:     render.DefaultTheme,
+		Description: "color theme of the task table",
+		Allowed:     render.ThemeNames(),
+	},
+	{
+		Key:         KeyColumns,
+		Default:     strings.Join(render.DefaultColumns, ","),
+		Description: "comma-separated table columns: " + strings.Join(render.ColumnNames(), ", "),
+		check: func(v string) error {
+			_, err := render.LookupColumns(strings.Split(v, ","))
+			return err
+		},
+	},
+	{
+		Key:         KeyStates,
+		Default:     todo.DefaultStates,
+		Description: "comma-separated workflow states; the first is where new tasks start, the last means done",
+		check: func(v string) error {
+			_, err := todo.ParseWorkflow(v, "")
+			return err
+		},
+	},
+	{
+		Key:         KeyWIPLimits,
+		Default:     "",
+		Description: "work-in-progress limits per state, e.g. doing=3,review=2",
+	},
+	{
+		Key:         KeyLanguage,
+		Default:     "auto",
+		Description: "language of messages and date input; auto follows LANG",
+		Allowed:     append([]string{"auto"}, i18n.Tags()...),
+	},
+	{
+		Key:         KeyFocusWork,
+		Default:     "25m",
+		Description: "length of a focus pomodoro, e.g. 25m",
+		check:       positiveDuration,
+	},
+	{
+		Key:         KeyFocusShort,
+		Default:     "5m",
+		Description: "break after each pomodoro but the last of a cycle",
+		check:       positiveDuration,
+	},
+	{
+		Key:         KeyFocusLong,
+		Default:     "15m",
+		Description: "break at the end of a focus cycle",
+		check:       positiveDuration,
+	},
+	{
+		Key:         KeyFocusPomodoros,
+		Default:     "4",
+		Description: "pomodoros in a focus cycle",
+		check: func(v string) error {
+			if n, err := strconv.Atoi(v); err != nil || n < 1 {
+				return i18n.Errorf("must be a whole number of at least 1")
+			}
+			return nil
+		},
+	},
+}
+
+// positiveDuration accepts durations such as 25m or 1h30m.
+func positiveDuration(v string) error {
+	if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+		return i18n.Errorf("must be a duration such as 25m or 1h30m")
+	}
+	return nil
+}
+
+// Lookup returns the setting for key.
+func Lookup(key string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Keys returns every known key in schema order.
+func Keys() []string {
+	keys := make([]string, len(Settings))
+	for i, s := range Settings {
+		keys[i] = s.Key
+	}
+	return keys
+}
+
+// EnvVar is the environment variable that overrides the setting.
+func (s Setting) EnvVar() string {
+	return EnvPrefix + "_" + strings.ToUpper(s.Key)
+}
+
+// Validate reports whether value is acceptable for the setting.
+func (s Setting) Validate(value string) error {
+	if s.Allowed != nil && !slices.Contains(s.Allowed, value) {
+		return i18n.Errorf("%s %q: must be one of %s", s.Key, value, strings.Join(s.Allowed, ", "))
+	}
+	if s.check != nil {
+		if err := s.check(value); err != nil {
+			return i18n.Errorf("%s %q: %w", s.Key, value, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks every setting, reading values through get.
+// All problems are reported together.
+func Validate(get func(key string) string) error {
+	var errs []error
+	for _, s := range Settings {
+		if err := s.Validate(get(s.Key)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		if _, err := Workflow(get); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Workflow builds the task workflow from the states and wip_limits settings.
+func Workflow(get func(key string) string) (todo.Workflow, error) {
+	// Parse the states on their own first so the error names the key
+	// that is actually wrong.
+	if _, err := todo.ParseWorkflow(get(KeyStates), ""); err != nil {
+		return todo.Workflow{}, i18n.Errorf("%s %q: %w", KeyStates, get(KeyStates), err)
+	}
+	w, err := todo.ParseWorkflow(get(KeyStates), get(KeyWIPLimits))
+	if err != nil {
+		return todo.Workflow{}, i18n.Errorf("%s %q: %w", KeyWIPLimits, get(KeyWIPLimits), err)
+	}
+	return w, nil
+}
+
+// UnknownKeyError is returned for keys that are not part of the schema.
+func UnknownKeyError(key string) error {
+	return i18n.Errorf("unknown config key %q (known keys: %s)", key, strings.Join(Keys(), ", "))
+}
+
+// DateLayout resolves a date_format value to a Go time layout.
+func DateLayout(format string) (string, error) {
+	if layout, ok := dateFormats[format]; ok {
+		return layout, nil
+	}
+	// A usable layout must round-trip a date.
+	ref := time.Date(2026, time.November, 23, 0, 0, 0, 0, time.UTC)
+	parsed, err := time.Parse(format, ref.Format(format))
+	if err != nil || !parsed.Equal(ref) {
+		return "", i18n.Errorf("not iso, us, eu or a Go date layout with year, month and day")
+	}
+	return format, nil
+}
+
+// ReadFile loads the settings stored in a config file.
+// A missing file yields an empty map.
+func ReadFile(path string) (map[string]string, error) {
+	values := map[string]string{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, i18n.Errorf("parsing %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// WriteFile stores values in a config file, replacing its contents.
+func WriteFile(path string, values map[string]string) error {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
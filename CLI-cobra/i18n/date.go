@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDate reads a date typed by the user: a word such as "today" or
+// "tomorrow", a weekday (the next one after today, full or abbreviated), a
+// number of days or weeks from today such as "3d" or "2 weeks", a date in
+// layout, or a date in one of the locale's own layouts. English input is
+// understood in every locale. The error lists the accepted forms.
+func (l *Locale) ParseDate(s string, now time.Time, layout string) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	word := strings.ToLower(strings.Join(strings.Fields(s), " "))
+
+	tried := []*Locale{l}
+	if en := English(); l != en {
+		tried = append(tried, en)
+	}
+	for _, loc := range tried {
+		if loc == nil {
+			continue
+		}
+		if days, ok := loc.relativeDays[word]; ok {
+			return today.AddDate(0, 0, days), nil
+		}
+		if n, ok := loc.relative(word); ok {
+			return today.AddDate(0, 0, n), nil
+		}
+		for wd, names := range loc.weekdays {
+			for _, name := range names {
+				if word == name {
+					days := (wd - int(today.Weekday()) + 7) % 7
+					if days == 0 {
+						days = 7
+					}
+					return today.AddDate(0, 0, days), nil
+				}
+			}
+		}
+	}
+
+	if t, err := time.ParseInLocation(layout, strings.TrimSpace(s), now.Location()); err == nil {
+		return t, nil
+	}
+	for _, loc := range tried {
+		if loc == nil {
+			continue
+		}
+		in := word
+		if loc.toEnglish != nil {
+			in = loc.toEnglish.Replace(word)
+		}
+		for _, extra := range loc.dateLayouts {
+			if t, err := time.ParseInLocation(extra, in, now.Location()); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, l.Errorf("invalid date %q: use today, tomorrow, a weekday, a number of days or weeks such as 3d or 2w, or the format %s", s, layout)
+}
+
+// relative reads a number of days or weeks such as "3d" or "2 weeks".
+func (l *Locale) relative(word string) (int, bool) {
+	i := 0
+	if strings.HasPrefix(word, "+") {
+		i = 1
+	}
+	j := i
+	for j < len(word) && word[j] >= '0' && word[j] <= '9' {
+		j++
+	}
+	if j == i {
+		return 0, false
+	}
+	n, err := strconv.Atoi(word[i:j])
+	if err != nil {
+		return 0, false
+	}
+	unit := strings.TrimSpace(word[j:])
+	for _, u := range l.dayUnits {
+		if unit == u {
+			return n, true
+		}
+	}
+	for _, u := range l.weekUnits {
+		if unit == u {
+			return n * 7, true
+		}
+	}
+	return 0, false
+}
+
+// Format formats t with a Go layout, using the locale's names for weekdays
+// and months.
+func (l *Locale) Format(t time.Time, layout string) string {
+	s := t.Format(layout)
+	if l == nil || l.fromEnglish == nil {
+		return s
+	}
+	return l.fromEnglish.Replace(s)
+}
@@ -0,0 +1,24 @@
+package i18n
+
+func init() {
+	register(&Locale{
+		Tag:          "en",
+		Name:         "English",
+		relativeDays: map[string]int{"today": 0, "tomorrow": 1},
+		dayUnits:     []string{"d", "day", "days"},
+		weekUnits:    []string{"w", "week", "weeks"},
+		weekdays: [7][]string{
+			{"sunday", "sun"},
+			{"monday", "mon"},
+			{"tuesday", "tue", "tues"},
+			{"wednesday", "wed"},
+			{"thursday", "thu", "thurs"},
+			{"friday", "fri"},
+			{"saturday", "sat"},
+		},
+		dateLayouts: []string{
+			"2 January 2006", "January 2 2006", "January 2, 2006",
+			"2 Jan 2006", "Jan 2 2006", "Jan 2, 2006",
+		},
+	})
+}
@@ -0,0 +1,140 @@
+// Package i18n translates the messages of cli-cobra and reads dates typed
+// in the user's language.
+//
+// Messages are looked up by their English text, format verbs included, so
+// English needs no catalog and a missing translation falls back to English.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Locale is a language cli-cobra speaks. The nil *Locale is English.
+type Locale struct {
+	Tag  string // language code, such as "nl"
+	Name string // name of the language in itself
+
+	messages map[string]string
+
+	// Date input, all lowercase.
+	relativeDays map[string]int // "tomorrow": 1
+	dayUnits     []string
+	weekUnits    []string
+	weekdays     [7][]string // names per weekday, Sunday first
+	dateLayouts  []string    // tried after the date_format layout
+	// toEnglish turns local month names into English ones for parsing,
+	// fromEnglish does the opposite for formatting, with weekdays too.
+	toEnglish   *strings.Replacer
+	fromEnglish *strings.Replacer
+}
+
+var locales = map[string]*Locale{}
+
+func register(l *Locale) {
+	locales[l.Tag] = l
+}
+
+// English is the default locale.
+func English() *Locale {
+	return locales["en"]
+}
+
+// Lookup returns the locale for a language code such as "nl", or a locale
+// name such as "nl_NL.UTF-8".
+func Lookup(name string) (*Locale, bool) {
+	l, ok := locales[language(name)]
+	return l, ok
+}
+
+// Tags lists the language codes of every locale.
+func Tags() []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Detect picks the locale for the language setting: a language code, or
+// "auto" to follow LC_ALL, LC_MESSAGES or LANG as read by getenv.
+// Languages without a locale fall back to English.
+func Detect(setting string, getenv func(string) string) *Locale {
+	if setting != "auto" {
+		if l, ok := Lookup(setting); ok {
+			return l
+		}
+		return English()
+	}
+	for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := getenv(v); value != "" {
+			if l, ok := Lookup(value); ok {
+				return l
+			}
+			return English()
+		}
+	}
+	return English()
+}
+
+// language reduces a locale name such as "nl_BE.UTF-8@euro" to its
+// language code.
+func language(name string) string {
+	name = strings.ToLower(name)
+	if i := strings.IndexAny(name, "_-.@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// T translates a message.
+func (l *Locale) T(msg string) string {
+	if l == nil {
+		return msg
+	}
+	if t, ok := l.messages[msg]; ok {
+		return t
+	}
+	return msg
+}
+
+// Sprintf formats a translated format string. Arguments that are
+// Messages or Translatable errors are translated too, as with the other
+// formatting methods.
+func (l *Locale) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(l.T(format), l.args(args)...)
+}
+
+// Fprintf writes a translated format string to w.
+func (l *Locale) Fprintf(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, l.T(format), l.args(args)...)
+}
+
+// Fprintln writes a translated message and a newline to w.
+func (l *Locale) Fprintln(w io.Writer, msg string) {
+	fmt.Fprintln(w, l.T(msg))
+}
+
+// Errorf returns an error with a translated format string; %w wraps as
+// with fmt.Errorf.
+func (l *Locale) Errorf(format string, args ...any) error {
+	return fmt.Errorf(l.T(format), l.args(args)...)
+}
+
+// Error returns an error with a translated message.
+func (l *Locale) Error(msg string) error {
+	return errors.New(l.T(msg))
+}
+
+// Plural translates one when n is 1 and formats many with n otherwise,
+// e.g. Plural(n, "1 day", "%d days").
+func (l *Locale) Plural(n int, one, many string) string {
+	if n == 1 {
+		return l.T(one)
+	}
+	return l.Sprintf(many, n)
+}
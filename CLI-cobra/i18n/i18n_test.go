@@ -0,0 +1,200 @@
+package i18n
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// translated are the methods of Locale whose string arguments are
+// messages, the functions of this package that keep messages for later,
+// and the cmd and todo helpers that translate their first argument.
+var translated = map[string]bool{
+	"T": true, "Sprintf": true, "Fprintf": true, "Fprintln": true,
+	"Errorf": true, "Error": true, "Plural": true, "NewMessage": true,
+	"section": true, "ask": true, "problem": true,
+}
+
+// indirect are messages translated through a variable rather than a
+// literal: table headers, priorities and config sources.
+var indirect = []string{
+	"LABEL", "PRIORITY", "TASK", "STATUS", "STATE", "ASSIGNEE", "SOURCE", "DUE", "WAIT",
+	"High", "Medium", "Low",
+	"flag", "env", "file", "default",
+}
+
+// messages collects the literal messages passed to translating calls in
+// the non-test Go files of dirs.
+func messages(t *testing.T, dirs ...string) []string {
+	t.Helper()
+	var msgs []string
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range files {
+			if strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				var method string
+				switch fun := call.Fun.(type) {
+				case *ast.SelectorExpr:
+					if x, ok := fun.X.(*ast.Ident); ok && x.Name == "fmt" {
+						return true
+					}
+					method = fun.Sel.Name
+				case *ast.Ident:
+					method = fun.Name
+				}
+				if !translated[method] {
+					return true
+				}
+				for _, arg := range call.Args {
+					lit, ok := arg.(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					s, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						t.Fatal(err)
+					}
+					msgs = append(msgs, s)
+				}
+				return true
+			})
+		}
+	}
+	return append(msgs, indirect...)
+}
+
+var verb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogs(t *testing.T) {
+	msgs := messages(t, "../cmd", "../render", "../todo", "../config", ".")
+	for _, tag := range Tags() {
+		l, _ := Lookup(tag)
+		if tag == "en" {
+			if len(l.messages) > 0 {
+				t.Errorf("en: English messages are their own keys, want no catalog")
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			tr, ok := l.messages[msg]
+			if !ok {
+				t.Errorf("%s: no translation for %q", tag, msg)
+				continue
+			}
+			if got, want := verb.FindAllString(tr, -1), verb.FindAllString(msg, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q uses verbs %v, want %v", tag, tr, got, want)
+			}
+		}
+		for msg := range l.messages {
+			if !slices.Contains(msgs, msg) {
+				t.Errorf("%s: translation of %q is not used", tag, msg)
+			}
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	env := func(vars ...string) func(string) string {
+		return func(key string) string {
+			for i := 0; i < len(vars); i += 2 {
+				if vars[i] == key {
+					return vars[i+1]
+				}
+			}
+			return ""
+		}
+	}
+	tests := []struct {
+		setting string
+		getenv  func(string) string
+		want    string
+	}{
+		{"auto", env(), "en"},
+		{"auto", env("LANG", "nl_NL.UTF-8"), "nl"},
+		{"auto", env("LANG", "nl_BE.UTF-8", "LC_ALL", "C"), "en"},
+		{"auto", env("LANG", "en_US.UTF-8", "LC_MESSAGES", "nl"), "nl"},
+		{"auto", env("LANG", "fr_FR.UTF-8"), "en"},
+		{"nl", env("LANG", "en_US.UTF-8"), "nl"},
+		{"en", env("LANG", "nl_NL.UTF-8"), "en"},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.setting, tt.getenv).Tag; got != tt.want {
+			t.Errorf("Detect(%q) = %s, want %s", tt.setting, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	d := time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC)
+	nl, _ := Lookup("nl")
+	tests := []struct {
+		l      *Locale
+		layout string
+		want   string
+	}{
+		{nil, "Mon 2006-01-02", "Sat 2026-03-14"},
+		{English(), "Monday 2 January 2006", "Saturday 14 March 2026"},
+		{nl, "Mon 2006-01-02", "za 2026-03-14"},
+		{nl, "Monday 2 January 2006", "zaterdag 14 maart 2026"},
+		{nl, "Mon 2 Jan", "za 14 mrt"},
+	}
+	for _, tt := range tests {
+		if got := tt.l.Format(d, tt.layout); got != tt.want {
+			t.Errorf("%s: Format(%q) = %q, want %q", tt.l.Tag, tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	nl, _ := Lookup("nl")
+	wrapped := Errorf("parsing %s: %w", "x.yaml", fs.ErrNotExist)
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{Errorf("must not be empty"), "mag niet leeg zijn"},
+		{wrapped, "x.yaml lezen: " + fs.ErrNotExist.Error()},
+		{Errorf("%s %q: %w", "datafile", "", Errorf("must not be empty")), `datafile "": mag niet leeg zijn`},
+		{errors.Join(Errorf("file is empty"), errors.New("as is")), "bestand is leeg\nas is"},
+		{errors.New("as is"), "as is"},
+	}
+	for _, tt := range tests {
+		got := nl.Translate(tt.err)
+		if got.Error() != tt.want {
+			t.Errorf("Translate(%q) = %q, want %q", tt.err, got, tt.want)
+		}
+		if !errors.Is(got, tt.err) {
+			t.Errorf("Translate(%q) does not wrap the original", tt.err)
+		}
+	}
+	if !errors.Is(nl.Translate(wrapped), fs.ErrNotExist) {
+		t.Errorf("Translate(%q) loses the error it wraps", wrapped)
+	}
+	if nl.Translate(nil) != nil {
+		t.Errorf("Translate(nil) != nil")
+	}
+}
@@ -0,0 +1,111 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Message is a message kept as its English format and arguments until it
+// is shown, so the packages below cmd, which do not know the user's
+// language, can still be shown in it. Arguments that are Messages, or
+// errors made by Errorf, are translated along with it.
+type Message struct {
+	Format string
+	Args   []any
+}
+
+// NewMessage returns the message format says about args.
+func NewMessage(format string, args ...any) Message {
+	return Message{Format: format, Args: args}
+}
+
+// MessageOf is the message of err: the one it was made with if it is
+// Translatable, otherwise its text as it is.
+func MessageOf(err error) Message {
+	if t, ok := err.(Translatable); ok {
+		return t.Message()
+	}
+	return Message{Format: "%s", Args: []any{err.Error()}}
+}
+
+// String is the message in English.
+func (m Message) String() string {
+	return English().Text(m)
+}
+
+// Text formats m in l's language.
+func (l *Locale) Text(m Message) string {
+	return fmt.Sprintf(strings.ReplaceAll(l.T(m.Format), "%w", "%v"), l.args(m.Args)...)
+}
+
+// Translatable is an error whose text can be shown in another language.
+type Translatable interface {
+	error
+	Message() Message
+}
+
+// Error is a Translatable error; Errorf makes one.
+type Error struct {
+	msg     Message
+	wrapped error
+}
+
+// Errorf returns an error with the message format says about args,
+// translated when it is shown. %w wraps as with fmt.Errorf.
+func Errorf(format string, args ...any) error {
+	return &Error{msg: NewMessage(format, args...), wrapped: errors.Unwrap(fmt.Errorf(format, args...))}
+}
+
+func (e *Error) Error() string    { return e.msg.String() }
+func (e *Error) Message() Message { return e.msg }
+func (e *Error) Unwrap() error    { return e.wrapped }
+
+// localized is an error shown in the user's language. It unwraps to the
+// original, so errors.Is and errors.As still see it.
+type localized struct {
+	text string
+	err  error
+}
+
+func (t *localized) Error() string { return t.text }
+func (t *localized) Unwrap() error { return t.err }
+
+// Translate returns err in l's language if it is Translatable, or joins
+// errors with errors.Join that are, and err itself otherwise.
+func (l *Locale) Translate(err error) error {
+	if t, ok := err.(Translatable); ok {
+		return &localized{text: l.Text(t.Message()), err: err}
+	}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := j.Unwrap()
+		lines := make([]string, len(errs))
+		for i, e := range errs {
+			lines[i] = e.Error()
+		}
+		// Only errors.Join puts one error on each line.
+		if strings.Join(lines, "\n") == err.Error() {
+			for i, e := range errs {
+				lines[i] = l.Translate(e).Error()
+			}
+			return &localized{text: strings.Join(lines, "\n"), err: err}
+		}
+	}
+	return err
+}
+
+// args translates the arguments that are Messages or Translatable errors.
+func (l *Locale) args(args []any) []any {
+	out := make([]any, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case Message:
+			out[i] = l.Text(arg)
+		case error:
+			out[i] = l.Translate(arg)
+		default:
+			out[i] = arg
+		}
+	}
+	return out
+}
@@ -0,0 +1,216 @@
+package i18n
+
+import "strings"
+
+func init() {
+	register(&Locale{
+		Tag:          "nl",
+		Name:         "Nederlands",
+		messages:     dutch,
+		relativeDays: map[string]int{"vandaag": 0, "morgen": 1, "overmorgen": 2},
+		dayUnits:     []string{"d", "dag", "dagen"},
+		weekUnits:    []string{"w", "wk", "week", "weken"},
+		weekdays: [7][]string{
+			{"zondag", "zo"},
+			{"maandag", "ma"},
+			{"dinsdag", "di"},
+			{"woensdag", "wo"},
+			{"donderdag", "do"},
+			{"vrijdag", "vr"},
+			{"zaterdag", "za"},
+		},
+		dateLayouts: []string{"2-1-2006", "2/1/2006", "2 January 2006", "2 Jan 2006"},
+		toEnglish: strings.NewReplacer(
+			"januari", "january", "februari", "february", "maart", "march",
+			"mei", "may", "juni", "june", "juli", "july", "augustus", "august",
+			"oktober", "october", "mrt", "mar", "okt", "oct",
+		),
+		// Long names come first so that "Monday" is not read as "Mon".
+		fromEnglish: strings.NewReplacer(
+			"Monday", "maandag", "Tuesday", "dinsdag", "Wednesday", "woensdag",
+			"Thursday", "donderdag", "Friday", "vrijdag", "Saturday", "zaterdag",
+			"Sunday", "zondag",
+			"Mon", "ma", "Tue", "di", "Wed", "wo", "Thu", "do", "Fri", "vr",
+			"Sat", "za", "Sun", "zo",
+			"January", "januari", "February", "februari", "March", "maart",
+			"April", "april", "May", "mei", "June", "juni", "July", "juli",
+			"August", "augustus", "September", "september", "October", "oktober",
+			"November", "november", "December", "december",
+			"Jan", "jan", "Feb", "feb", "Mar", "mrt", "Apr", "apr", "Jun", "jun",
+			"Jul", "jul", "Aug", "aug", "Sep", "sep", "Oct", "okt", "Nov", "nov",
+			"Dec", "dec",
+		),
+	})
+}
+
+var dutch = map[string]string{
+	// Tables and settings.
+	"LABEL":                                 "NR",
+	"PRIORITY":                              "PRIORITEIT",
+	"TASK":                                  "TAAK",
+	"STATUS":                                "STATUS",
+	"STATE":                                 "FASE",
+	"ASSIGNEE":                              "TOEGEWEZEN",
+	"SOURCE":                                "BRON",
+	"DUE":                                   "DEADLINE",
+	"WAIT":                                  "WACHT",
+	"High":                                  "Hoog",
+	"Medium":                                "Gemiddeld",
+	"Low":                                   "Laag",
+	"KEY\tVALUE\tSOURCE\tENV":               "SLEUTEL\tWAARDE\tBRON\tENV",
+	"flag":                                  "vlag",
+	"env":                                   "omgeving",
+	"file":                                  "bestand",
+	"default":                               "standaard",
+	"1 day":                                 "1 dag",
+	"1 task":                                "1 taak",
+	"%d tasks":                              "%d taken",
+	"%d days":                               "%d dagen",
+	"unknown column %q (known columns: %s)": "onbekende kolom %q (bekende kolommen: %s)",
+	"no columns selected":                   "geen kolommen gekozen",
+
+	// Tasks.
+	"Added task: %q\n":                        "Taak toegevoegd: %q\n",
+	"%q marked as done\n":                     "%q is afgerond\n",
+	"%q assigned to %s\n":                     "%q toegewezen aan %s\n",
+	"%q moved to %s\n":                        "%q verplaatst naar %s\n",
+	"%q is no longer snoozed\n":               "%q wacht niet meer\n",
+	"%q snoozed until %s\n":                   "%q uitgesteld tot %s\n",
+	"You have %d tasks in your to-do list:\n": "Je hebt %d taken op je takenlijst:\n",
+	"You have %d tasks in your to-do list (%d snoozed, see list --waiting):\n": "Je hebt %d taken op je takenlijst (%d uitgesteld, zie list --waiting):\n",
+	"Warning: %s holds %d tasks, over its WIP limit of %d\n":                   "Let op: %s bevat %d taken, meer dan de WIP-limiet van %d\n",
+	"Warning: %s now holds %d tasks, over its WIP limit of %d\n":               "Let op: %s bevat nu %d taken, meer dan de WIP-limiet van %d\n",
+	"reading stdin: %w":               "lezen van stdin: %w",
+	"reading %s: %w":                  "lezen van %s: %w",
+	"invalid task number %q":          "ongeldig taaknummer %q",
+	"task number out of range: %d":    "taaknummer bestaat niet: %d",
+	"unknown state %q, states are %s": "onbekende fase %q, de fases zijn %s",
+	"invalid date %q: use today, tomorrow, a weekday, a number of days or weeks such as 3d or 2w, or the format %s": "ongeldige datum %q: gebruik vandaag, morgen, een weekdag, een aantal dagen of weken zoals 3d of 2w, of het formaat %s",
+	"no workflow states configured":                         "er zijn geen fases ingesteld",
+	"%w\nRun \"cli-cobra doctor\" to inspect or repair it.": "%w\nGebruik \"cli-cobra doctor\" om het bestand te controleren of te herstellen.",
+	"flag needs an argument: --%s":                          "vlag heeft een waarde nodig: --%s",
+	"unknown state %q (states: %s)":                         "onbekende fase %q (fases: %s)",
+	"invalid state name %q":                                 "ongeldige fasenaam %q",
+	"state %q is listed twice":                              "fase %q staat er twee keer in",
+	"a workflow needs at least two states":                  "een werkwijze heeft minstens twee fases nodig",
+	"invalid WIP limit %q, want state=number":               "ongeldige WIP-limiet %q, verwacht fase=aantal",
+	"WIP limit for unknown state %q":                        "WIP-limiet voor onbekende fase %q",
+	`nothing to add: give the task as an argument, "-" to read it from standard input, or --from-file`: `niets toe te voegen: geef de taak als argument, "-" om hem van standaardinvoer te lezen, of --from-file`,
+
+	// Timers.
+	"Started the timer of %q\n":                        "Timer van %q gestart\n",
+	"Stopped the timer of %q after %s (%s in total)\n": "Timer van %q gestopt na %s (%s in totaal)\n",
+	"the timer of %q is already running":               "de timer van %q loopt al",
+	"the timer of %q is not running":                   "de timer van %q loopt niet",
+	"no timer is running":                              "er loopt geen timer",
+
+	// Agenda and review.
+	"Agenda for %s":        "Agenda voor %s",
+	"Overdue":              "Te laat",
+	"Due today":            "Vandaag",
+	"Next up":              "Hierna",
+	"Running timers":       "Lopende timers",
+	"nothing":              "niets",
+	"due %s":               "deadline %s",
+	"due today":            "deadline vandaag",
+	"due %s, %s overdue":   "deadline %s, %s te laat",
+	"timer running for %s": "timer loopt %s",
+	"Nothing to review: every open task changed in the last %s.\n": "Niets te doen: elke open taak is in de laatste %s gewijzigd.\n",
+	"keep, priority, snooze, delete or quit? [k/p/s/d/q] ":         "houden (k), prioriteit (p), uitstellen (s), verwijderen (d) of stoppen (q)? [k/p/s/d/q] ",
+	"New priority (1=high, 2=medium, 3=low): ":                     "Nieuwe prioriteit (1=hoog, 2=gemiddeld, 3=laag): ",
+	"Please enter 1, 2 or 3.":                                      "Kies 1, 2 of 3.",
+	"Snooze until: ":                                               "Uitstellen tot: ",
+	"Pick a day after today.":                                      "Kies een dag na vandaag.",
+	"Please answer k, p, s, d or q.":                               "Antwoord k, p, s, d of q.",
+	"Kept: %s":                                                     "Gehouden: %s",
+	"Reprioritized: %s (%s to %s)":                                 "Nieuwe prioriteit: %s (%s naar %s)",
+	"Snoozed: %s until %s":                                         "Uitgesteld: %s tot %s",
+	"Deleted: %s":                                                  "Verwijderd: %s",
+	"Review of %s: %d of %d stale tasks":                           "Overzicht van %s: %d van %d stilgevallen taken",
+	"nothing changed":                                              "niets gewijzigd",
+
+	// Calendar.
+	"Week %d of %d":                        "Week %d van %d",
+	"today":                                "vandaag",
+	"+%d more":                             "+%d meer",
+	"\n%s without a due date not shown.\n": "\n%s zonder deadline niet getoond.\n",
+	"--month cannot be combined with --week or a date":                                       "--month gaat niet samen met --week of een datum",
+	"invalid month %q: use the format 2026-11, or +1 and -1 for the next and previous month": "ongeldige maand %q: gebruik het formaat 2026-11, of +1 en -1 voor de volgende en vorige maand",
+	"empty calendar period": "lege kalenderperiode",
+
+	// Focus and stats.
+	"Pomodoro %d of %d on %q":                                    "Pomodoro %d van %d aan %q",
+	"%s: %s, until %s\n":                                         "%s: %s, tot %s\n",
+	"Finished pomodoro %d of %d, %d on this task so far\n":       "Pomodoro %d van %d klaar, %d aan deze taak tot nu toe\n",
+	"Stopped after %s; the unfinished pomodoro is not logged.\n": "Gestopt na %s; de onafgemaakte pomodoro telt niet mee.\n",
+	"Short break":                         "Korte pauze",
+	"Long break":                          "Lange pauze",
+	"Break cut short.":                    "Pauze afgebroken.",
+	"Cycle complete: %s of focus on %q\n": "Cyclus klaar: %s gefocust op %q\n",
+	"%q is already done":                  "%q is al afgerond",
+	"%q was deleted in the meantime":      "%q is intussen verwijderd",
+	"the number of pomodoros and every length must be positive": "het aantal pomodoro's en elke duur moeten groter dan nul zijn",
+	"--days must be at least 1":                                 "--days moet minstens 1 zijn",
+	"Focus from %s to %s\n":                                     "Focus van %s tot %s\n",
+	"Pomodoros: %d, %s of focus\n":                              "Pomodoro's: %d, %s gefocust\n",
+	"Streak: %s\n":                                              "Reeks: %s\n",
+	"Tasks":                                                     "Taken",
+	"  %s%s: %s, %s tracked in total\n":                         "  %s%s: %s, %s bijgehouden in totaal\n",
+	"1 pomodoro":                                                "1 pomodoro",
+	"%d pomodoros":                                              "%d pomodoro's",
+
+	// Config.
+	"Config file: %s\n":                  "Configuratiebestand: %s\n",
+	"Set %s = %s in %s\n":                "%s = %s ingesteld in %s\n",
+	"Note: %s=%s overrides this value\n": "Let op: %s=%s gaat voor deze waarde\n",
+	"%s is not set in %s\n":              "%s is niet ingesteld in %s\n",
+	"Unset %s in %s\n":                   "%s verwijderd uit %s\n",
+	"invalid configuration (run \"cli-cobra config list\"):\n%w": "ongeldige configuratie (zie \"cli-cobra config list\"):\n%w",
+	"must not be empty":                                            "mag niet leeg zijn",
+	"must be a whole number of at least 1":                         "moet een heel getal van minstens 1 zijn",
+	"must be a duration such as 25m or 1h30m":                      "moet een duur zijn zoals 25m of 1h30m",
+	"%s %q: must be one of %s":                                     "%s %q: moet een van deze zijn: %s",
+	"%s %q: %w":                                                    "%s %q: %w",
+	"unknown config key %q (known keys: %s)":                       "onbekende instelling %q (bekende instellingen: %s)",
+	"not iso, us, eu or a Go date layout with year, month and day": "niet iso, us, eu of een Go-datumopmaak met jaar, maand en dag",
+	"parsing %s: %w":                                               "%s lezen: %w",
+
+	// Doctor.
+	"%s does not exist yet; it is created when you add a task.\n": "%s bestaat nog niet; het wordt aangemaakt bij de eerste taak.\n",
+	"Data file: %s (format version %s, current %d)\n":             "Gegevensbestand: %s (formaatversie %s, huidige %d)\n",
+	"unknown":                          "onbekend",
+	"No problems found in %d tasks.\n": "Geen problemen gevonden in %d taken.\n",
+	"task %d":                          "taak %d",
+	" (cannot be fixed automatically)": " (niet automatisch te herstellen)",
+	"Repaired %s, %d tasks kept. The original is in %s.doctor.bak.\n": "%s hersteld, %d taken behouden. Het origineel staat in %s.doctor.bak.\n",
+	"some problems need manual attention; the file was not changed":   "sommige problemen moeten met de hand worden opgelost; het bestand is niet gewijzigd",
+	"run \"cli-cobra doctor --fix\" to repair them":                   "gebruik \"cli-cobra doctor --fix\" om ze te herstellen",
+	"cannot be decoded (%v); %d tasks can be recovered from it":       "kan niet worden gelezen (%v); %d taken zijn eruit te redden",
+	"stored in format version %d, upgrading to %d":                    "opgeslagen in formaatversie %d, wordt bijgewerkt naar %d",
+	"missing ID":                               "ID ontbreekt",
+	"duplicate ID, assigning a new one":        "dubbele ID, krijgt een nieuwe",
+	"empty task text, removing the task":       "lege taaktekst, de taak wordt verwijderd",
+	"invalid priority %d, resetting to medium": "ongeldige prioriteit %d, wordt gemiddeld",
+	"unknown state %q, mapping to %q":          "onbekende fase %q, wordt %q",
+	"done flag disagrees with state %q":        "afgerond-vlag klopt niet met fase %q",
+	"backing up %s before repairing it: %w":    "reservekopie van %s maken voor het herstel: %w",
+
+	// Data file.
+	"%s was changed by someone else and these tasks conflict: %s; re-run the command to apply it on top of their changes": "%s is door iemand anders gewijzigd en deze taken botsen: %s; voer de opdracht opnieuw uit om hem op hun wijzigingen toe te passen",
+	"%s is locked by %s; remove %s if nobody is writing to it":                                                            "%s is vergrendeld door %s; verwijder %s als niemand erin schrijft",
+	"%s cannot be read: %v": "%s kan niet worden gelezen: %v",
+	"file is empty":         "bestand is leeg",
+	"invalid version %d":    "ongeldige versie %d",
+	"not a JSON task list":  "geen JSON-takenlijst",
+	"written by a newer cli-cobra (format version %d, this one reads up to %d)": "geschreven door een nieuwere cli-cobra (formaatversie %d, deze leest tot en met %d)",
+	"no migration from format version %d":                                       "geen omzetting vanaf formaatversie %d",
+	"migrating from version %d: %w":                                             "omzetten vanaf versie %d: %w",
+	"backing up %s before upgrading it: %w":                                     "reservekopie van %s maken voor het bijwerken: %w",
+
+	// Scan.
+	"Added %s: %q (%s)\n":                                   "%s toegevoegd: %q (%s)\n",
+	"Closed %q, its comment is gone from %s\n":              "%q afgerond, het commentaar is weg uit %s\n",
+	"Scanned %d files: %d created, %d updated, %d closed\n": "%d bestanden doorzocht: %d aangemaakt, %d bijgewerkt, %d afgerond\n",
+	"Warning: could not read %s: %v\n":                      "Let op: %s kon niet worden gelezen: %v\n",
+	"Left %q open, %s was not scanned\n":                    "%q blijft open, %s is niet doorzocht\n",
+}
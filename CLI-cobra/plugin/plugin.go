@@ -0,0 +1,218 @@
+// Package plugin runs external cli-cobra-<name> executables as
+// subcommands, git-style, and serves their requests for tasks.
+//
+// A plugin is started with the arguments given after its name and with
+// CLI_COBRA_* environment variables holding the resolved settings. Its
+// stderr goes straight to the user. Its stdin and stdout carry the
+// protocol: every line the plugin writes that is a JSON object with a
+// "method" is a Request, answered with one Response line on its stdin.
+// Any other line is output and is copied to the user, so a plugin that
+// does not need tasks can simply print.
+//
+//	> {"id":1,"method":"read"}
+//	< {"id":1,"items":[{"ID":"4f0c...","Text":"Buy milk","Priority":2,...}]}
+//	> {"id":2,"method":"write","items":[...]}
+//	< {"id":2,"items":[...]}
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/jubel075/cli-cobra/todo"
+)
+
+// Prefix starts the file name of every plugin executable.
+const Prefix = "cli-cobra-"
+
+// ProtocolVersion is passed to plugins as CLI_COBRA_PLUGIN_PROTOCOL and
+// changes when requests or responses change incompatibly.
+const ProtocolVersion = 1
+
+// Plugin is an executable found on the search path.
+type Plugin struct {
+	Name string // the subcommand, the file name without Prefix
+	Path string
+}
+
+// Request is a message from a plugin.
+//
+//	read   returns the task list, in the order task numbers refer to
+//	write  replaces the task list with Items and returns it as saved,
+//	       with IDs assigned; changes made by others are merged
+type Request struct {
+	ID     int         `json:"id"`
+	Method string      `json:"method"`
+	Items  []todo.Item `json:"items,omitempty"`
+}
+
+// Response answers a Request with the same ID.
+type Response struct {
+	ID    int         `json:"id"`
+	Items []todo.Item `json:"items"`
+	Error string      `json:"error,omitempty"`
+}
+
+// Host serves the requests of a running plugin.
+type Host interface {
+	Read() ([]todo.Item, error)
+	Write(items []todo.Item) error
+}
+
+// Discover finds the plugins in the directories of path, which is in
+// PATH format. When several directories hold a plugin of the same name,
+// the first one wins, as it would for a shell. Plugins are sorted by name.
+func Discover(path string) []Plugin {
+	seen := map[string]bool{}
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := pluginName(e.Name())
+			if !ok || seen[name] {
+				continue
+			}
+			full := filepath.Join(dir, e.Name())
+			if !executable(full) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: full})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// pluginName returns the subcommand name for a plugin file name.
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(file)
+		if !strings.EqualFold(ext, ".exe") {
+			return "", false
+		}
+		file = strings.TrimSuffix(file, ext)
+	}
+	name, ok := strings.CutPrefix(file, Prefix)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", false
+	}
+	return name, true
+}
+
+func executable(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || fi.Mode().Perm()&0111 != 0
+}
+
+// Run starts the plugin with args and env added to the environment, and
+// serves its requests through host until it exits. Output lines go to
+// stdout and its stderr to stderr.
+func (p Plugin) Run(args, env []string, host Host, stdout, stderr io.Writer) error {
+	cmd := exec.Command(p.Path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	serveErr := serve(bufio.NewReader(out), in, host, stdout)
+	in.Close()
+	if serveErr != nil {
+		// Drain the plugin's output so it can exit.
+		io.Copy(io.Discard, out)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(p.Path), err)
+	}
+	return serveErr
+}
+
+// serve reads the plugin's lines until EOF, answering requests on w and
+// copying everything else to stdout.
+func serve(r *bufio.Reader, w io.Writer, host Host, stdout io.Writer) error {
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			if req, ok := parseRequest(line); ok {
+				data, merr := json.Marshal(handle(req, host))
+				if merr != nil {
+					return merr
+				}
+				// A plugin that exits without reading the answer is
+				// not an error; its exit status tells how it went.
+				w.Write(append(data, '\n'))
+			} else if _, werr := stdout.Write(line); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseRequest tells protocol lines apart from output.
+func parseRequest(line []byte) (Request, bool) {
+	var req Request
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return req, false
+	}
+	if err := json.Unmarshal(trimmed, &req); err != nil || req.Method == "" {
+		return req, false
+	}
+	return req, true
+}
+
+func handle(req Request, host Host) Response {
+	resp := Response{ID: req.ID}
+	var err error
+	switch req.Method {
+	case "read":
+		resp.Items, err = host.Read()
+	case "write":
+		items := req.Items
+		if items == nil {
+			items = []todo.Item{}
+		}
+		if err = host.Write(items); err == nil {
+			resp.Items = items
+		}
+	default:
+		err = fmt.Errorf("unknown method %q", req.Method)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
@@ -0,0 +1,73 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jubel075/cli-cobra/todo"
+)
+
+// boardColumnWidth is the width of a board column when the output is not
+// fitted to a terminal.
+const boardColumnWidth = 24
+
+// minBoardColumnWidth keeps columns readable on narrow terminals.
+const minBoardColumnWidth = 8
+
+// Board writes items as side-by-side columns, one per workflow state.
+// Column headers show the task count and the WIP limit, and are styled as
+// overdue when the limit is exceeded.
+func Board(w io.Writer, items []todo.Item, o Options) error {
+	wf := o.Workflow
+	n := len(wf.States)
+	if n == 0 {
+		return o.Locale.Error("no workflow states configured")
+	}
+
+	width := boardColumnWidth
+	if o.Width > 0 {
+		width = max(minBoardColumnWidth, (o.Width-gap*(n-1))/n)
+	}
+
+	cols := make([][]string, n)
+	styles := make([][]string, n)
+	for c, state := range wf.States {
+		count := wf.Count(items, state)
+		title := fmt.Sprintf("%s (%d)", strings.ToUpper(state), count)
+		style := o.Theme.Header
+		if limit, ok := wf.WIP[state]; ok {
+			title = fmt.Sprintf("%s (%d/%d)", strings.ToUpper(state), count, limit)
+			if count > limit {
+				style = o.Theme.Overdue
+			}
+		}
+		for _, line := range fit(title, width, false) {
+			cols[c] = append(cols[c], line)
+			styles[c] = append(styles[c], style)
+		}
+		cols[c] = append(cols[c], strings.Repeat("-", width))
+		styles[c] = append(styles[c], "")
+
+		for _, item := range items {
+			if item.StateIn(wf) != state {
+				continue
+			}
+			style := cellStyle("priority", item, o)
+			if item.Overdue(o.Now) {
+				style = o.Theme.Overdue
+			}
+			for _, line := range fit(item.Label()+item.Text, width, o.Wrap) {
+				cols[c] = append(cols[c], line)
+				styles[c] = append(styles[c], style)
+			}
+		}
+	}
+
+	widths := make([]int, n)
+	for c := range widths {
+		widths[c] = width
+	}
+	writeRow(w, cols, styles, widths, o.Color)
+	return nil
+}
@@ -0,0 +1,151 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jubel075/cli-cobra/i18n"
+	"github.com/jubel075/cli-cobra/todo"
+)
+
+// calendarColumnWidth is the width of a day when the output is not fitted
+// to a terminal.
+const calendarColumnWidth = 12
+
+// minCalendarColumnWidth keeps days readable on narrow terminals.
+const minCalendarColumnWidth = 6
+
+// Period is the span of whole weeks, Monday to Sunday, that a calendar
+// shows.
+type Period struct {
+	Start time.Time  // first day shown, a Monday
+	Weeks int        // number of weeks shown
+	Month time.Month // the month of a month view, 0 for a week view
+	// Lines caps the tasks listed per day; busier days end in "+N more".
+	// 0 means no cap.
+	Lines int
+}
+
+// MonthPeriod covers the weeks holding any day of the month first is in.
+func MonthPeriod(first time.Time, lines int) Period {
+	first = time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, first.Location())
+	start := monday(first)
+	last := first.AddDate(0, 1, -1)
+	// Step by calendar weeks: a day is not always 24 hours long.
+	weeks := 0
+	for week := start; !week.After(last); week = week.AddDate(0, 0, 7) {
+		weeks++
+	}
+	return Period{Start: start, Weeks: weeks, Month: first.Month(), Lines: lines}
+}
+
+// WeekPeriod covers the week holding day.
+func WeekPeriod(day time.Time, lines int) Period {
+	return Period{Start: monday(day), Weeks: 1, Lines: lines}
+}
+
+// End is the day after the last one shown.
+func (p Period) End() time.Time {
+	return p.Start.AddDate(0, 0, 7*p.Weeks)
+}
+
+// Contains reports whether t falls on a day shown.
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End())
+}
+
+// monday returns the start of the Monday on or before t.
+func monday(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// Calendar writes the days of p as a grid, one column per weekday, with
+// the tasks due on each day listed under it in priority order and colored
+// like the board. Today is marked, and days over p.Lines tasks end with a
+// count of the ones left out.
+func Calendar(w io.Writer, items []todo.Item, p Period, o Options) error {
+	if p.Weeks < 1 {
+		return i18n.Errorf("empty calendar period")
+	}
+	width := calendarColumnWidth
+	if o.Width > 0 {
+		width = max(minCalendarColumnWidth, (o.Width-gap*6)/7)
+	}
+	widths := make([]int, 7)
+	for c := range widths {
+		widths[c] = width
+	}
+
+	byDay := map[string][]todo.Item{}
+	for _, item := range items {
+		if !item.Due.IsZero() && p.Contains(item.Due) {
+			key := item.Due.Format(time.DateOnly)
+			byDay[key] = append(byDay[key], item)
+		}
+	}
+	for _, day := range byDay {
+		slices.SortStableFunc(day, func(a, b todo.Item) int { return a.Priority - b.Priority })
+	}
+
+	l := o.Locale
+	title := l.Format(p.Start.AddDate(0, 0, 6), "January 2006")
+	if p.Month == 0 {
+		year, week := p.Start.ISOWeek()
+		title = l.Sprintf("Week %d of %d", week, year)
+	}
+	writeRow(w, [][]string{{title}}, [][]string{{o.Theme.Header}}, []int{utf8.RuneCountInString(title)}, o.Color)
+
+	names := make([][]string, 7)
+	styles := make([][]string, 7)
+	for c := range names {
+		names[c] = fit(l.Format(p.Start.AddDate(0, 0, c), "Mon"), width, false)
+		styles[c] = []string{o.Theme.Header}
+	}
+	writeRow(w, names, styles, widths, o.Color)
+
+	today := o.Now.Format(time.DateOnly)
+	for week := range p.Weeks {
+		cols := make([][]string, 7)
+		styles := make([][]string, 7)
+		for c := range cols {
+			day := p.Start.AddDate(0, 0, 7*week+c)
+			label := l.Format(day, "2")
+			if p.Month == 0 || day.Day() == 1 || week == 0 && c == 0 {
+				label = l.Format(day, "2 Jan")
+			}
+			style := ""
+			if day.Format(time.DateOnly) == today {
+				label += " " + l.T("today")
+				style = o.Theme.Header
+			}
+			cols[c] = append(cols[c], fit(label, width, false)[0], strings.Repeat("-", width))
+			styles[c] = append(styles[c], style, "")
+
+			due := byDay[day.Format(time.DateOnly)]
+			shown := due
+			if p.Lines > 0 && len(due) > p.Lines {
+				shown = due[:p.Lines-1]
+			}
+			for _, item := range shown {
+				style := cellStyle("priority", item, o)
+				if item.Overdue(o.Now) {
+					style = o.Theme.Overdue
+				}
+				cols[c] = append(cols[c], fit(item.Label()+item.Text, width, false)[0])
+				styles[c] = append(styles[c], style)
+			}
+			if len(shown) < len(due) {
+				cols[c] = append(cols[c], fit(l.Sprintf("+%d more", len(due)-len(shown)), width, false)[0])
+				styles[c] = append(styles[c], o.Theme.Header)
+			}
+		}
+		fmt.Fprintln(w)
+		writeRow(w, cols, styles, widths, o.Color)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package render
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonthPeriod(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		first time.Time
+		weeks int
+	}{
+		// The clocks go forward on 30 March; the 31st is a Monday.
+		{time.Date(2025, time.March, 1, 0, 0, 0, 0, amsterdam), 6},
+		{time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), 6},
+		{time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC), 5},
+		// A February that starts on a Monday fits in four weeks.
+		{time.Date(2027, time.February, 1, 0, 0, 0, 0, time.UTC), 4},
+	}
+	for _, tt := range tests {
+		p := MonthPeriod(tt.first, 0)
+		if p.Weeks != tt.weeks || p.Start.Weekday() != time.Monday {
+			t.Errorf("%s: %d weeks from %s, want %d", tt.first.Format("January 2006 MST"), p.Weeks, p.Start.Format(time.DateOnly), tt.weeks)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package render draws task lists for the terminal: aligned columns,
+// colors from a theme, and task text fitted to the terminal width.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jubel075/cli-cobra/i18n"
+	"github.com/jubel075/cli-cobra/todo"
+)
+
+// gap is the number of spaces between columns.
+const gap = 2
+
+// minTaskWidth is how narrow the task column may get when fitting a table.
+const minTaskWidth = 10
+
+// Options controls how a table is drawn.
+type Options struct {
+	Columns    []string
+	Width      int // terminal width, 0 means do not fit
+	Wrap       bool
+	Color      bool
+	Theme      Theme
+	Scheme     string // priority scheme, see todo.PriorityIn
+	DateLayout string
+	Now        time.Time
+	Workflow   todo.Workflow
+	Locale     *i18n.Locale // language of headers and priorities, nil for English
+}
+
+// Column is a selectable table column.
+type Column struct {
+	Name   string
+	Header string
+	cell   func(i todo.Item, o Options) string
+}
+
+var columns = []Column{
+	{"label", "LABEL", func(i todo.Item, o Options) string { return strings.TrimSpace(i.Label()) }},
+	{"priority", "PRIORITY", func(i todo.Item, o Options) string { return o.Locale.T(i.PriorityIn(o.Scheme)) }},
+	{"task", "TASK", func(i todo.Item, o Options) string { return i.Text }},
+	{"status", "STATUS", func(i todo.Item, o Options) string { return strings.TrimSpace(i.PrettyDone()) }},
+	{"state", "STATE", func(i todo.Item, o Options) string {
+		if len(o.Workflow.States) == 0 {
+			return i.State
+		}
+		return i.StateIn(o.Workflow)
+	}},
+	{"assignee", "ASSIGNEE", func(i todo.Item, o Options) string { return i.Assignee }},
+	{"source", "SOURCE", func(i todo.Item, o Options) string { return i.Source }},
+	{"due", "DUE", func(i todo.Item, o Options) string {
+		if i.Due.IsZero() {
+			return ""
+		}
+		return o.Locale.Format(i.Due, o.DateLayout)
+	}},
+	{"wait", "WAIT", func(i todo.Item, o Options) string {
+		if i.Wait.IsZero() {
+			return ""
+		}
+		return o.Locale.Format(i.Wait, o.DateLayout)
+	}},
+}
+
+// DefaultColumns is the column set used when none is selected.
+var DefaultColumns = []string{"label", "priority", "task", "status", "due"}
+
+// ColumnNames lists every selectable column.
+func ColumnNames() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// LookupColumns resolves column names, rejecting unknown ones.
+func LookupColumns(names []string) ([]Column, error) {
+	var cols []Column
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, c := range columns {
+			if c.Name == name {
+				cols = append(cols, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, i18n.Errorf("unknown column %q (known columns: %s)", name, strings.Join(ColumnNames(), ", "))
+		}
+	}
+	if len(cols) == 0 {
+		return nil, i18n.Errorf("no columns selected")
+	}
+	return cols, nil
+}
+
+// Table writes items as an aligned table. Padding is computed on the plain
+// text and styles are added afterwards, so colors never skew the layout.
+func Table(w io.Writer, items []todo.Item, o Options) error {
+	cols, err := LookupColumns(o.Columns)
+	if err != nil {
+		return err
+	}
+
+	cells := make([][]string, len(items))
+	widths := make([]int, len(cols))
+	task := -1
+	headers := make([]string, len(cols))
+	for c, col := range cols {
+		headers[c] = o.Locale.T(col.Header)
+		widths[c] = utf8.RuneCountInString(headers[c])
+		if col.Name == "task" {
+			task = c
+		}
+	}
+	for r, item := range items {
+		cells[r] = make([]string, len(cols))
+		for c, col := range cols {
+			cells[r][c] = col.cell(item, o)
+			widths[c] = max(widths[c], utf8.RuneCountInString(cells[r][c]))
+		}
+	}
+
+	if o.Width > 0 && task >= 0 {
+		total := gap * (len(cols) - 1)
+		for _, n := range widths {
+			total += n
+		}
+		if over := total - o.Width; over > 0 {
+			widths[task] = max(minTaskWidth, widths[task]-over)
+		}
+	}
+
+	header := make([][]string, len(cols))
+	rule := make([][]string, len(cols))
+	styles := make([][]string, len(cols))
+	for c := range cols {
+		header[c] = []string{headers[c]}
+		rule[c] = []string{strings.Repeat("-", utf8.RuneCountInString(headers[c]))}
+		styles[c] = []string{o.Theme.Header}
+	}
+	writeRow(w, header, styles, widths, o.Color)
+	writeRow(w, rule, styles, widths, false)
+
+	for r, item := range items {
+		lines := make([][]string, len(cols))
+		for c, col := range cols {
+			lines[c] = fit(cells[r][c], widths[c], o.Wrap && c == task)
+			styles[c] = repeat(cellStyle(col.Name, item, o), len(lines[c]))
+		}
+		writeRow(w, lines, styles, widths, o.Color)
+	}
+	return nil
+}
+
+// cellStyle picks the style for one cell of item.
+func cellStyle(column string, item todo.Item, o Options) string {
+	switch {
+	case item.Done:
+		return o.Theme.Done
+	case item.Overdue(o.Now) && (column == "task" || column == "due"):
+		return o.Theme.Overdue
+	case column == "priority":
+		return o.Theme.priority(item.Priority)
+	}
+	return ""
+}
+
+// writeRow prints side-by-side cells that may span several lines; each
+// line of cells[c] is drawn with the matching entry of styles[c].
+func writeRow(w io.Writer, cells, styles [][]string, widths []int, color bool) {
+	height := 0
+	for _, lines := range cells {
+		height = max(height, len(lines))
+	}
+	for l := 0; l < height; l++ {
+		var b strings.Builder
+		pad := 0
+		for c, lines := range cells {
+			if l < len(lines) && lines[l] != "" {
+				b.WriteString(strings.Repeat(" ", pad))
+				pad = 0
+				if color && styles[c][l] != "" {
+					b.WriteString(styles[c][l] + lines[l] + "\033[0m")
+				} else {
+					b.WriteString(lines[l])
+				}
+				pad -= utf8.RuneCountInString(lines[l])
+			}
+			pad += widths[c] + gap
+		}
+		fmt.Fprintln(w, b.String())
+	}
+}
+
+func repeat(s string, n int) []string {
+	r := make([]string, n)
+	for i := range r {
+		r[i] = s
+	}
+	return r
+}
+
+// fit makes text at most width runes wide, either by wrapping it onto
+// several lines or by truncating it with an ellipsis.
+func fit(text string, width int, wrap bool) []string {
+	if utf8.RuneCountInString(text) <= width {
+		return []string{text}
+	}
+	if !wrap {
+		return []string{string([]rune(text)[:width-1]) + "…"}
+	}
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			r := []rune(word)
+			lines = append(lines, string(r[:width]))
+			word = string(r[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,41 @@
+package render
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether w is a terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Width returns the width of the terminal w writes to, or 0 when w is not
+// a terminal and output should not be fitted.
+func Width(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// UseColor decides whether to emit colors for the color setting mode
+// ("auto", "always" or "never"). In auto mode colors are only used on a
+// terminal and when NO_COLOR is not set.
+func UseColor(mode string, w io.Writer) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return os.Getenv("NO_COLOR") == "" && IsTerminal(w)
+}
@@ -0,0 +1,76 @@
+package render
+
+// Theme holds the ANSI styles used to color a task table.
+type Theme struct {
+	Name    string
+	Header  string
+	High    string
+	Medium  string
+	Low     string
+	Done    string
+	Overdue string
+}
+
+// DefaultTheme is used when no theme is configured.
+const DefaultTheme = "default"
+
+var themes = []Theme{
+	{
+		Name:    DefaultTheme,
+		Header:  "\033[1m",
+		High:    "\033[1;31m",
+		Medium:  "\033[33m",
+		Low:     "\033[32m",
+		Done:    "\033[2;9m",
+		Overdue: "\033[1;4;91m",
+	},
+	{
+		// 256-color palette matching the chatbot's look.
+		Name:    "pastel",
+		Header:  "\033[1;38;5;141m",
+		High:    "\033[38;5;203m",
+		Medium:  "\033[38;5;215m",
+		Low:     "\033[38;5;150m",
+		Done:    "\033[2;38;5;243m",
+		Overdue: "\033[1;38;5;197m",
+	},
+	{
+		// No hues at all, only weight and decoration.
+		Name:    "mono",
+		Header:  "\033[1m",
+		High:    "\033[1m",
+		Low:     "\033[2m",
+		Done:    "\033[2;9m",
+		Overdue: "\033[4m",
+	},
+}
+
+// LookupTheme returns the built-in theme called name.
+func LookupTheme(name string) (Theme, bool) {
+	for _, t := range themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// ThemeNames lists the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// priority returns the style for a priority level.
+func (t Theme) priority(level int) string {
+	switch level {
+	case 1:
+		return t.High
+	case 3:
+		return t.Low
+	}
+	return t.Medium
+}
@@ -0,0 +1,135 @@
+// Package scan finds TODO and FIXME comments in Go source trees.
+package scan
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Comment is a TODO or FIXME found in a source file.
+type Comment struct {
+	File  string // absolute path
+	Line  int
+	Kind  string // "TODO" or "FIXME"
+	Owner string // from TODO(owner), may be empty
+	Text  string
+}
+
+// Location is the file:line the comment was found at.
+func (c Comment) Location() string {
+	return c.File + ":" + strconv.Itoa(c.Line)
+}
+
+// marker matches "TODO(owner): text", "FIXME: text", "TODO:text" and
+// "TODO text", but not words such as "TODOs".
+var marker = regexp.MustCompile(`^(TODO|FIXME)\b(?:\(([^)]*)\))?:?\s*(.+)$`)
+
+// skipDirs are never descended into.
+var skipDirs = map[string]bool{"vendor": true, "testdata": true, "node_modules": true}
+
+// Result is what Dir found below a root.
+type Result struct {
+	Comments []Comment // in file order
+	Files    int       // files scanned
+	// Failed are the files that did not parse, and Skipped the directories
+	// that were not descended into, all absolute. Whether their comments
+	// are still there is unknown.
+	Failed  []Failure
+	Skipped []string
+}
+
+// Failure is a Go file that did not parse.
+type Failure struct {
+	File string
+	Err  error
+}
+
+// Scanned reports whether the comments of file, an absolute path below
+// the root, are known: it was neither a file that failed to parse nor in
+// a skipped directory.
+func (r Result) Scanned(file string) bool {
+	for _, f := range r.Failed {
+		if f.File == file {
+			return false
+		}
+	}
+	for _, dir := range r.Skipped {
+		if strings.HasPrefix(file, dir+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Dir walks the Go files below root and returns their TODO and FIXME line
+// comments. Files that do not parse and skipped directories are listed in
+// the result, so their comments are not taken to be gone.
+func Dir(root string) (Result, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var r Result
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (skipDirs[name] || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				r.Skipped = append(r.Skipped, path)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		found, err := File(path)
+		if err != nil {
+			r.Failed = append(r.Failed, Failure{File: path, Err: err})
+			return nil
+		}
+		r.Files++
+		r.Comments = append(r.Comments, found...)
+		return nil
+	})
+	return r, err
+}
+
+// File returns the TODO and FIXME line comments of one Go file. Only real
+// comments count, so markers inside string literals are ignored.
+func File(path string) ([]Comment, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, "//") {
+				continue
+			}
+			m := marker.FindStringSubmatch(strings.TrimSpace(strings.TrimPrefix(c.Text, "//")))
+			if m == nil {
+				continue
+			}
+			comments = append(comments, Comment{
+				File:  path,
+				Line:  fset.Position(c.Pos()).Line,
+				Kind:  m[1],
+				Owner: strings.TrimSpace(m[2]),
+				Text:  strings.TrimSpace(m[3]),
+			})
+		}
+	}
+	return comments, nil
+}
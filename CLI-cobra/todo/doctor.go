@@ -0,0 +1,199 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/jubel075/cli-cobra/i18n"
+)
+
+// Report is the outcome of checking a data file.
+type Report struct {
+	Filename string
+	Exists   bool
+	Version  int // format version found, 0 if it could not be told
+	Items    []Item
+	Problems []Problem
+	// Salvaged is set when the file could not be decoded and Items were
+	// recovered from the readable part of it.
+	Salvaged bool
+	// Written is set when Repair saved the repaired file.
+	Written bool
+}
+
+// Problem is something wrong with a data file. Item is the position of the
+// task concerned, or 0 for the file as a whole.
+type Problem struct {
+	Item    int
+	Message i18n.Message
+	Fixable bool
+}
+
+// Fixable reports whether Repair can fix every problem found.
+func (r *Report) Fixable() bool {
+	for _, p := range r.Problems {
+		if !p.Fixable {
+			return false
+		}
+	}
+	return true
+}
+
+// problem records a problem, described by format and args.
+func (r *Report) problem(item int, fixable bool, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{Item: item, Message: i18n.NewMessage(format, args...), Fixable: fixable})
+}
+
+// Check inspects filename against the workflow without changing it.
+func Check(filename string, wf Workflow) (*Report, error) {
+	return inspect(filename, wf)
+}
+
+// Repair fixes the problems Check finds and writes the result, after
+// copying the original to filename.doctor.bak. Nothing is written when a
+// problem cannot be fixed.
+func Repair(filename string, wf Workflow) (*Report, error) {
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	r, err := inspect(filename, wf)
+	if err != nil || !r.Exists || len(r.Problems) == 0 || !r.Fixable() {
+		return r, err
+	}
+
+	original, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filename+".doctor.bak", original, 0644); err != nil {
+		return nil, i18n.Errorf("backing up %s before repairing it: %w", filename, err)
+	}
+	data, err := encode(r.Items)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(filename, data); err != nil {
+		return nil, err
+	}
+	r.Written = true
+	remember(filename, r.Items)
+	return r, nil
+}
+
+// inspect reads filename and collects its problems. r.Items holds the
+// items as they would be after repair.
+func inspect(filename string, wf Workflow) (*Report, error) {
+	r := &Report{Filename: filename}
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Exists = true
+
+	items, version, err := decode(data)
+	r.Version = version
+	switch {
+	case err != nil && version > CurrentVersion:
+		r.Problems = append(r.Problems, Problem{Message: i18n.MessageOf(err)})
+		return r, nil
+	case err != nil:
+		items = salvage(data)
+		r.Salvaged = true
+		r.problem(0, len(items) > 0, "cannot be decoded (%v); %d tasks can be recovered from it", err, len(items))
+	case version < CurrentVersion:
+		r.problem(0, true, "stored in format version %d, upgrading to %d", version, CurrentVersion)
+	}
+
+	seen := map[string]bool{}
+	for i := range items {
+		item := &items[i]
+		item.position = i + 1
+		pos := item.position
+
+		switch {
+		case item.ID == "" && version >= 2:
+			item.ID = legacyID(*item)
+			r.problem(pos, true, "missing ID")
+		case item.ID == "":
+			item.ID = legacyID(*item)
+		case seen[item.ID]:
+			item.ID = newID()
+			r.problem(pos, true, "duplicate ID, assigning a new one")
+		}
+		seen[item.ID] = true
+
+		if item.Text == "" {
+			r.problem(pos, true, "empty task text, removing the task")
+		}
+		if item.Priority < 1 || item.Priority > 3 {
+			r.problem(pos, true, "invalid priority %d, resetting to medium", item.Priority)
+			item.SetPtiority(item.Priority)
+		}
+		if item.State != "" && !wf.Has(item.State) {
+			r.problem(pos, true, "unknown state %q, mapping to %q", item.State, item.StateIn(wf))
+			item.State = item.StateIn(wf)
+		}
+		if item.State != "" && item.Done != (item.State == wf.Final()) {
+			r.problem(pos, true, "done flag disagrees with state %q", item.State)
+			item.Done = item.State == wf.Final()
+		}
+	}
+
+	r.Items = items[:0]
+	for _, item := range items {
+		if item.Text != "" {
+			r.Items = append(r.Items, item)
+		}
+	}
+	return r, nil
+}
+
+// salvage recovers the items that decode cleanly from the start of a
+// damaged file, in either format, stopping at the first broken one.
+func salvage(data []byte) []Item {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil
+	}
+	if tok == json.Delim('{') {
+		for {
+			key, err := dec.Token()
+			if err != nil || key == json.Delim('}') {
+				return nil
+			}
+			if key == "Items" {
+				break
+			}
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil
+			}
+		}
+		if tok, err = dec.Token(); err != nil {
+			return nil
+		}
+	}
+	if tok != json.Delim('[') {
+		return nil
+	}
+
+	var items []Item
+	for dec.More() {
+		var item Item
+		if err := dec.Decode(&item); err != nil {
+			break
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,154 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jubel075/cli-cobra/i18n"
+)
+
+// How long SaveItems waits for another writer, and when a lock left
+// behind by a crashed process is considered abandoned.
+const (
+	lockWait  = 5 * time.Second
+	lockStale = 30 * time.Second
+)
+
+// ConflictError reports items that were changed both by us and by someone
+// else since the file was read. Nothing is written when it is returned.
+type ConflictError struct {
+	Filename string
+	Items    []string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message().String()
+}
+
+// Message lets the error be shown in the user's language.
+func (e *ConflictError) Message() i18n.Message {
+	return i18n.NewMessage("%s was changed by someone else and these tasks conflict: %s; re-run the command to apply it on top of their changes",
+		e.Filename, strings.Join(e.Items, ", "))
+}
+
+// merge combines our items with theirs, both derived from base. Items are
+// matched by ID; a side that left an item untouched takes the other
+// side's version, including deletions. The result keeps their order with
+// our new items appended.
+func merge(base, ours, theirs []Item) ([]Item, error) {
+	baseByID := index(base)
+	oursByID := index(ours)
+	theirsByID := index(theirs)
+
+	var merged []Item
+	var conflicts []string
+	for _, t := range theirs {
+		b, inBase := baseByID[t.ID]
+		o, inOurs := oursByID[t.ID]
+		switch {
+		case !inBase:
+			// Added by them.
+			merged = append(merged, t)
+		case !inOurs:
+			// Deleted by us; keep it if they changed it meanwhile.
+			if !sameItem(b, t) {
+				conflicts = append(conflicts, quote(t))
+				continue
+			}
+		case sameItem(o, b):
+			merged = append(merged, t)
+		case sameItem(t, b) || sameItem(o, t):
+			merged = append(merged, o)
+		default:
+			conflicts = append(conflicts, quote(o))
+		}
+	}
+	for _, o := range ours {
+		if _, inTheirs := theirsByID[o.ID]; inTheirs {
+			continue
+		}
+		b, inBase := baseByID[o.ID]
+		switch {
+		case !inBase:
+			// Added by us.
+			merged = append(merged, o)
+		case !sameItem(o, b):
+			// Changed by us but deleted by them.
+			conflicts = append(conflicts, quote(o))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, &ConflictError{Items: conflicts}
+	}
+	for i := range merged {
+		merged[i].position = i + 1
+	}
+	return merged, nil
+}
+
+func index(items []Item) map[string]Item {
+	m := make(map[string]Item, len(items))
+	for _, i := range items {
+		m[i.ID] = i
+	}
+	return m
+}
+
+func quote(i Item) string {
+	return fmt.Sprintf("%q", i.Text)
+}
+
+// sameItem compares the stored form of two items, ignoring their position.
+func sameItem(a, b Item) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+func sameItems(a, b []Item) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !sameItem(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// lockFile takes an exclusive lock on filename by creating filename.lock,
+// which works on network mounts where flock does not. The returned
+// function releases it.
+func lockFile(filename string) (func(), error) {
+	path := filename + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%s %d\n", CurrentUser(), os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			owner, _ := os.ReadFile(path)
+			return nil, i18n.Errorf("%s is locked by %s; remove %s if nobody is writing to it",
+				filename, strings.TrimSpace(string(owner)), path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
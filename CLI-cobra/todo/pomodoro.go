@@ -0,0 +1,26 @@
+package todo
+
+import "time"
+
+// Pomodoro is a focus session finished on an item.
+type Pomodoro struct {
+	Finished time.Time
+	Length   time.Duration
+}
+
+// LogPomodoro records a focus session of length that finished at end. The
+// time itself is tracked by the item's timer, which runs during a session.
+func (i *Item) LogPomodoro(end time.Time, length time.Duration) {
+	i.Pomodoros = append(i.Pomodoros, Pomodoro{Finished: end, Length: length})
+}
+
+// PomodorosBetween returns the item's pomodoros finished in [from, to).
+func (i Item) PomodorosBetween(from, to time.Time) []Pomodoro {
+	var ps []Pomodoro
+	for _, p := range i.Pomodoros {
+		if !p.Finished.Before(from) && p.Finished.Before(to) {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
@@ -0,0 +1,160 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jubel075/cli-cobra/i18n"
+)
+
+// CurrentVersion is the data file format written by SaveItems.
+//
+//	1: a bare JSON array of items
+//	2: {"Version": 2, "Items": [...]}
+const CurrentVersion = 2
+
+// envelope is the on-disk shape of a data file from version 2 on.
+type envelope struct {
+	Version int
+	Items   []Item
+}
+
+// Migration upgrades the raw contents of a data file from version From to
+// From+1.
+type Migration struct {
+	From        int
+	Description string
+	Upgrade     func(data []byte) ([]byte, error)
+}
+
+// migrations is the registry of upgrades, applied in order of From.
+var migrations = map[int]Migration{}
+
+func registerMigration(m Migration) {
+	migrations[m.From] = m
+}
+
+func init() {
+	registerMigration(Migration{
+		From:        1,
+		Description: "wrap the bare item array in a versioned envelope",
+		Upgrade: func(data []byte) ([]byte, error) {
+			var items []json.RawMessage
+			if err := json.Unmarshal(data, &items); err != nil {
+				return nil, err
+			}
+			return json.Marshal(struct {
+				Version int
+				Items   []json.RawMessage
+			}{2, items})
+		},
+	})
+}
+
+// CorruptError reports a data file that cannot be decoded. The file is
+// left untouched.
+type CorruptError struct {
+	Filename string
+	Err      error
+}
+
+func (e *CorruptError) Error() string {
+	return e.Message().String()
+}
+
+// Message lets the error be shown in the user's language.
+func (e *CorruptError) Message() i18n.Message {
+	return i18n.NewMessage("%s cannot be read: %v", e.Filename, e.Err)
+}
+
+func (e *CorruptError) Unwrap() error { return e.Err }
+
+// fileVersion tells which format data is stored in.
+func fileVersion(data []byte) (int, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return 0, i18n.Errorf("file is empty")
+	}
+	switch data[0] {
+	case '[':
+		return 1, nil
+	case '{':
+		var v struct{ Version int }
+		if err := json.Unmarshal(data, &v); err != nil {
+			return 0, err
+		}
+		if v.Version < 2 {
+			return 0, i18n.Errorf("invalid version %d", v.Version)
+		}
+		return v.Version, nil
+	}
+	return 0, i18n.Errorf("not a JSON task list")
+}
+
+// decode runs the migrations needed to bring data up to CurrentVersion and
+// returns its items along with the version it was stored in.
+func decode(data []byte) ([]Item, int, error) {
+	version, err := fileVersion(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version > CurrentVersion {
+		return nil, version, i18n.Errorf("written by a newer cli-cobra (format version %d, this one reads up to %d)", version, CurrentVersion)
+	}
+	for v := version; v < CurrentVersion; v++ {
+		m, ok := migrations[v]
+		if !ok {
+			return nil, version, i18n.Errorf("no migration from format version %d", v)
+		}
+		if data, err = m.Upgrade(data); err != nil {
+			return nil, version, i18n.Errorf("migrating from version %d: %w", v, err)
+		}
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, version, err
+	}
+	return env.Items, version, nil
+}
+
+func encode(items []Item) ([]byte, error) {
+	if items == nil {
+		items = []Item{}
+	}
+	return json.Marshal(envelope{Version: CurrentVersion, Items: items})
+}
+
+// upgrade rewrites a data file stored in an older format in the current
+// one, after copying the original to BackupName. The file is re-read under
+// the lock in case someone else upgraded or changed it meanwhile.
+func upgrade(filename string) ([]Item, error) {
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	original, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	items, version, err := parse(filename, original)
+	if err != nil || version == CurrentVersion {
+		return items, err
+	}
+	if err := os.WriteFile(BackupName(filename, version), original, 0644); err != nil {
+		return nil, i18n.Errorf("backing up %s before upgrading it: %w", filename, err)
+	}
+	data, err := encode(items)
+	if err != nil {
+		return nil, err
+	}
+	return items, writeFileAtomic(filename, data)
+}
+
+// BackupName is where the original of a file upgraded from version is kept.
+func BackupName(filename string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", filename, version)
+}
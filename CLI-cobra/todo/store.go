@@ -0,0 +1,42 @@
+package todo
+
+import "sync"
+
+// Store loads and saves a task list.
+type Store interface {
+	Load() ([]Item, error)
+	Save(items []Item) error
+}
+
+// FileStore keeps the task list in a JSON data file, see ReadItems and
+// SaveItems.
+type FileStore struct {
+	Filename string
+}
+
+func (s FileStore) Load() ([]Item, error)   { return ReadItems(s.Filename) }
+func (s FileStore) Save(items []Item) error { return SaveItems(s.Filename, items) }
+
+// MemStore keeps the task list in memory. It is meant for tests, and
+// unlike FileStore it leaves items without an ID as they are.
+type MemStore struct {
+	mu    sync.Mutex
+	Items []Item
+}
+
+func (s *MemStore) Load() ([]Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := append([]Item{}, s.Items...)
+	for i := range items {
+		items[i].position = i + 1
+	}
+	return items, nil
+}
+
+func (s *MemStore) Save(items []Item) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Items = append([]Item(nil), items...)
+	return nil
+}
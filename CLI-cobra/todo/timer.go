@@ -0,0 +1,38 @@
+package todo
+
+import "time"
+
+// Running reports whether the item's timer is running.
+func (i Item) Running() bool {
+	return !i.Started.IsZero()
+}
+
+// StartTimer starts the item's timer at now. It reports false when the
+// timer was already running.
+func (i *Item) StartTimer(now time.Time) bool {
+	if i.Running() {
+		return false
+	}
+	i.Started = now
+	return true
+}
+
+// StopTimer stops the item's timer and adds the time it ran to Tracked,
+// which it returns. Stopping a timer that is not running does nothing.
+func (i *Item) StopTimer(now time.Time) time.Duration {
+	if !i.Running() {
+		return 0
+	}
+	ran := now.Sub(i.Started)
+	i.Tracked += ran
+	i.Started = time.Time{}
+	return ran
+}
+
+// Elapsed is the time tracked on the item, including a running timer.
+func (i Item) Elapsed(now time.Time) time.Duration {
+	if i.Running() {
+		return i.Tracked + now.Sub(i.Started)
+	}
+	return i.Tracked
+}
@@ -1,19 +1,53 @@
 package todo
 
 import (
-	"encoding/json"
-	"fmt"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"math"
 	"os"
+	"os/user"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Item struct {
+	// ID identifies the item across concurrent edits of a shared file.
+	ID       string `json:",omitempty"`
 	Text     string
 	Priority int
 	position int
 	Done     bool
+	State    string    `json:",omitempty"`
+	Due      time.Time `json:",omitzero"`
+	// Wait hides the item from list until that day; zero means not snoozed.
+	Wait      time.Time `json:",omitzero"`
+	Assignee  string    `json:",omitempty"`
+	CreatedBy string    `json:",omitempty"`
+	// Source links the item to a file:line, e.g. a TODO comment.
+	Source string `json:",omitempty"`
+	// Updated is when a command last changed the item.
+	Updated time.Time `json:",omitzero"`
+	// Started is when the running timer was started, zero when none runs;
+	// Tracked is the time logged by timers that were stopped.
+	Started time.Time     `json:",omitzero"`
+	Tracked time.Duration `json:",omitempty"`
+	// Pomodoros logs the focus sessions finished on the item.
+	Pomodoros []Pomodoro `json:",omitempty"`
 }
 
+// bases remembers the items last read from or written to each file, so
+// SaveItems can tell our changes apart from those made by someone else.
+var (
+	basesMu sync.Mutex
+	bases   = map[string][]Item{}
+)
+
 type ByPriority []Item
 
 func (s ByPriority) Len() int      { return len(s) }
@@ -28,29 +62,153 @@ func (s ByPriority) Less(i, j int) bool {
 	return s[i].Priority < s[j].Priority
 }
 
+// SaveItems writes items to filename. If the file was changed by someone
+// else since it was read, their changes are merged with ours; a
+// *ConflictError is returned when both sides changed the same item.
+//
+// items itself, not the merged list, becomes the base of the next save,
+// so saving the same slice again keeps what the other writer added.
 func SaveItems(filename string, items []Item) error {
-	fmt.Println("Writing to file:", filename) // <- debug print
-	data, err := json.Marshal(items)
+	for i := range items {
+		if items[i].ID == "" {
+			items[i].ID = newID()
+		}
+	}
+
+	unlock, err := lockFile(filename)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, data, 0644)
+	defer unlock()
+
+	current, _, err := readFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	basesMu.Lock()
+	base := bases[filename]
+	basesMu.Unlock()
+	merged := items
+	if err == nil && !sameItems(base, current) {
+		if merged, err = merge(base, items, current); err != nil {
+			if c, ok := err.(*ConflictError); ok {
+				c.Filename = filename
+			}
+			return err
+		}
+	}
+
+	data, err := encode(merged)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filename, data); err != nil {
+		return err
+	}
+	remember(filename, items)
+	return nil
 }
 
+// ReadItems loads the items stored in filename. A missing file is an empty
+// list; a file that cannot be decoded yields a *CorruptError. Files in an
+// older format are upgraded in place, keeping a backup of the original.
 func ReadItems(filename string) ([]Item, error) {
-	// Implementation to read items from a file
-	data, err := os.ReadFile(filename)
+	items, version, err := readFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Item{}, nil
+	}
 	if err != nil {
 		return []Item{}, err
 	}
-	var items []Item
-	if err := json.Unmarshal(data, &items); err != nil {
-		return []Item{}, err
+	if version < CurrentVersion {
+		if items, err = upgrade(filename); err != nil {
+			return []Item{}, err
+		}
+	}
+	remember(filename, items)
+	return items, nil
+}
+
+// readFile decodes filename and also returns the format version it is
+// stored in.
+func readFile(filename string) ([]Item, int, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parse(filename, data)
+}
+
+// parse decodes the contents of filename and numbers its items.
+func parse(filename string, data []byte) ([]Item, int, error) {
+	items, version, err := decode(data)
+	if err != nil {
+		return nil, version, &CorruptError{Filename: filename, Err: err}
 	}
 	for i := range items {
 		items[i].position = i + 1
+		if items[i].ID == "" {
+			items[i].ID = legacyID(items[i])
+		}
 	}
-	return items, nil
+	return items, version, nil
+}
+
+// remember records items as the merge base for filename.
+func remember(filename string, items []Item) {
+	basesMu.Lock()
+	defer basesMu.Unlock()
+	bases[filename] = append([]Item(nil), items...)
+}
+
+// writeFileAtomic replaces filename in one step, so readers on a shared
+// mount never see a half-written file.
+func writeFileAtomic(filename string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// legacyID derives an ID for items saved before IDs existed. It is
+// deterministic so every user reading the same old file agrees on it.
+func legacyID(i Item) string {
+	sum := sha1.Sum([]byte(strconv.Itoa(i.position) + "\x00" + i.Text))
+	return hex.EncodeToString(sum[:6])
+}
+
+// CurrentUser returns the login name of the OS user running the program.
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		// Windows reports DOMAIN\user.
+		name := u.Username
+		if i := strings.LastIndex(name, `\`); i >= 0 {
+			name = name[i+1:]
+		}
+		return name
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
 }
 
 func (i *Item) SetPtiority(pri int) {
@@ -66,21 +224,76 @@ func (i *Item) SetPtiority(pri int) {
 	}
 }
 
+// Priority schemes understood by PriorityIn.
+const (
+	SchemeWords   = "words"
+	SchemeNumbers = "numbers"
+	SchemeLetters = "letters"
+)
+
 // Prettie priority print
 func (i Item) PrettyP() string {
-	if i.Priority == 1 {
-		return "High"
+	return i.PriorityIn(SchemeWords)
+}
+
+// PriorityIn renders the priority using the given scheme: "words"
+// (High/Medium/Low), "numbers" (1/2/3) or "letters" (A/B/C).
+// Unknown schemes fall back to words.
+func (i Item) PriorityIn(scheme string) string {
+	level := i.Priority
+	if level < 1 || level > 3 {
+		level = 2
 	}
-	if i.Priority == 3 {
-		return "Low"
+	switch scheme {
+	case SchemeNumbers:
+		return strconv.Itoa(level)
+	case SchemeLetters:
+		return string(rune('A' + level - 1))
 	}
-	return "Medium"
+	return [...]string{"High", "Medium", "Low"}[level-1]
 }
 
 func (i Item) Label() string {
 	return strconv.Itoa(i.position) + ". "
 }
 
+// Overdue reports whether an unfinished item was due before the day of now.
+func (i Item) Overdue(now time.Time) bool {
+	if i.Done || i.Due.IsZero() {
+		return false
+	}
+	return i.Due.Before(startOfDay(now))
+}
+
+// Waiting reports whether an unfinished item is snoozed until after the day
+// of now.
+func (i Item) Waiting(now time.Time) bool {
+	if i.Done || i.Wait.IsZero() {
+		return false
+	}
+	return i.Wait.After(startOfDay(now))
+}
+
+// DueToday reports whether an unfinished item is due on the day of now.
+func (i Item) DueToday(now time.Time) bool {
+	return !i.Done && !i.Due.IsZero() && startOfDay(i.Due).Equal(startOfDay(now))
+}
+
+// DaysUntilDue counts the days from the day of now to the due date, which
+// is negative when the item is overdue. Items without a due date return 0.
+func (i Item) DaysUntilDue(now time.Time) int {
+	if i.Due.IsZero() {
+		return 0
+	}
+	return int(math.Round(startOfDay(i.Due).Sub(startOfDay(now)).Hours() / 24))
+}
+
+// startOfDay returns midnight at the start of the day of t.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
 func (i *Item) PrettyDone() string {
 	if i.Done {
 		return "[x] "
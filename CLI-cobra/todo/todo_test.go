@@ -0,0 +1,67 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// appendElsewhere adds an item to filename as another process would,
+// without touching the merge base this process keeps.
+func appendElsewhere(t *testing.T, filename, text string) {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, _, err := decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items = append(items, Item{ID: newID(), Text: text, Priority: 2})
+	if data, err = encode(items); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(filename, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func texts(items []Item) []string {
+	var out []string
+	for _, i := range items {
+		out = append(out, i.Text)
+	}
+	return out
+}
+
+func TestSaveKeepsConcurrentAppend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "todos.json")
+	if err := SaveItems(filename, []Item{{Text: "a", Priority: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := ReadItems(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	appendElsewhere(t, filename, "b")
+
+	// Both saves use the slice read before "b" was added.
+	items[0].Priority = 1
+	if err := SaveItems(filename, items); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	items[0].Done = true
+	if err := SaveItems(filename, items); err != nil {
+		t.Fatalf("second save: %v", err)
+	}
+
+	got, err := ReadItems(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Text != "a" || !got[0].Done || got[0].Priority != 1 || got[1].Text != "b" {
+		t.Errorf("items = %q (%+v), want a, done at priority 1, then b", texts(got), got)
+	}
+}
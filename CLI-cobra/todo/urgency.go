@@ -0,0 +1,39 @@
+package todo
+
+import "time"
+
+// Urgency scores how pressing an unfinished item is, higher first. Its
+// priority, a near or passed due date, being in progress and a running
+// timer all add to it; a snoozed item is pushed down. Done items score 0.
+func (i Item) Urgency(w Workflow, now time.Time) float64 {
+	if i.Done {
+		return 0
+	}
+	level := i.Priority
+	if level < 1 || level > 3 {
+		level = 2
+	}
+	u := [...]float64{6, 4, 2}[level-1]
+
+	if !i.Due.IsZero() {
+		switch days := i.DaysUntilDue(now); {
+		case days < 0:
+			// Overdue, more so the longer ago, up to a week.
+			u += 12 + float64(min(-days, 7))
+		case days == 0:
+			u += 10
+		case days < 14:
+			u += 8 * float64(14-days) / 14
+		}
+	}
+	if s := i.StateIn(w); s != w.Initial() && s != w.Final() {
+		u += 3
+	}
+	if i.Running() {
+		u += 4
+	}
+	if i.Waiting(now) {
+		u -= 10
+	}
+	return u
+}
@@ -0,0 +1,116 @@
+package todo
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jubel075/cli-cobra/i18n"
+)
+
+// DefaultStates is the workflow used when none is configured.
+const DefaultStates = "backlog,todo,doing,review,done"
+
+// Workflow is the ordered list of states a task moves through. New tasks
+// start in the first state and the last state means done.
+type Workflow struct {
+	States []string
+	// WIP caps the number of tasks per state; missing states are unlimited.
+	WIP map[string]int
+}
+
+// ParseWorkflow builds a workflow from a comma-separated state list such
+// as "todo,doing,done" and WIP limits such as "doing=3,review=2".
+func ParseWorkflow(states, wip string) (Workflow, error) {
+	w := Workflow{WIP: map[string]int{}}
+	for _, s := range strings.Split(states, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" || strings.ContainsAny(s, " \t=") {
+			return Workflow{}, i18n.Errorf("invalid state name %q", s)
+		}
+		if slices.Contains(w.States, s) {
+			return Workflow{}, i18n.Errorf("state %q is listed twice", s)
+		}
+		w.States = append(w.States, s)
+	}
+	if len(w.States) < 2 {
+		return Workflow{}, i18n.Errorf("a workflow needs at least two states")
+	}
+
+	for _, l := range strings.Split(wip, ",") {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		state, n, ok := strings.Cut(l, "=")
+		state = strings.ToLower(strings.TrimSpace(state))
+		limit, err := strconv.Atoi(strings.TrimSpace(n))
+		if !ok || err != nil || limit < 1 {
+			return Workflow{}, i18n.Errorf("invalid WIP limit %q, want state=number", l)
+		}
+		if !w.Has(state) {
+			return Workflow{}, i18n.Errorf("WIP limit for unknown state %q", state)
+		}
+		w.WIP[state] = limit
+	}
+	return w, nil
+}
+
+// Has reports whether state is part of the workflow.
+func (w Workflow) Has(state string) bool {
+	return slices.Contains(w.States, state)
+}
+
+// Initial is the state new tasks start in.
+func (w Workflow) Initial() string {
+	return w.States[0]
+}
+
+// Final is the state that counts as done.
+func (w Workflow) Final() string {
+	return w.States[len(w.States)-1]
+}
+
+// Count returns how many items are in state.
+func (w Workflow) Count(items []Item, state string) int {
+	n := 0
+	for _, i := range items {
+		if i.StateIn(w) == state {
+			n++
+		}
+	}
+	return n
+}
+
+// OverLimit lists the states holding more items than their WIP limit.
+func (w Workflow) OverLimit(items []Item) []string {
+	var over []string
+	for _, s := range w.States {
+		if limit, ok := w.WIP[s]; ok && w.Count(items, s) > limit {
+			over = append(over, s)
+		}
+	}
+	return over
+}
+
+// StateIn returns the item's state in w. Items saved before workflow
+// states existed, or whose state is no longer configured, map their Done
+// flag to the final or initial state.
+func (i Item) StateIn(w Workflow) string {
+	if w.Has(i.State) {
+		return i.State
+	}
+	if i.Done {
+		return w.Final()
+	}
+	return w.Initial()
+}
+
+// SetState moves the item to state and keeps Done in sync with it.
+func (i *Item) SetState(w Workflow, state string) error {
+	if !w.Has(state) {
+		return i18n.Errorf("unknown state %q (states: %s)", state, strings.Join(w.States, ", "))
+	}
+	i.State = state
+	i.Done = state == w.Final()
+	return nil
+}
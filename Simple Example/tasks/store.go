@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// FileName is the file the tasks are kept in, in the app's storage root.
+const FileName = "tasks.json"
+
+// FileStore keeps the tasks as JSON in a file.
+type FileStore struct {
+	URI fyne.URI
+}
+
+// NewFileStore returns the store for the tasks of app, a file in its
+// storage root. The app needs a unique ID for that root to be its own.
+func NewFileStore(app fyne.App) (*FileStore, error) {
+	uri, err := storage.Child(app.Storage().RootURI(), FileName)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{URI: uri}, nil
+}
+
+// Load reads the tasks. A file that does not exist yet holds no tasks.
+func (s *FileStore) Load() ([]Task, error) {
+	exists, err := storage.Exists(s.URI)
+	if err != nil || !exists {
+		return nil, err
+	}
+	r, err := storage.Reader(s.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var tasks []Task
+	if err := json.NewDecoder(r).Decode(&tasks); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.URI, err)
+	}
+	return tasks, nil
+}
+
+// Save writes the tasks, replacing the file.
+func (s *FileStore) Save(tasks []Task) error {
+	w, err := storage.Writer(s.URI)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(tasks); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,151 @@
+// Package tasks holds the to-do list shown by the app. It knows nothing
+// about widgets, so it can be tested without opening a window.
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Task is one entry of the to-do list.
+type Task struct {
+	Text string `json:"text"`
+	Done bool   `json:"done,omitempty"`
+}
+
+// ErrNothingToUndo is returned by Undo when no removal is left to undo.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Store keeps the tasks between runs of the app.
+type Store interface {
+	Load() ([]Task, error)
+	Save([]Task) error
+}
+
+// List is the to-do list. Every change is saved to its store right away.
+type List struct {
+	tasks []Task
+	store Store
+	// removed holds the tasks removed since the list was loaded, most
+	// recent last, for Undo.
+	removed []removal
+}
+
+type removal struct {
+	index int
+	task  Task
+}
+
+// New loads the list kept in store.
+func New(store Store) (*List, error) {
+	tasks, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &List{tasks: tasks, store: store}, nil
+}
+
+// Len returns the number of tasks.
+func (l *List) Len() int {
+	return len(l.tasks)
+}
+
+// Get returns the task at index i.
+func (l *List) Get(i int) Task {
+	return l.tasks[i]
+}
+
+// Add appends a task and saves the list. If saving fails the task is not
+// added, so adding it again does not add it twice.
+func (l *List) Add(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("a task needs some text")
+	}
+	l.tasks = append(l.tasks, Task{Text: text})
+	if err := l.save(); err != nil {
+		l.tasks = l.tasks[:len(l.tasks)-1]
+		return err
+	}
+	return nil
+}
+
+// SetText changes the text of the task at index i and saves the list.
+func (l *List) SetText(i int, text string) error {
+	if err := l.check(i); err != nil {
+		return err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return errors.New("a task needs some text")
+	}
+	l.tasks[i].Text = text
+	return l.save()
+}
+
+// SetDone marks the task at index i as done or not and saves the list.
+func (l *List) SetDone(i int, done bool) error {
+	if err := l.check(i); err != nil {
+		return err
+	}
+	l.tasks[i].Done = done
+	return l.save()
+}
+
+// Move moves the task at index from to index to, shifting the tasks in
+// between, and saves the list. A to beyond either end is clamped.
+func (l *List) Move(from, to int) error {
+	if err := l.check(from); err != nil {
+		return err
+	}
+	to = max(0, min(to, len(l.tasks)-1))
+	if from == to {
+		return nil
+	}
+	task := l.tasks[from]
+	l.tasks = append(l.tasks[:from], l.tasks[from+1:]...)
+	l.tasks = append(l.tasks[:to], append([]Task{task}, l.tasks[to:]...)...)
+	return l.save()
+}
+
+// Remove deletes the task at index i and saves the list. The removal can
+// be undone with Undo.
+func (l *List) Remove(i int) error {
+	if err := l.check(i); err != nil {
+		return err
+	}
+	l.removed = append(l.removed, removal{index: i, task: l.tasks[i]})
+	l.tasks = append(l.tasks[:i], l.tasks[i+1:]...)
+	return l.save()
+}
+
+// CanUndo reports whether there is a removal to undo.
+func (l *List) CanUndo() bool {
+	return len(l.removed) > 0
+}
+
+// Undo puts the most recently removed task back where it was, or at the
+// end if the list has become shorter, and saves the list. It returns the
+// index of the restored task.
+func (l *List) Undo() (int, error) {
+	if len(l.removed) == 0 {
+		return 0, ErrNothingToUndo
+	}
+	r := l.removed[len(l.removed)-1]
+	l.removed = l.removed[:len(l.removed)-1]
+	i := min(r.index, len(l.tasks))
+	l.tasks = append(l.tasks[:i], append([]Task{r.task}, l.tasks[i:]...)...)
+	return i, l.save()
+}
+
+func (l *List) check(i int) error {
+	if i < 0 || i >= len(l.tasks) {
+		return fmt.Errorf("no task at index %d", i)
+	}
+	return nil
+}
+
+func (l *List) save() error {
+	return l.store.Save(l.tasks)
+}
@@ -0,0 +1,162 @@
+package tasks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+)
+
+func newStore(t *testing.T) *FileStore {
+	t.Helper()
+	test.NewApp()
+	return &FileStore{URI: storage.NewFileURI(filepath.Join(t.TempDir(), FileName))}
+}
+
+func texts(l *List) []string {
+	var s []string
+	for i := 0; i < l.Len(); i++ {
+		s = append(s, l.Get(i).Text)
+	}
+	return s
+}
+
+func TestPersist(t *testing.T) {
+	store := newStore(t)
+	l, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Len() != 0 {
+		t.Fatalf("new list has %d tasks, want none", l.Len())
+	}
+	for _, text := range []string{"Buy milk", "Call mom", "Walk the dog"} {
+		if err := l.Add(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, want := texts(reloaded), []string{"Buy milk", "Walk the dog"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("reloaded tasks = %q, want %q", got, want)
+	}
+}
+
+// brokenStore fails to save.
+type brokenStore struct{}
+
+func (brokenStore) Load() ([]Task, error) { return nil, nil }
+func (brokenStore) Save([]Task) error     { return errors.New("disk full") }
+
+func TestAdd(t *testing.T) {
+	l, err := New(newStore(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Add("  Buy milk \n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Add(" \t"); err == nil {
+		t.Error("Add with blank text succeeded")
+	}
+	if got := texts(l); !slices.Equal(got, []string{"Buy milk"}) {
+		t.Errorf("tasks = %q", got)
+	}
+
+	broken, err := New(brokenStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := broken.Add("Buy milk"); err == nil || broken.Len() != 0 {
+		t.Errorf("Add to a store that cannot save: %v, %d tasks", err, broken.Len())
+	}
+}
+
+func TestRemoveOutOfRange(t *testing.T) {
+	l, err := New(newStore(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Remove(0); err == nil {
+		t.Error("Remove(0) on an empty list succeeded")
+	}
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	store := newStore(t)
+	if err := os.WriteFile(store.URI.Path(), []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(store); err == nil {
+		t.Error("New with a corrupt file succeeded")
+	}
+}
+
+func TestEdit(t *testing.T) {
+	store := newStore(t)
+	l, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"a", "b", "c", "d"} {
+		if err := l.Add(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	steps := []struct {
+		name string
+		do   func() error
+		want []string
+	}{
+		{"rename", func() error { return l.SetText(1, "  B  ") }, []string{"a", "B", "c", "d"}},
+		{"move down", func() error { return l.Move(0, 2) }, []string{"B", "c", "a", "d"}},
+		{"move up", func() error { return l.Move(3, 0) }, []string{"d", "B", "c", "a"}},
+		{"move past the end", func() error { return l.Move(1, 9) }, []string{"d", "c", "a", "B"}},
+		{"remove", func() error { return l.Remove(1) }, []string{"d", "a", "B"}},
+		{"remove again", func() error { return l.Remove(2) }, []string{"d", "a"}},
+		{"undo", func() error { _, err := l.Undo(); return err }, []string{"d", "a", "B"}},
+		{"undo again", func() error { _, err := l.Undo(); return err }, []string{"d", "c", "a", "B"}},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := texts(l); !slices.Equal(got, step.want) {
+			t.Fatalf("after %s: tasks = %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	if _, err := l.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo with nothing removed: %v, want ErrNothingToUndo", err)
+	}
+	if err := l.SetText(0, " "); err == nil {
+		t.Error("SetText with blank text succeeded")
+	}
+	if err := l.SetDone(2, true); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := texts(reloaded); !slices.Equal(got, []string{"d", "c", "a", "B"}) {
+		t.Errorf("reloaded tasks = %q", got)
+	}
+	for i := 0; i < reloaded.Len(); i++ {
+		if got := reloaded.Get(i).Done; got != (i == 2) {
+			t.Errorf("reloaded task %d done = %v", i, got)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"todo-app/tasks"
+)
+
+// row shows one task of the list: a check box to complete it and its
+// text, struck through once done. Double-tapping the text edits it in
+// place, and dragging the row moves the task.
+type row struct {
+	widget.BaseWidget
+
+	view   *View
+	id     widget.ListItemID
+	check  *widget.Check
+	label  *widget.Label
+	strike *canvas.Line
+	entry  *widget.Entry
+
+	// dragged is how far the row has been dragged vertically.
+	dragged float32
+}
+
+func newRow(v *View) *row {
+	r := &row{view: v, id: -1}
+	r.check = widget.NewCheck("", func(done bool) {
+		if r.id >= 0 {
+			r.view.complete(r.id, done)
+		}
+	})
+	r.label = widget.NewLabel("")
+	r.strike = canvas.NewLine(color.Black)
+	r.strike.StrokeWidth = 1
+	r.entry = widget.NewEntry()
+	r.entry.OnSubmitted = func(text string) {
+		r.stopEditing()
+		r.view.rename(r.id, text)
+	}
+	r.entry.Hide()
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+func (r *row) CreateRenderer() fyne.WidgetRenderer {
+	text := container.New(&strikeLayout{r.label}, r.label, r.strike)
+	return widget.NewSimpleRenderer(container.NewBorder(nil, nil, r.check, nil, container.NewStack(text, r.entry)))
+}
+
+// update shows task as the item id of the list.
+func (r *row) update(id widget.ListItemID, task tasks.Task) {
+	if id != r.id {
+		r.stopEditing()
+	}
+	r.id = id
+	r.check.SetChecked(task.Done)
+	r.label.SetText(task.Text)
+	r.strike.StrokeColor = theme.ForegroundColor()
+	r.strike.Hidden = !task.Done
+	r.label.Importance = widget.MediumImportance
+	if task.Done {
+		r.label.Importance = widget.LowImportance
+	}
+	r.Refresh()
+}
+
+// Tapped selects the task, as tapping a plain list item does.
+func (r *row) Tapped(*fyne.PointEvent) {
+	if r.id >= 0 {
+		r.view.List.Select(r.id)
+	}
+}
+
+// DoubleTapped starts editing the task.
+func (r *row) DoubleTapped(*fyne.PointEvent) {
+	if r.id < 0 {
+		return
+	}
+	r.entry.SetText(r.label.Text)
+	r.label.Hide()
+	r.strike.Hide()
+	r.entry.Show()
+	if c := fyne.CurrentApp().Driver().CanvasForObject(r); c != nil {
+		c.Focus(r.entry)
+	}
+}
+
+// editing reports whether the task is being edited.
+func (r *row) editing() bool {
+	return r.entry.Visible()
+}
+
+func (r *row) stopEditing() {
+	if !r.editing() {
+		return
+	}
+	r.entry.Hide()
+	r.label.Show()
+	if r.id >= 0 && r.id < r.view.todos.Len() {
+		r.strike.Hidden = !r.view.todos.Get(r.id).Done
+	}
+}
+
+// Dragged follows the pointer while the row is dragged.
+func (r *row) Dragged(ev *fyne.DragEvent) {
+	r.dragged += ev.Dragged.DY
+}
+
+// DragEnd moves the task by the number of rows it was dragged over.
+func (r *row) DragEnd() {
+	rows := int(math.Round(float64(r.dragged / (r.Size().Height + theme.SeparatorThicknessSize()))))
+	r.dragged = 0
+	if rows != 0 && r.id >= 0 {
+		r.view.move(r.id, r.id+rows)
+	}
+}
+
+// strikeLayout fills the space with the label and draws the line over
+// the middle of its text.
+type strikeLayout struct {
+	label *widget.Label
+}
+
+func (l *strikeLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	l.label.Move(fyne.NewPos(0, 0))
+	l.label.Resize(size)
+
+	pad := theme.InnerPadding()
+	width := fyne.MeasureText(l.label.Text, theme.TextSize(), l.label.TextStyle).Width
+	width = min(width, size.Width-2*pad)
+	line := objects[1].(*canvas.Line)
+	line.Position1 = fyne.NewPos(pad, size.Height/2)
+	line.Position2 = fyne.NewPos(pad+width, size.Height/2)
+}
+
+func (l *strikeLayout) MinSize([]fyne.CanvasObject) fyne.Size {
+	return l.label.MinSize()
+}
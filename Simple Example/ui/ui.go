@@ -0,0 +1,174 @@
+// Package ui builds the widgets of the to-do app on top of a tasks.List.
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"todo-app/tasks"
+)
+
+// View is the to-do list window content: an entry to add tasks, the task
+// list and the buttons acting on the selected task.
+type View struct {
+	Content fyne.CanvasObject
+
+	Entry        *widget.Entry
+	List         *widget.List
+	AddButton    *widget.Button
+	RemoveButton *widget.Button
+	UndoButton   *widget.Button
+	UpButton     *widget.Button
+	DownButton   *widget.Button
+
+	todos    *tasks.List
+	window   fyne.Window
+	selected widget.ListItemID
+}
+
+// New builds the view of todos. Errors are shown in dialogs on window.
+func New(todos *tasks.List, window fyne.Window) *View {
+	v := &View{todos: todos, window: window, selected: -1}
+
+	v.List = widget.NewList(
+		func() int { return v.todos.Len() },
+		func() fyne.CanvasObject { return newRow(v) },
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*row).update(i, v.todos.Get(i))
+		},
+	)
+	v.List.OnSelected = func(id widget.ListItemID) {
+		v.selected = id
+		v.refreshButtons()
+	}
+	v.List.OnUnselected = func(widget.ListItemID) {
+		v.selected = -1
+		v.refreshButtons()
+	}
+
+	v.Entry = widget.NewEntry()
+	v.Entry.SetPlaceHolder("Enter new task...")
+	v.Entry.OnSubmitted = func(string) { v.Add() }
+
+	v.AddButton = widget.NewButton("Add", v.Add)
+	v.RemoveButton = widget.NewButton("Remove", v.Remove)
+	v.UndoButton = widget.NewButton("Undo", v.Undo)
+	v.UpButton = widget.NewButton("Up", func() { v.MoveSelected(-1) })
+	v.DownButton = widget.NewButton("Down", func() { v.MoveSelected(1) })
+	v.refreshButtons()
+
+	top := container.NewVBox(
+		v.Entry,
+		v.AddButton,
+		container.NewGridWithColumns(4, v.RemoveButton, v.UndoButton, v.UpButton, v.DownButton),
+	)
+	v.Content = container.NewBorder(top, nil, nil, nil, v.List)
+	return v
+}
+
+// AddShortcuts lets the keyboard reorder tasks with Alt+Up and Alt+Down
+// and undo removals with Ctrl+Z (Cmd+Z on macOS).
+func (v *View) AddShortcuts(c fyne.Canvas) {
+	c.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyUp, Modifier: fyne.KeyModifierAlt}, func(fyne.Shortcut) { v.MoveSelected(-1) })
+	c.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyDown, Modifier: fyne.KeyModifierAlt}, func(fyne.Shortcut) { v.MoveSelected(1) })
+	c.AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyZ, Modifier: fyne.KeyModifierShortcutDefault}, func(fyne.Shortcut) { v.Undo() })
+}
+
+// Add appends the text of the entry as a new task. Blank text is
+// ignored, and the entry keeps its text if the task cannot be saved.
+func (v *View) Add() {
+	if strings.TrimSpace(v.Entry.Text) == "" {
+		return
+	}
+	if err := v.todos.Add(v.Entry.Text); err != nil {
+		v.show(err)
+		return
+	}
+	v.Entry.SetText("")
+	v.List.Refresh()
+}
+
+// Remove deletes the selected task.
+func (v *View) Remove() {
+	if v.selected < 0 || v.selected >= v.todos.Len() {
+		return
+	}
+	v.show(v.todos.Remove(v.selected))
+	v.List.UnselectAll()
+	v.List.Refresh()
+	v.refreshButtons()
+}
+
+// Undo puts back the last removed task and selects it.
+func (v *View) Undo() {
+	i, err := v.todos.Undo()
+	if errors.Is(err, tasks.ErrNothingToUndo) {
+		return
+	}
+	v.show(err)
+	v.List.Refresh()
+	v.List.Select(i)
+	v.refreshButtons()
+}
+
+// MoveSelected moves the selected task by delta places, keeping it
+// selected.
+func (v *View) MoveSelected(delta int) {
+	if v.selected >= 0 {
+		v.move(v.selected, v.selected+delta)
+	}
+}
+
+// move moves the task at from to index to and selects it.
+func (v *View) move(from, to int) {
+	to = max(0, min(to, v.todos.Len()-1))
+	if from == to {
+		return
+	}
+	v.show(v.todos.Move(from, to))
+	v.List.Refresh()
+	v.List.Select(to)
+}
+
+// rename changes the text of the task at i.
+func (v *View) rename(i widget.ListItemID, text string) {
+	v.show(v.todos.SetText(i, text))
+	v.List.RefreshItem(i)
+}
+
+// complete marks the task at i as done or not.
+func (v *View) complete(i widget.ListItemID, done bool) {
+	if v.todos.Get(i).Done == done {
+		return
+	}
+	v.show(v.todos.SetDone(i, done))
+	v.List.RefreshItem(i)
+}
+
+func (v *View) refreshButtons() {
+	enable(v.RemoveButton, v.selected >= 0)
+	enable(v.UpButton, v.selected > 0)
+	enable(v.DownButton, v.selected >= 0 && v.selected < v.todos.Len()-1)
+	enable(v.UndoButton, v.todos.CanUndo())
+}
+
+func enable(b *widget.Button, on bool) {
+	if on {
+		b.Enable()
+	} else {
+		b.Disable()
+	}
+}
+
+// show reports err, if any, in a dialog.
+func (v *View) show(err error) {
+	if err != nil {
+		dialog.ShowError(err, v.window)
+	}
+}
@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/test"
+
+	"todo-app/tasks"
+)
+
+// newView opens a view of a list stored in a temporary file, holding
+// the given tasks.
+func newView(t *testing.T, texts ...string) (*View, fyne.Window) {
+	t.Helper()
+	test.NewApp()
+	store := &tasks.FileStore{URI: storage.NewFileURI(filepath.Join(t.TempDir(), tasks.FileName))}
+	todos, err := tasks.New(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range texts {
+		if err := todos.Add(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := test.NewWindow(nil)
+	v := New(todos, w)
+	w.SetContent(v.Content)
+	w.Resize(fyne.NewSize(300, 400))
+	t.Cleanup(w.Close)
+	return v, w
+}
+
+// rowOf returns the row showing task i. The list recycles rows as it is
+// laid out, so the tree is walked as it stands rather than laid out anew.
+func rowOf(t *testing.T, w fyne.Window, i int) *row {
+	t.Helper()
+	var found *row
+	var walk func(o fyne.CanvasObject)
+	walk = func(o fyne.CanvasObject) {
+		if found != nil || !o.Visible() {
+			return
+		}
+		switch o := o.(type) {
+		case *row:
+			if o.id == i {
+				found = o
+			}
+		case *fyne.Container:
+			for _, child := range o.Objects {
+				walk(child)
+			}
+		case fyne.Widget:
+			for _, child := range test.WidgetRenderer(o).Objects() {
+				walk(child)
+			}
+		}
+	}
+	walk(w.Content())
+	if found == nil {
+		t.Fatalf("no row shows task %d", i)
+	}
+	return found
+}
+
+func texts(v *View) []string {
+	var s []string
+	for i := 0; i < v.todos.Len(); i++ {
+		s = append(s, v.todos.Get(i).Text)
+	}
+	return s
+}
+
+func TestAdd(t *testing.T) {
+	v, w := newView(t)
+	test.Type(v.Entry, "Buy milk")
+	test.Tap(v.AddButton)
+	test.Type(v.Entry, "Call mom")
+	v.Entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+
+	if got := texts(v); !slices.Equal(got, []string{"Buy milk", "Call mom"}) {
+		t.Errorf("tasks = %q", got)
+	}
+	if v.Entry.Text != "" {
+		t.Errorf("entry holds %q after adding", v.Entry.Text)
+	}
+	if got := rowOf(t, w, 1).label.Text; got != "Call mom" {
+		t.Errorf("second row shows %q", got)
+	}
+
+	test.Type(v.Entry, "   ")
+	test.Tap(v.AddButton)
+	if got := texts(v); len(got) != 2 {
+		t.Errorf("blank task added: %q", got)
+	}
+}
+
+// brokenStore fails to save.
+type brokenStore struct{}
+
+func (brokenStore) Load() ([]tasks.Task, error) { return nil, nil }
+func (brokenStore) Save([]tasks.Task) error     { return errors.New("disk full") }
+
+func TestAddFails(t *testing.T) {
+	test.NewApp()
+	todos, err := tasks.New(brokenStore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := test.NewWindow(nil)
+	v := New(todos, w)
+	w.SetContent(v.Content)
+	t.Cleanup(w.Close)
+
+	test.Type(v.Entry, "Buy milk")
+	test.Tap(v.AddButton)
+	if v.Entry.Text != "Buy milk" || todos.Len() != 0 {
+		t.Errorf("after a failed save: entry holds %q, %d tasks", v.Entry.Text, todos.Len())
+	}
+}
+
+func TestComplete(t *testing.T) {
+	v, w := newView(t, "Buy milk", "Call mom")
+	r := rowOf(t, w, 1)
+	test.Tap(r.check)
+
+	if !v.todos.Get(1).Done || v.todos.Get(0).Done {
+		t.Fatalf("only the second task should be done")
+	}
+	if !rowOf(t, w, 1).strike.Visible() {
+		t.Error("done task is not struck through")
+	}
+	if rowOf(t, w, 0).strike.Visible() {
+		t.Error("open task is struck through")
+	}
+
+	test.Tap(rowOf(t, w, 1).check)
+	if v.todos.Get(1).Done || rowOf(t, w, 1).strike.Visible() {
+		t.Error("unchecking did not reopen the task")
+	}
+}
+
+func TestEditInPlace(t *testing.T) {
+	v, w := newView(t, "Buy milk")
+	r := rowOf(t, w, 0)
+	test.DoubleTap(r)
+	if !r.editing() {
+		t.Fatal("double tap did not start editing")
+	}
+	if w.Canvas().Focused() != r.entry {
+		t.Error("the entry of the row is not focused")
+	}
+	r.entry.SetText("Buy oat milk")
+	r.entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+
+	if r.editing() {
+		t.Error("still editing after Return")
+	}
+	if got := v.todos.Get(0).Text; got != "Buy oat milk" {
+		t.Errorf("task text = %q", got)
+	}
+	if got := r.label.Text; got != "Buy oat milk" {
+		t.Errorf("row shows %q", got)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	v, w := newView(t, "a", "b", "c", "d")
+	v.List.Select(0)
+	if !v.UpButton.Disabled() {
+		t.Error("Up is enabled on the first task")
+	}
+	test.Tap(v.DownButton)
+	v.MoveSelected(1)
+	if got := texts(v); !slices.Equal(got, []string{"b", "c", "a", "d"}) {
+		t.Fatalf("after moving down twice: %q", got)
+	}
+	if v.selected != 2 {
+		t.Errorf("selected = %d, want the moved task at 2", v.selected)
+	}
+	test.Tap(v.UpButton)
+	if got := texts(v); !slices.Equal(got, []string{"b", "a", "c", "d"}) {
+		t.Fatalf("after moving up: %q", got)
+	}
+
+	r := rowOf(t, w, 3)
+	r.Dragged(&fyne.DragEvent{Dragged: fyne.NewDelta(0, -2.2*r.Size().Height)})
+	r.DragEnd()
+	if got := texts(v); !slices.Equal(got, []string{"b", "d", "a", "c"}) {
+		t.Errorf("after dragging up two rows: %q", got)
+	}
+}
+
+func TestUndoRemove(t *testing.T) {
+	v, _ := newView(t, "a", "b", "c")
+	if !v.UndoButton.Disabled() {
+		t.Error("Undo is enabled before anything was removed")
+	}
+	v.List.Select(1)
+	test.Tap(v.RemoveButton)
+	v.List.Select(0)
+	test.Tap(v.RemoveButton)
+	if got := texts(v); !slices.Equal(got, []string{"c"}) {
+		t.Fatalf("after removing two: %q", got)
+	}
+
+	test.Tap(v.UndoButton)
+	test.Tap(v.UndoButton)
+	if got := texts(v); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("after undoing both: %q", got)
+	}
+	if v.selected != 1 {
+		t.Errorf("selected = %d, want the restored task at 1", v.selected)
+	}
+	if !v.UndoButton.Disabled() {
+		t.Error("Undo is still enabled with nothing left to undo")
+	}
+}
@@ -0,0 +1,309 @@
+// Package ai connects the chatbot to an LLM backend and keeps the
+// conversation history.
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultSystemPrompt is the system prompt when no persona is chosen.
+const DefaultSystemPrompt = "You are a helpful CLI assistant. Provide clear, concise responses. " +
+	"Use markdown sparingly - prefer plain text with occasional formatting. " +
+	"Keep responses focused and terminal-friendly. Be conversational but brief."
+
+// DefaultTimeout bounds a request when AI_TIMEOUT is not set.
+const DefaultTimeout = 2 * time.Minute
+
+// truncatedNote marks a reply in the history that was cut short, so the
+// model knows it did not finish it.
+const truncatedNote = "\n[response truncated]"
+
+// TypingDelay paces streamed chunks for a typing effect on screen; 0
+// passes them on as soon as they arrive.
+var TypingDelay = 20 * time.Millisecond
+
+var (
+	provider     Provider
+	config       Config    // provider settings, kept for switching model
+	history      []Message // conversation so far, without the system prompt
+	systemPrompt = DefaultSystemPrompt
+	settings     Settings
+	aiEnabled    bool
+	timeout      = DefaultTimeout // per request, 0 for none
+	lastUsage    *TokenUsage      // reported for the last reply, nil if none was
+
+	contextConfig = ContextConfig{Budget: DefaultBudget, Strategy: StrategyWindow}
+	tokenCache    = map[Message]int{}
+)
+
+// Initialize sets up the provider chosen by AI_PROVIDER
+func Initialize() error {
+	aiEnabled = false
+	if os.Getenv("AI_ENABLED") == "false" {
+		return nil
+	}
+
+	timeout = DefaultTimeout
+	if s := os.Getenv("AI_TIMEOUT"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid AI_TIMEOUT %q, want a duration such as 90s or 0 for none", s)
+		}
+		timeout = d
+	}
+
+	c, err := ContextConfigFromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	config = ConfigFromEnv(os.Getenv)
+	p, err := New(context.Background(), config)
+	if err != nil {
+		return err
+	}
+	provider = p
+	history = nil
+	contextConfig = c
+	tokenCache = map[Message]int{}
+	aiEnabled = true
+	return nil
+}
+
+// IsEnabled returns whether AI is enabled
+func IsEnabled() bool {
+	return aiEnabled
+}
+
+// Current returns the provider in use, or nil when AI is disabled.
+func Current() Provider {
+	if !aiEnabled {
+		return nil
+	}
+	return provider
+}
+
+// History returns the conversation so far, without the system prompt.
+func History() []Message {
+	return append([]Message(nil), history...)
+}
+
+// SetHistory replaces the conversation, as when resuming a saved session.
+func SetHistory(messages []Message) {
+	history = append([]Message(nil), messages...)
+}
+
+// Models lists the models the provider offers.
+func Models(ctx context.Context) ([]string, error) {
+	if !aiEnabled || provider == nil {
+		return nil, fmt.Errorf("AI is not enabled or initialized")
+	}
+	return provider.ListModels(ctx)
+}
+
+// SwitchModel sends the following requests to model, keeping the
+// conversation.
+func SwitchModel(ctx context.Context, model string) error {
+	if !aiEnabled || provider == nil {
+		return fmt.Errorf("AI is not enabled or initialized")
+	}
+	c := config
+	c.Model = model
+	p, err := New(ctx, c)
+	if err != nil {
+		return err
+	}
+	provider, config = p, c
+	tokenCache = map[Message]int{}
+	return nil
+}
+
+// lastPrompt is the index of the last user message in the history, or -1.
+func lastPrompt() int {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == RoleUser {
+			return i
+		}
+	}
+	return -1
+}
+
+// LastExchange returns the last prompt and the reply to it, which is
+// empty if none was kept.
+func LastExchange() (prompt, reply string, ok bool) {
+	i := lastPrompt()
+	if i < 0 {
+		return "", "", false
+	}
+	if i+1 < len(history) && history[i+1].Role == RoleAssistant {
+		reply = history[i+1].Content
+	}
+	return history[i].Content, reply, true
+}
+
+// Undo removes the last prompt and the reply to it from the history and
+// returns the prompt.
+func Undo() (string, bool) {
+	i := lastPrompt()
+	if i < 0 {
+		return "", false
+	}
+	prompt := history[i].Content
+	history = history[:i]
+	return prompt, true
+}
+
+// SystemPrompt returns the system instruction sent with every request.
+func SystemPrompt() string {
+	return systemPrompt
+}
+
+// SetSystemPrompt replaces the system instruction, as when switching
+// persona. The conversation is kept.
+func SetSystemPrompt(prompt string) {
+	systemPrompt = prompt
+}
+
+// CurrentSettings returns the generation settings in use.
+func CurrentSettings() Settings {
+	return settings
+}
+
+// SetSettings changes the generation settings for the requests that follow.
+func SetSettings(s Settings) {
+	settings = s
+}
+
+// messages puts the system prompt and prompt around hist.
+func messages(hist []Message, prompt string) []Message {
+	msgs := make([]Message, 0, len(hist)+2)
+	msgs = append(msgs, Message{Role: RoleSystem, Content: systemPrompt})
+	msgs = append(msgs, hist...)
+	return append(msgs, Message{Role: RoleUser, Content: prompt})
+}
+
+// remember appends a finished exchange to the history.
+func remember(prompt, reply string) {
+	history = append(history,
+		Message{Role: RoleUser, Content: prompt},
+		Message{Role: RoleAssistant, Content: reply})
+}
+
+// withTimeout applies the request timeout to ctx.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// newRequest is the request for msgs and images. It adds up the token
+// counts the backend reports in lastUsage.
+func newRequest(msgs []Message, images []Image) Request {
+	lastUsage = nil
+	return Request{Messages: msgs, Settings: settings, Images: images, Usage: func(u TokenUsage) {
+		if lastUsage == nil {
+			lastUsage = &TokenUsage{}
+		}
+		lastUsage.Prompt += u.Prompt
+		lastUsage.Response += u.Response
+	}}
+}
+
+// LastUsage returns the tokens the last reply took, tool rounds included,
+// as the backend counted them. ok is false if the backend did not say.
+func LastUsage() (u TokenUsage, ok bool) {
+	if lastUsage == nil {
+		return TokenUsage{}, false
+	}
+	return *lastUsage, true
+}
+
+// IsInterrupted reports whether err means the request was cancelled or
+// timed out rather than failed.
+func IsInterrupted(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// StreamResponse sends a prompt to the provider and streams the response chunk by chunk.
+// onChunk is called for each real chunk from the API.
+// onComplete receives the full final string when streaming finishes.
+// Cancelling ctx stops the stream; the part received so far is kept in the
+// history, marked as truncated, and the error satisfies IsInterrupted.
+// Images are sent along with the prompt.
+func StreamResponse(ctx context.Context, prompt string, onChunk func(string), onComplete func(string), images ...Image) error {
+	if !aiEnabled || provider == nil {
+		return fmt.Errorf("AI is not enabled or initialized")
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	msgs, err := fit(ctx, prompt)
+	if err != nil {
+		if IsInterrupted(err) {
+			return fmt.Errorf("response truncated: %w", err)
+		}
+		return err
+	}
+	fullText, err := reply(ctx, newRequest(msgs, images), func(chunk string) {
+		if ctx.Err() != nil {
+			return
+		}
+		if onChunk != nil {
+			onChunk(chunk)
+		}
+		time.Sleep(TypingDelay)
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			return err
+		}
+		if fullText != "" {
+			remember(prompt, fullText+truncatedNote)
+		}
+		return fmt.Errorf("response truncated: %w", ctx.Err())
+	}
+
+	if onComplete != nil {
+		onComplete(fullText)
+	}
+	remember(prompt, fullText)
+	return nil
+}
+
+// GetResponse sends a prompt, and any images, to the provider and returns the response (non-streaming fallback)
+func GetResponse(ctx context.Context, prompt string, images ...Image) (string, error) {
+	if !aiEnabled || provider == nil {
+		return "", fmt.Errorf("AI is not enabled or initialized")
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+	msgs, err := fit(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	var text string
+	if ToolsEnabled() {
+		text, err = reply(ctx, newRequest(msgs, images), nil)
+	} else {
+		text, err = provider.Complete(ctx, newRequest(msgs, images))
+	}
+	if err != nil {
+		return "", err
+	}
+	remember(prompt, text)
+	return text, nil
+}
+
+// Close releases the provider (no-op for the current backends)
+func Close() error {
+	return nil
+}
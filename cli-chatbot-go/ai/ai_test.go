@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stalling replies with chunks, then hangs until its context ends.
+type stalling struct {
+	chunks []string
+}
+
+func (s *stalling) Name() string  { return "stalling" }
+func (s *stalling) Model() string { return "test" }
+
+func (s *stalling) Stream(ctx context.Context, req Request, onChunk func(string)) (string, error) {
+	text := ""
+	for _, c := range s.chunks {
+		onChunk(c)
+		text += c
+	}
+	<-ctx.Done()
+	return text, ctx.Err()
+}
+
+func (s *stalling) Complete(ctx context.Context, req Request) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (s *stalling) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (s *stalling) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	return estimateTokens(messages), nil
+}
+
+// use makes p the current provider for the rest of the test.
+func use(t *testing.T, p Provider, d time.Duration) {
+	t.Cleanup(func() {
+		provider, history, aiEnabled, timeout = nil, nil, false, DefaultTimeout
+		contextConfig = ContextConfig{Budget: DefaultBudget, Strategy: StrategyWindow}
+	})
+	provider, history, aiEnabled, timeout = p, nil, true, d
+	tokenCache = map[Message]int{}
+}
+
+func TestStreamCancel(t *testing.T) {
+	use(t, &stalling{chunks: []string{"Once upon", " a time"}}, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got string
+	err := StreamResponse(ctx, "Tell me a story", func(chunk string) {
+		got += chunk
+		if got == "Once upon a time" {
+			cancel()
+		}
+	}, func(string) { t.Error("onComplete called for a cancelled stream") })
+
+	if !IsInterrupted(err) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want a cancellation", err)
+	}
+	want := []Message{
+		{Role: RoleUser, Content: "Tell me a story"},
+		{Role: RoleAssistant, Content: "Once upon a time" + truncatedNote},
+	}
+	if len(history) != 2 || history[0] != want[0] || history[1] != want[1] {
+		t.Errorf("history = %q, want %q", history, want)
+	}
+}
+
+func TestStreamTimeout(t *testing.T) {
+	use(t, &stalling{}, 10*time.Millisecond)
+
+	err := StreamResponse(context.Background(), "Hello?", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want a timeout", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("history = %q, want nothing kept when nothing arrived", history)
+	}
+
+	if _, err := GetResponse(context.Background(), "Hello?"); !IsInterrupted(err) {
+		t.Errorf("GetResponse: err = %v, want a timeout", err)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	use(t, &stalling{}, 0)
+	if _, ok := Undo(); ok {
+		t.Error("Undo on an empty history succeeded")
+	}
+
+	SetHistory([]Message{
+		{Role: RoleSystem, Content: summaryPrefix + "they met"},
+		{Role: RoleUser, Content: "first"},
+		{Role: RoleAssistant, Content: "one"},
+		{Role: RoleUser, Content: "second"},
+		{Role: RoleAssistant, Content: "two"},
+		{Role: RoleUser, Content: "third"}, // interrupted before a reply
+	})
+	if prompt, reply, ok := LastExchange(); prompt != "third" || reply != "" || !ok {
+		t.Errorf("LastExchange = %q, %q, %v", prompt, reply, ok)
+	}
+	for _, want := range []string{"third", "second"} {
+		if prompt, ok := Undo(); prompt != want || !ok {
+			t.Errorf("Undo = %q, %v, want %q", prompt, ok, want)
+		}
+	}
+	if prompt, reply, _ := LastExchange(); prompt != "first" || reply != "one" {
+		t.Errorf("LastExchange = %q, %q", prompt, reply)
+	}
+	if len(history) != 3 {
+		t.Errorf("history = %q", history)
+	}
+}
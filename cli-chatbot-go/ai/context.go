@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Strategies for keeping a conversation inside the token budget.
+const (
+	// StrategyWindow sends the most recent messages that fit.
+	StrategyWindow = "window"
+	// StrategySummarize folds older messages into a summary that replaces
+	// them in the history.
+	StrategySummarize = "summarize"
+)
+
+// DefaultBudget is the token budget when AI_CONTEXT_BUDGET is not set. It
+// is well inside the context window of current models, local ones included.
+const DefaultBudget = 8000
+
+// summaryPrefix starts the message a summary is kept in.
+const summaryPrefix = "Summary of the earlier conversation:\n"
+
+const summaryPrompt = "Summarize the conversation below in a few short paragraphs. " +
+	"Keep names, facts, decisions and open questions; leave out small talk. " +
+	"Reply with the summary only."
+
+// ContextConfig bounds how much of the conversation is sent each turn.
+type ContextConfig struct {
+	// Budget is the most tokens a request may use, counting the system
+	// prompt and the new prompt, or 0 for no limit.
+	Budget   int
+	Strategy string
+	// Pin is the number of leading history messages that are always sent.
+	Pin int
+}
+
+// ContextConfigFromEnv reads the context settings from the environment:
+//
+//	AI_CONTEXT_BUDGET    token budget per request, 0 for no limit
+//	AI_CONTEXT_STRATEGY  window (default) or summarize
+//	AI_CONTEXT_PIN       leading messages that are always sent
+func ContextConfigFromEnv(getenv func(string) string) (ContextConfig, error) {
+	c := ContextConfig{Budget: DefaultBudget, Strategy: StrategyWindow}
+	if s := getenv("AI_CONTEXT_BUDGET"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return c, fmt.Errorf("invalid AI_CONTEXT_BUDGET %q, want a number of tokens or 0 for no limit", s)
+		}
+		c.Budget = n
+	}
+	if s := strings.ToLower(strings.TrimSpace(getenv("AI_CONTEXT_STRATEGY"))); s != "" {
+		if s != StrategyWindow && s != StrategySummarize {
+			return c, fmt.Errorf("invalid AI_CONTEXT_STRATEGY %q, want window or summarize", s)
+		}
+		c.Strategy = s
+	}
+	if s := getenv("AI_CONTEXT_PIN"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return c, fmt.Errorf("invalid AI_CONTEXT_PIN %q, want a number of messages", s)
+		}
+		c.Pin = n
+	}
+	return c, nil
+}
+
+// tokens returns how many tokens m takes up. Counts are cached, so a
+// backend with a tokenizer endpoint is asked once per message.
+func tokens(ctx context.Context, m Message) int {
+	if n, ok := tokenCache[m]; ok {
+		return n
+	}
+	n, err := provider.CountTokens(ctx, []Message{m})
+	if err != nil {
+		return estimateTokens([]Message{m})
+	}
+	tokenCache[m] = n
+	return n
+}
+
+func sumTokens(ctx context.Context, msgs []Message) int {
+	n := 0
+	for _, m := range msgs {
+		n += tokens(ctx, m)
+	}
+	return n
+}
+
+// recent returns the index the most recent history messages that fit in
+// room tokens start at, never before from. The window starts on a user
+// message so that no reply is sent without its question.
+func recent(ctx context.Context, from, room int) int {
+	start := len(history)
+	for start > from && room >= tokens(ctx, history[start-1]) {
+		start--
+		room -= tokens(ctx, history[start])
+	}
+	for start < len(history) && start > from && history[start].Role == RoleAssistant {
+		start++
+	}
+	return start
+}
+
+// pinned is the number of leading history messages that are always sent.
+func pinned() int {
+	return min(contextConfig.Pin, len(history))
+}
+
+// fit returns the messages to send with prompt, within the budget. With
+// the summarize strategy, messages that no longer fit are summarized into
+// the history first.
+func fit(ctx context.Context, prompt string) ([]Message, error) {
+	if contextConfig.Budget == 0 {
+		return messages(history, prompt), nil
+	}
+	pin := pinned()
+	room := contextConfig.Budget - sumTokens(ctx, messages(history[:pin], prompt))
+	start := recent(ctx, pin, room)
+	if start > pin && contextConfig.Strategy == StrategySummarize {
+		// Keep half the room free so that the next turns do not need a
+		// summary straight away.
+		if err := summarize(ctx, pin, recent(ctx, pin, room/2)); err != nil {
+			if IsInterrupted(err) {
+				return nil, err
+			}
+			// Without a summary, fall back to the window for this turn.
+		}
+		start = recent(ctx, pin, room)
+	}
+	sent := append(history[:pin:pin], history[start:]...)
+	return messages(sent, prompt), nil
+}
+
+// summarize replaces history[from:to] with a summary of those messages.
+// An earlier summary among them is folded into the new one.
+func summarize(ctx context.Context, from, to int) error {
+	if to <= from {
+		return nil
+	}
+	var transcript strings.Builder
+	for _, m := range history[from:to] {
+		switch m.Role {
+		case RoleSystem:
+			transcript.WriteString(m.Content)
+		case RoleAssistant:
+			transcript.WriteString("Assistant: " + m.Content)
+		default:
+			transcript.WriteString("User: " + m.Content)
+		}
+		transcript.WriteString("\n\n")
+	}
+	summary, err := provider.Complete(ctx, Request{Messages: []Message{
+		{Role: RoleSystem, Content: summaryPrompt},
+		{Role: RoleUser, Content: transcript.String()},
+	}})
+	if err != nil {
+		return fmt.Errorf("summarizing the conversation: %w", err)
+	}
+
+	rest := append([]Message{{Role: RoleSystem, Content: summaryPrefix + strings.TrimSpace(summary)}}, history[to:]...)
+	history = append(history[:from:from], rest...)
+	return nil
+}
+
+// IsSummary reports whether m holds a summary of earlier messages.
+func IsSummary(m Message) bool {
+	return m.Role == RoleSystem && strings.HasPrefix(m.Content, summaryPrefix)
+}
+
+// Usage describes how the conversation fits in the budget.
+type Usage struct {
+	ContextConfig
+	Messages  int // in the history
+	Summaries int // history messages that are summaries
+	Total     int // tokens in the system prompt and the whole history
+	Sent      int // tokens the next request sends before the new prompt
+	Dropped   int // history messages the next request leaves out
+}
+
+// ContextUsage reports how the conversation fits in the budget. It does
+// not summarize anything.
+func ContextUsage(ctx context.Context) (Usage, error) {
+	if !aiEnabled || provider == nil {
+		return Usage{}, fmt.Errorf("AI is not enabled or initialized")
+	}
+	u := Usage{ContextConfig: contextConfig, Messages: len(history)}
+	for _, m := range history {
+		if IsSummary(m) {
+			u.Summaries++
+		}
+	}
+	system := tokens(ctx, Message{Role: RoleSystem, Content: systemPrompt})
+	u.Total = system + sumTokens(ctx, history)
+	u.Sent = u.Total
+	if u.Budget > 0 {
+		pin := pinned()
+		fixed := system + sumTokens(ctx, history[:pin])
+		start := recent(ctx, pin, u.Budget-fixed)
+		u.Sent = fixed + sumTokens(ctx, history[start:])
+		u.Dropped = start - pin
+	}
+	return u, ctx.Err()
+}
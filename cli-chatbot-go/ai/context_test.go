@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wordy counts a token per word and answers every request with "fine".
+// Completions, used for summaries, are recorded.
+type wordy struct {
+	summaries []string
+}
+
+func (w *wordy) Name() string  { return "wordy" }
+func (w *wordy) Model() string { return "test" }
+
+func (w *wordy) Stream(ctx context.Context, req Request, onChunk func(string)) (string, error) {
+	return "fine", nil
+}
+
+func (w *wordy) Complete(ctx context.Context, req Request) (string, error) {
+	w.summaries = append(w.summaries, req.Messages[len(req.Messages)-1].Content)
+	return "they said hello", nil
+}
+
+func (w *wordy) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (w *wordy) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	n := 0
+	for _, m := range messages {
+		n += len(strings.Fields(m.Content))
+	}
+	return n, nil
+}
+
+// chat is a history of five exchanges, each message four tokens long.
+func chat() []Message {
+	var msgs []Message
+	for _, n := range []string{"one", "two", "three", "four", "five"} {
+		msgs = append(msgs,
+			Message{Role: RoleUser, Content: "question number " + n + " please"},
+			Message{Role: RoleAssistant, Content: "answer number " + n + " here"})
+	}
+	return msgs
+}
+
+// budget leaves room for n messages of the chat next to the system
+// prompt and a one-word prompt.
+func budget(n int) int {
+	return len(strings.Fields(systemPrompt)) + 1 + 4*n
+}
+
+func contents(msgs []Message) []string {
+	var s []string
+	for _, m := range msgs {
+		s = append(s, m.Content)
+	}
+	return s
+}
+
+func TestFitWindow(t *testing.T) {
+	tests := []struct {
+		config ContextConfig
+		want   []string // history messages sent
+	}{
+		{ContextConfig{Budget: 0}, contents(chat())},
+		{ContextConfig{Budget: budget(10)}, contents(chat())},
+		{ContextConfig{Budget: budget(4)}, contents(chat()[6:])},
+		// Three messages fit, but the window must not open on a reply.
+		{ContextConfig{Budget: budget(3)}, contents(chat()[8:])},
+		{ContextConfig{Budget: budget(4), Pin: 2}, contents(append(chat()[:2], chat()[8:]...))},
+		{ContextConfig{Budget: budget(0), Pin: 1}, contents(chat()[:1])},
+	}
+	for _, tt := range tests {
+		use(t, &wordy{}, 0)
+		contextConfig, history = tt.config, chat()
+
+		msgs, err := fit(context.Background(), "hi")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msgs[0].Content != systemPrompt || msgs[len(msgs)-1].Content != "hi" {
+			t.Errorf("%+v: system prompt or prompt missing from %q", tt.config, contents(msgs))
+		}
+		if got := contents(msgs[1 : len(msgs)-1]); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: sent %q, want %q", tt.config, got, tt.want)
+		}
+		if len(history) != 10 {
+			t.Errorf("%+v: the window changed the history", tt.config)
+		}
+	}
+}
+
+func TestFitSummarize(t *testing.T) {
+	p := &wordy{}
+	use(t, p, 0)
+	contextConfig = ContextConfig{Budget: budget(6), Strategy: StrategySummarize, Pin: 2}
+	history = chat()
+
+	msgs, err := fit(context.Background(), "hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Half the room, three messages, is kept after the pinned exchange;
+	// the window starts on a question, leaving two.
+	want := []string{
+		"question number one please", "answer number one here",
+		summaryPrefix + "they said hello",
+		"question number five please", "answer number five here",
+	}
+	if got := contents(history); !reflect.DeepEqual(got, want) {
+		t.Errorf("history = %q, want %q", got, want)
+	}
+	if !IsSummary(history[2]) {
+		t.Errorf("%q is not recognised as a summary", history[2].Content)
+	}
+	if len(p.summaries) != 1 || !strings.HasPrefix(p.summaries[0], "User: question number two please\n\nAssistant: answer number two here") ||
+		strings.Contains(p.summaries[0], "five") {
+		t.Errorf("summarized %q", p.summaries)
+	}
+	if got := contents(msgs[1 : len(msgs)-1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("sent %q, want the new history %q", got, want)
+	}
+
+	// The earlier summary is folded into the next one.
+	history = append(history, chat()[:6]...)
+	if _, err := fit(context.Background(), "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.summaries) != 2 || !strings.HasPrefix(p.summaries[1], summaryPrefix+"they said hello") {
+		t.Errorf("second summary of %q", p.summaries[1:])
+	}
+	summaries := 0
+	for _, m := range history {
+		if IsSummary(m) {
+			summaries++
+		}
+	}
+	if summaries != 1 {
+		t.Errorf("history holds %d summaries, want 1: %q", summaries, contents(history))
+	}
+}
+
+func TestContextUsage(t *testing.T) {
+	use(t, &wordy{}, 0)
+	contextConfig = ContextConfig{Budget: budget(4), Strategy: StrategyWindow, Pin: 2}
+	history = chat()
+
+	u, err := ContextUsage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	system := len(strings.Fields(systemPrompt))
+	want := Usage{ContextConfig: contextConfig, Messages: 10, Total: system + 40, Sent: system + 16, Dropped: 6}
+	if u != want {
+		t.Errorf("ContextUsage = %+v, want %+v", u, want)
+	}
+}
+
+func TestContextConfigFromEnv(t *testing.T) {
+	env := map[string]string{"AI_CONTEXT_BUDGET": "32000", "AI_CONTEXT_STRATEGY": "Summarize", "AI_CONTEXT_PIN": "2"}
+	c, err := ContextConfigFromEnv(func(k string) string { return env[k] })
+	if want := (ContextConfig{Budget: 32000, Strategy: StrategySummarize, Pin: 2}); err != nil || c != want {
+		t.Errorf("ContextConfigFromEnv = %+v, %v, want %+v", c, err, want)
+	}
+	if c, err := ContextConfigFromEnv(func(string) string { return "" }); err != nil || c.Budget != DefaultBudget || c.Strategy != StrategyWindow {
+		t.Errorf("defaults = %+v, %v", c, err)
+	}
+	for k, v := range map[string]string{"AI_CONTEXT_BUDGET": "lots", "AI_CONTEXT_STRATEGY": "forget", "AI_CONTEXT_PIN": "-1"} {
+		if _, err := ContextConfigFromEnv(func(key string) string { return map[string]string{k: v}[key] }); err == nil {
+			t.Errorf("%s=%s: no error", k, v)
+		}
+	}
+}
@@ -2,198 +2,226 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
+	"slices"
 	"strings"
-	"time"
 
 	"google.golang.org/genai"
 )
 
-type GeminiClient struct {
+// Gemini talks to Google's Gemini API.
+type Gemini struct {
 	client    *genai.Client
 	modelName string
-	ctx       context.Context
-	history   []*genai.Content // New: conversation history
 }
 
-var (
-	geminiClient *GeminiClient
-	aiEnabled    bool
-)
-
-// Initialize sets up the Gemini AI client
-func Initialize() error {
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey == "" {
-		aiEnabled = false
-		return fmt.Errorf("GEMINI_API_KEY not found in environment")
-	}
-
-	enabled := os.Getenv("AI_ENABLED")
-	if enabled == "false" {
-		aiEnabled = false
-		return nil
+// NewGemini sets up the Gemini client. BaseURL is only needed to point it
+// at a proxy or a test server.
+func NewGemini(ctx context.Context, c Config) (*Gemini, error) {
+	if c.APIKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY not found in environment")
 	}
-	ctx := context.Background()
 
 	// Use ClientConfig for explicit API key and backend (avoids redundant env set)
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:      c.APIKey,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: c.BaseURL},
 	})
 	if err != nil {
-		aiEnabled = false
-		return fmt.Errorf("failed to create Gemini client: %w", err)
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
-	modelName := os.Getenv("AI_MODEL")
+	modelName := c.Model
 	if modelName == "" {
-		modelName = "gemini-2.5-flash" // Updated to match guide
+		modelName = "gemini-2.5-flash"
 	}
-
-	geminiClient = &GeminiClient{
-		client:    client,
-		modelName: modelName,
-		ctx:       ctx,
-		history:   []*genai.Content{}, // Initialize empty history
-	}
-
-	aiEnabled = true
-	return nil
+	return &Gemini{client: client, modelName: modelName}, nil
 }
 
-// IsEnabled returns whether AI is enabled
-func IsEnabled() bool {
-	return aiEnabled
-}
+func (g *Gemini) Name() string  { return "gemini" }
+func (g *Gemini) Model() string { return g.modelName }
 
-// StreamResponse sends a prompt to Gemini and streams the response chunk by chunk.
-// onChunk is called for each real chunk from the API.
-// onComplete receives the full final string when streaming finishes.
-func StreamResponse(prompt string, onChunk func(string), onComplete func(string)) error {
-	if !aiEnabled || geminiClient == nil {
-		return fmt.Errorf("AI is not enabled or initialized")
-	}
-
-	systemPrompt := "You are a helpful CLI assistant. Provide clear, concise responses. " +
-		"Use markdown sparingly - prefer plain text with occasional formatting. " +
-		"Keep responses focused and terminal-friendly. Be conversational but brief."
-
-	// Build contents with history + new user prompt
-	contents := append(append([]*genai.Content{}, geminiClient.history...),
-		&genai.Content{
-			Role:  "user",
-			Parts: []*genai.Part{{Text: prompt}},
-		})
+// Stream sends req to Gemini and streams the reply chunk by chunk.
+func (g *Gemini) Stream(ctx context.Context, req Request, onChunk func(string)) (string, error) {
+	round, err := g.StreamTools(ctx, req, onChunk)
+	return round.Text, err
+}
 
-	// If first message, prepend system prompt to the user prompt
-	if len(geminiClient.history) == 0 {
-		contents[0].Parts[0].Text = systemPrompt + "\n\nUser: " + contents[0].Parts[0].Text
+// StreamTools is Stream offering req.Tools, and returns the calls the
+// model makes.
+func (g *Gemini) StreamTools(ctx context.Context, req Request, onChunk func(string)) (ToolRound, error) {
+	contents, config := geminiContents(req.Messages, req.Settings)
+	geminiImages(contents, req.Images)
+	contents = append(contents, geminiRounds(req.Rounds)...)
+	if len(req.Tools) > 0 {
+		decls := make([]*genai.FunctionDeclaration, len(req.Tools))
+		for i, t := range req.Tools {
+			decls[i] = &genai.FunctionDeclaration{Name: t.Name, Description: t.Description, ParametersJsonSchema: t.schema()}
+		}
+		config.Tools = []*genai.Tool{{FunctionDeclarations: decls}}
 	}
 
-	// Use real streaming
-	iter := geminiClient.client.Models.GenerateContentStream(
-		geminiClient.ctx,
-		geminiClient.modelName,
-		contents,
-		nil,
-	)
-
+	var round ToolRound
 	var builder strings.Builder
-
-	for result, err := range iter {
+	// Each chunk carries the usage so far; the last one counts.
+	var usage *genai.GenerateContentResponseUsageMetadata
+	for result, err := range g.client.Models.GenerateContentStream(ctx, g.modelName, contents, config) {
 		if err != nil {
-			return fmt.Errorf("streaming error: %w", err)
+			round.Text = builder.String()
+			return round, fmt.Errorf("streaming error: %w", err)
 		}
-
-		// Extract text from the partial response (manual extraction)
-		if len(result.Candidates) == 0 || result.Candidates[0].Content == nil || len(result.Candidates[0].Content.Parts) == 0 {
-			continue
+		if result.UsageMetadata != nil {
+			usage = result.UsageMetadata
 		}
-		chunk := result.Candidates[0].Content.Parts[0].Text
-		if chunk == "" {
+		if len(result.Candidates) == 0 || result.Candidates[0].Content == nil {
 			continue
 		}
-
-		if onChunk != nil {
-			onChunk(chunk)
+		for _, part := range result.Candidates[0].Content.Parts {
+			if c := part.FunctionCall; c != nil {
+				args, err := json.Marshal(c.Args)
+				if err != nil || c.Args == nil {
+					args = []byte("{}")
+				}
+				round.Calls = append(round.Calls, ToolCall{ID: c.ID, Name: c.Name, Args: args, signature: part.ThoughtSignature})
+			}
+			if part.Text == "" || part.Thought {
+				continue
+			}
+			if onChunk != nil {
+				onChunk(part.Text)
+			}
+			builder.WriteString(part.Text)
 		}
-		builder.WriteString(chunk)
-
-		// Optional small delay for visual typing effect (remove for max speed)
-		time.Sleep(20 * time.Millisecond)
 	}
+	round.Text = builder.String()
+	geminiUsage(req, usage)
+	return round, nil
+}
 
-	fullText := builder.String()
-	if onComplete != nil {
-		onComplete(fullText)
+// Complete sends req to Gemini and returns the reply.
+func (g *Gemini) Complete(ctx context.Context, req Request) (string, error) {
+	contents, config := geminiContents(req.Messages, req.Settings)
+	geminiImages(contents, req.Images)
+	result, err := g.client.Models.GenerateContent(ctx, g.modelName, contents, config)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate content: %w", err)
 	}
-
-	// On success, append user and model to history
-	geminiClient.history = append(geminiClient.history, contents[len(contents)-1]) // user
-	geminiClient.history = append(geminiClient.history,
-		&genai.Content{
-			Role:  "model",
-			Parts: []*genai.Part{{Text: fullText}},
-		})
-
-	return nil
+	geminiUsage(req, result.UsageMetadata)
+	text := result.Text()
+	if text == "" {
+		return "", fmt.Errorf("no response generated")
+	}
+	return text, nil
 }
 
-// GetResponse sends a prompt to Gemini and returns the response (non-streaming fallback)
-func GetResponse(prompt string) (string, error) {
-	if !aiEnabled || geminiClient == nil {
-		return "", fmt.Errorf("AI is not enabled or initialized")
+// geminiUsage passes the token counts Gemini reported on to req. The
+// thinking a model does counts towards the response.
+func geminiUsage(req Request, u *genai.GenerateContentResponseUsageMetadata) {
+	if u != nil {
+		req.report(int(u.PromptTokenCount+u.ToolUsePromptTokenCount), int(u.CandidatesTokenCount+u.ThoughtsTokenCount))
 	}
+}
 
-	systemPrompt := "You are a helpful CLI assistant. Provide clear, concise responses. " +
-		"Use markdown sparingly - prefer plain text with occasional formatting. " +
-		"Keep responses focused and terminal-friendly. Be conversational but brief."
-
-	// Build contents with history + new user prompt
-	contents := append(append([]*genai.Content{}, geminiClient.history...),
-		&genai.Content{
-			Role:  "user",
-			Parts: []*genai.Part{{Text: prompt}},
-		})
-
-	// If first message, prepend system prompt to the user prompt
-	if len(geminiClient.history) == 0 {
-		contents[0].Parts[0].Text = systemPrompt + "\n\nUser: " + contents[0].Parts[0].Text
+// ListModels returns the Gemini models that can generate content.
+func (g *Gemini) ListModels(ctx context.Context) ([]string, error) {
+	var names []string
+	for m, err := range g.client.Models.All(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("listing models: %w", err)
+		}
+		if len(m.SupportedActions) > 0 && !slices.Contains(m.SupportedActions, "generateContent") {
+			continue
+		}
+		names = append(names, strings.TrimPrefix(m.Name, "models/"))
 	}
+	return names, nil
+}
 
-	result, err := geminiClient.client.Models.GenerateContent(
-		geminiClient.ctx,
-		geminiClient.modelName,
-		contents,
-		nil,
-	)
+// CountTokens asks Gemini how many tokens messages take up.
+func (g *Gemini) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	contents, config := geminiContents(messages, Settings{})
+	if config.SystemInstruction != nil {
+		// The Gemini API does not take a system instruction here, so count
+		// it as the opening user turn.
+		contents = append([]*genai.Content{config.SystemInstruction}, contents...)
+	}
+	result, err := g.client.Models.CountTokens(ctx, g.modelName, contents, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate content: %w", err)
+		return 0, fmt.Errorf("counting tokens: %w", err)
 	}
+	return int(result.TotalTokens), nil
+}
 
-	// Extract text manually
-	if len(result.Candidates) == 0 || result.Candidates[0].Content == nil || len(result.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no response generated")
-	}
-	text := result.Candidates[0].Content.Parts[0].Text
-	if text == "" {
-		return "", fmt.Errorf("no response generated")
+// geminiImages adds images as inline data to the last user turn of
+// contents.
+func geminiImages(contents []*genai.Content, images []Image) {
+	for i := len(contents) - 1; i >= 0 && len(images) > 0; i-- {
+		if contents[i].Role != genai.RoleUser {
+			continue
+		}
+		for _, img := range images {
+			contents[i].Parts = append(contents[i].Parts, genai.NewPartFromBytes(img.Data, img.MIMEType))
+		}
+		return
 	}
+}
 
-	// On success, append user and model to history
-	geminiClient.history = append(geminiClient.history, contents[len(contents)-1]) // user
-	geminiClient.history = append(geminiClient.history,
-		&genai.Content{
-			Role:  "model",
-			Parts: []*genai.Part{{Text: text}},
-		})
-
-	return text, nil
+// geminiRounds converts tool rounds to Gemini's format: the model's turn
+// with its calls, then a user turn with the results.
+func geminiRounds(rounds []ToolRound) []*genai.Content {
+	var contents []*genai.Content
+	for _, round := range rounds {
+		model := &genai.Content{Role: genai.RoleModel}
+		if round.Text != "" {
+			model.Parts = append(model.Parts, genai.NewPartFromText(round.Text))
+		}
+		results := &genai.Content{Role: genai.RoleUser}
+		for i, c := range round.Calls {
+			var args map[string]any
+			json.Unmarshal(c.Args, &args)
+			model.Parts = append(model.Parts, &genai.Part{
+				FunctionCall:     &genai.FunctionCall{ID: c.ID, Name: c.Name, Args: args},
+				ThoughtSignature: c.signature,
+			})
+			results.Parts = append(results.Parts, &genai.Part{FunctionResponse: &genai.FunctionResponse{
+				ID:       c.ID,
+				Name:     c.Name,
+				Response: map[string]any{"output": round.Results[i]},
+			}})
+		}
+		contents = append(contents, model, results)
+	}
+	return contents
 }
 
-// Close closes the Gemini client connection (no-op for current SDK)
-func Close() error {
-	return nil
+// geminiContents converts messages and settings to Gemini's format. System
+// messages become the system instruction, and assistant turns have the
+// role "model".
+func geminiContents(messages []Message, s Settings) ([]*genai.Content, *genai.GenerateContentConfig) {
+	var contents []*genai.Content
+	var system []string
+	for _, m := range messages {
+		switch m.Role {
+		case RoleSystem:
+			system = append(system, m.Content)
+		case RoleAssistant:
+			contents = append(contents, genai.NewContentFromText(m.Content, genai.RoleModel))
+		default:
+			contents = append(contents, genai.NewContentFromText(m.Content, genai.RoleUser))
+		}
+	}
+
+	config := &genai.GenerateContentConfig{MaxOutputTokens: int32(s.MaxTokens)}
+	if len(system) > 0 {
+		config.SystemInstruction = genai.NewContentFromText(strings.Join(system, "\n\n"), genai.RoleUser)
+	}
+	if s.Temperature != nil {
+		config.Temperature = genai.Ptr(float32(*s.Temperature))
+	}
+	if s.TopP != nil {
+		config.TopP = genai.Ptr(float32(*s.TopP))
+	}
+	return contents, config
 }
@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// httpBackend is the plumbing shared by the providers that speak plain
+// JSON over HTTP.
+type httpBackend struct {
+	name    string
+	baseURL string
+	header  http.Header
+	client  *http.Client
+}
+
+func newHTTPBackend(name, baseURL string) httpBackend {
+	return httpBackend{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		header:  http.Header{"Content-Type": {"application/json"}},
+		client:  http.DefaultClient,
+	}
+}
+
+// do sends body, if any, to path and returns the response once it has
+// checked the status. The caller closes the body.
+func (b httpBackend) do(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = b.header.Clone()
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", b.name, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("%s returned %s: %s", b.name, resp.Status, errorMessage(resp.Body))
+	}
+	return resp, nil
+}
+
+// getJSON decodes the response to a request into v.
+func (b httpBackend) getJSON(ctx context.Context, method, path string, body, v any) error {
+	resp, err := b.do(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s response: %w", b.name, err)
+	}
+	return nil
+}
+
+// errorMessage pulls the message out of an error response. Backends put it
+// in "error" either as a string or as an object with a "message".
+func errorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 4096))
+	var e struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &e) == nil && len(e.Error) > 0 {
+		var s string
+		if json.Unmarshal(e.Error, &s) == nil {
+			return s
+		}
+		var obj struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(e.Error, &obj) == nil && obj.Message != "" {
+			return obj.Message
+		}
+	}
+	return strings.TrimSpace(string(data))
+}
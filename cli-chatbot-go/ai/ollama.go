@@ -0,0 +1,203 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Ollama talks to a local Ollama server.
+type Ollama struct {
+	httpBackend
+	modelName string
+}
+
+// NewOllama sets up an Ollama client. BaseURL may leave out the scheme, as
+// OLLAMA_HOST often does.
+func NewOllama(c Config) (*Ollama, error) {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	o := &Ollama{httpBackend: newHTTPBackend("ollama", baseURL), modelName: c.Model}
+	if o.modelName == "" {
+		o.modelName = "llama3.2"
+	}
+	return o, nil
+}
+
+func (o *Ollama) Name() string  { return "ollama" }
+func (o *Ollama) Model() string { return o.modelName }
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []functionTool  `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options"`
+}
+
+// ollamaMessage is a Message with the fields of a tool round: the calls an
+// assistant message makes, and the tool a tool message answers for.
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+	Images    [][]byte         `json:"images,omitempty"` // sent base64-encoded
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+}
+
+func (o *Ollama) request(req Request, stream bool) ollamaRequest {
+	messages := make([]ollamaMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, ollamaMessage{Role: m.Role, Content: m.Content})
+	}
+	if i := lastUser(req.Messages); i >= 0 {
+		for _, img := range req.Images {
+			messages[i].Images = append(messages[i].Images, img.Data)
+		}
+	}
+	for _, round := range req.Rounds {
+		calls := make([]ollamaToolCall, len(round.Calls))
+		for i, c := range round.Calls {
+			calls[i].Function.Name = c.Name
+			calls[i].Function.Arguments = c.Args
+			if len(c.Args) == 0 {
+				calls[i].Function.Arguments = json.RawMessage("{}")
+			}
+		}
+		messages = append(messages, ollamaMessage{Role: RoleAssistant, Content: round.Text, ToolCalls: calls})
+		for i, result := range round.Results {
+			messages = append(messages, ollamaMessage{Role: "tool", Content: result, ToolName: round.Calls[i].Name})
+		}
+	}
+	return ollamaRequest{
+		Model:    o.modelName,
+		Messages: messages,
+		Tools:    functionTools(req.Tools),
+		Stream:   stream,
+		Options: ollamaOptions{
+			Temperature: req.Settings.Temperature,
+			TopP:        req.Settings.TopP,
+			NumPredict:  req.Settings.MaxTokens,
+		},
+	}
+}
+
+type ollamaResponse struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error"`
+	// Token counts, sent with the last event. Ollama leaves out
+	// PromptEvalCount when the prompt was cached.
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+// report passes the token counts of the last event on to req.
+func (r ollamaResponse) report(req Request) {
+	if r.PromptEvalCount > 0 || r.EvalCount > 0 {
+		req.report(r.PromptEvalCount, r.EvalCount)
+	}
+}
+
+// Stream sends req and reads the reply from the JSON lines Ollama answers
+// with.
+func (o *Ollama) Stream(ctx context.Context, req Request, onChunk func(string)) (string, error) {
+	round, err := o.StreamTools(ctx, req, onChunk)
+	return round.Text, err
+}
+
+// StreamTools is Stream offering req.Tools, and returns the calls the
+// model makes. Ollama sends each call whole.
+func (o *Ollama) StreamTools(ctx context.Context, req Request, onChunk func(string)) (ToolRound, error) {
+	var round ToolRound
+	resp, err := o.do(ctx, http.MethodPost, "/api/chat", o.request(req, true))
+	if err != nil {
+		return round, toolsError(err, req)
+	}
+	defer resp.Body.Close()
+
+	var builder strings.Builder
+	dec := json.NewDecoder(bufio.NewReader(resp.Body))
+	for dec.More() {
+		var event ollamaResponse
+		if err := dec.Decode(&event); err != nil {
+			round.Text = builder.String()
+			return round, fmt.Errorf("streaming error: %w", err)
+		}
+		if event.Error != "" {
+			round.Text = builder.String()
+			return round, fmt.Errorf("streaming error: %s", event.Error)
+		}
+		for _, c := range event.Message.ToolCalls {
+			round.Calls = append(round.Calls, ToolCall{Name: c.Function.Name, Args: c.Function.Arguments})
+		}
+		if chunk := event.Message.Content; chunk != "" {
+			if onChunk != nil {
+				onChunk(chunk)
+			}
+			builder.WriteString(chunk)
+		}
+		if event.Done {
+			event.report(req)
+			break
+		}
+	}
+	round.Text = builder.String()
+	return round, nil
+}
+
+// Complete sends req and returns the reply.
+func (o *Ollama) Complete(ctx context.Context, req Request) (string, error) {
+	var result ollamaResponse
+	if err := o.getJSON(ctx, http.MethodPost, "/api/chat", o.request(req, false), &result); err != nil {
+		return "", err
+	}
+	result.report(req)
+	if result.Message.Content == "" {
+		return "", fmt.Errorf("no response generated")
+	}
+	return result.Message.Content, nil
+}
+
+// ListModels returns the models pulled on the server.
+func (o *Ollama) ListModels(ctx context.Context) ([]string, error) {
+	var result struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := o.getJSON(ctx, http.MethodGet, "/api/tags", nil, &result); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(result.Models))
+	for i, m := range result.Models {
+		names[i] = m.Name
+	}
+	return names, nil
+}
+
+// CountTokens estimates the token count, as Ollama has no endpoint for it.
+func (o *Ollama) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	return estimateTokens(messages), nil
+}
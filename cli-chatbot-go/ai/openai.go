@@ -0,0 +1,261 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenAI talks to the OpenAI chat completions API, or to any server that
+// implements it, such as vLLM, LM Studio or llama.cpp's server.
+type OpenAI struct {
+	httpBackend
+	modelName string
+}
+
+// NewOpenAI sets up an OpenAI-compatible client. An API key is required
+// for the public endpoint only; local servers usually do without.
+func NewOpenAI(c Config) (*OpenAI, error) {
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		if c.APIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY not found in environment")
+		}
+		baseURL = "https://api.openai.com/v1"
+	}
+	o := &OpenAI{httpBackend: newHTTPBackend("openai", baseURL), modelName: c.Model}
+	if c.APIKey != "" {
+		o.header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	if o.modelName == "" {
+		o.modelName = "gpt-4o-mini"
+	}
+	return o, nil
+}
+
+func (o *OpenAI) Name() string  { return "openai" }
+func (o *OpenAI) Model() string { return o.modelName }
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Tools       []functionTool  `json:"tools,omitempty"`
+	Stream      bool            `json:"stream"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	TopP        *float64        `json:"top_p,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	// StreamOptions asks for the token usage at the end of a stream.
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIMessage is a Message with the fields of a tool round: the calls an
+// assistant message makes, and the call a tool message answers.
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+	images     []Image
+}
+
+// MarshalJSON sends the content as a list of parts when the message has
+// images, each one inlined as a data URL.
+func (m openAIMessage) MarshalJSON() ([]byte, error) {
+	type message openAIMessage
+	if len(m.images) == 0 {
+		return json.Marshal(message(m))
+	}
+	parts := []map[string]any{{"type": "text", "text": m.Content}}
+	for _, img := range m.images {
+		url := "data:" + img.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+		parts = append(parts, map[string]any{"type": "image_url", "image_url": map[string]string{"url": url}})
+	}
+	return json.Marshal(struct {
+		message
+		Content []map[string]any `json:"content"`
+	}{message(m), parts})
+}
+
+type openAIToolCall struct {
+	Index    *int   `json:"index,omitempty"` // in streamed deltas only
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+func (o *OpenAI) request(req Request, stream bool) openAIRequest {
+	messages := make([]openAIMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, openAIMessage{Role: m.Role, Content: m.Content})
+	}
+	if i := lastUser(req.Messages); i >= 0 {
+		messages[i].images = req.Images
+	}
+	for r, round := range req.Rounds {
+		calls := make([]openAIToolCall, len(round.Calls))
+		for i, c := range round.Calls {
+			calls[i].ID = callID(c, r, i)
+			calls[i].Type = "function"
+			calls[i].Function.Name = c.Name
+			calls[i].Function.Arguments = string(c.Args)
+		}
+		messages = append(messages, openAIMessage{Role: RoleAssistant, Content: round.Text, ToolCalls: calls})
+		for i, result := range round.Results {
+			messages = append(messages, openAIMessage{Role: "tool", Content: result, ToolCallID: calls[i].ID})
+		}
+	}
+	var options *openAIStreamOptions
+	if stream {
+		options = &openAIStreamOptions{IncludeUsage: true}
+	}
+	return openAIRequest{
+		Model:         o.modelName,
+		Messages:      messages,
+		Tools:         functionTools(req.Tools),
+		Stream:        stream,
+		Temperature:   req.Settings.Temperature,
+		TopP:          req.Settings.TopP,
+		MaxTokens:     req.Settings.MaxTokens,
+		StreamOptions: options,
+	}
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+		Delta   openAIMessage `json:"delta"`
+	} `json:"choices"`
+	// Usage comes with the reply, or in a last event of its own when
+	// streaming. Servers may leave it out.
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Stream sends req and reads the reply from the server-sent events the
+// API answers with.
+func (o *OpenAI) Stream(ctx context.Context, req Request, onChunk func(string)) (string, error) {
+	round, err := o.StreamTools(ctx, req, onChunk)
+	return round.Text, err
+}
+
+// StreamTools is Stream offering req.Tools, and returns the calls the
+// model makes. They arrive in pieces, each delta naming the call it adds
+// to by index.
+func (o *OpenAI) StreamTools(ctx context.Context, req Request, onChunk func(string)) (ToolRound, error) {
+	var round ToolRound
+	resp, err := o.do(ctx, http.MethodPost, "/chat/completions", o.request(req, true))
+	if err != nil {
+		return round, toolsError(err, req)
+	}
+	defer resp.Body.Close()
+
+	var builder strings.Builder
+	var args []string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var event openAIResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			round.Text = builder.String()
+			return round, fmt.Errorf("streaming error: %w", err)
+		}
+		if u := event.Usage; u != nil {
+			req.report(u.PromptTokens, u.CompletionTokens)
+		}
+		if len(event.Choices) == 0 {
+			continue
+		}
+		delta := event.Choices[0].Delta
+		for _, c := range delta.ToolCalls {
+			i := len(round.Calls) - 1
+			if c.Index != nil {
+				i = *c.Index
+			} else if c.ID != "" || i < 0 {
+				i++
+			}
+			for len(round.Calls) <= i {
+				round.Calls = append(round.Calls, ToolCall{})
+				args = append(args, "")
+			}
+			if c.ID != "" {
+				round.Calls[i].ID = c.ID
+			}
+			if c.Function.Name != "" {
+				round.Calls[i].Name = c.Function.Name
+			}
+			args[i] += c.Function.Arguments
+		}
+		if chunk := delta.Content; chunk != "" {
+			if onChunk != nil {
+				onChunk(chunk)
+			}
+			builder.WriteString(chunk)
+		}
+	}
+	round.Text = builder.String()
+	for i := range round.Calls {
+		round.Calls[i].Args = json.RawMessage(args[i])
+	}
+	if err := scanner.Err(); err != nil {
+		return round, fmt.Errorf("streaming error: %w", err)
+	}
+	return round, nil
+}
+
+// Complete sends req and returns the reply.
+func (o *OpenAI) Complete(ctx context.Context, req Request) (string, error) {
+	var result openAIResponse
+	if err := o.getJSON(ctx, http.MethodPost, "/chat/completions", o.request(req, false), &result); err != nil {
+		return "", err
+	}
+	if u := result.Usage; u != nil {
+		req.report(u.PromptTokens, u.CompletionTokens)
+	}
+	if len(result.Choices) == 0 || result.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("no response generated")
+	}
+	return result.Choices[0].Message.Content, nil
+}
+
+// ListModels returns the models the server offers.
+func (o *OpenAI) ListModels(ctx context.Context) ([]string, error) {
+	var result struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := o.getJSON(ctx, http.MethodGet, "/models", nil, &result); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(result.Data))
+	for i, m := range result.Data {
+		names[i] = m.ID
+	}
+	return names, nil
+}
+
+// CountTokens estimates the token count, as the API has no endpoint for it.
+func (o *OpenAI) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	return estimateTokens(messages), nil
+}
@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Roles a Message can have.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one turn of a conversation, in a form every backend accepts.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Image is a picture sent along with a prompt, for models that can see.
+// Images are sent with the prompt they are attached to only; the history
+// keeps the prompt's text.
+type Image struct {
+	Name     string
+	MIMEType string // e.g. "image/png"
+	Data     []byte
+}
+
+// lastUser is the index of the last user message in msgs, which images
+// are sent with, or -1.
+func lastUser(msgs []Message) int {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == RoleUser {
+			return i
+		}
+	}
+	return -1
+}
+
+// Provider is an LLM backend the chat can talk to.
+type Provider interface {
+	// Name identifies the backend, e.g. "gemini".
+	Name() string
+	// Model is the model the provider sends prompts to.
+	Model() string
+	// Stream sends req and calls onChunk with each piece of the reply as it
+	// arrives. It returns the full reply, or on error the part of it
+	// received so far.
+	Stream(ctx context.Context, req Request, onChunk func(string)) (string, error)
+	// Complete sends req and returns the whole reply at once.
+	Complete(ctx context.Context, req Request) (string, error)
+	// ListModels returns the models the backend offers.
+	ListModels(ctx context.Context) ([]string, error)
+	// CountTokens returns how many tokens messages take up. Backends without
+	// a tokenizer endpoint return an estimate.
+	CountTokens(ctx context.Context, messages []Message) (int, error)
+}
+
+// ToolCaller is a Provider whose models can call the tools in a request.
+type ToolCaller interface {
+	Provider
+	// StreamTools is Stream offering req.Tools to the model, with the
+	// rounds of calls so far after req.Messages. It returns the model's
+	// turn: the text streamed and the calls it makes, if any.
+	StreamTools(ctx context.Context, req Request, onChunk func(string)) (ToolRound, error)
+}
+
+// Config selects and sets up a provider.
+type Config struct {
+	Provider string // "gemini", "openai" or "ollama"
+	Model    string // empty for the provider's default
+	APIKey   string
+	BaseURL  string // empty for the provider's public endpoint
+}
+
+// ConfigFromEnv reads the provider settings from the environment:
+//
+//	AI_PROVIDER     gemini (default), openai or ollama
+//	AI_MODEL        model name, defaults per provider
+//	AI_BASE_URL     endpoint, for OpenAI-compatible servers and proxies
+//	GEMINI_API_KEY  key for gemini
+//	OPENAI_API_KEY  key for openai, optional with AI_BASE_URL
+//	OLLAMA_HOST     Ollama server, defaults to http://localhost:11434
+func ConfigFromEnv(getenv func(string) string) Config {
+	c := Config{
+		Provider: strings.ToLower(strings.TrimSpace(getenv("AI_PROVIDER"))),
+		Model:    getenv("AI_MODEL"),
+		BaseURL:  getenv("AI_BASE_URL"),
+	}
+	if c.Provider == "" {
+		c.Provider = "gemini"
+	}
+	switch c.Provider {
+	case "gemini":
+		c.APIKey = getenv("GEMINI_API_KEY")
+	case "openai":
+		c.APIKey = getenv("OPENAI_API_KEY")
+	case "ollama":
+		if c.BaseURL == "" {
+			c.BaseURL = getenv("OLLAMA_HOST")
+		}
+	}
+	return c
+}
+
+// New creates the provider c asks for.
+func New(ctx context.Context, c Config) (Provider, error) {
+	switch c.Provider {
+	case "gemini":
+		return NewGemini(ctx, c)
+	case "openai":
+		return NewOpenAI(c)
+	case "ollama":
+		return NewOllama(c)
+	}
+	return nil, fmt.Errorf("unknown AI provider %q (want gemini, openai or ollama)", c.Provider)
+}
+
+// estimateTokens approximates the token count of messages at about four
+// characters per token, which is close enough for English text.
+func estimateTokens(messages []Message) int {
+	n := 0
+	for _, m := range messages {
+		n += (len(m.Content)+3)/4 + 4 // per-message overhead for the role
+	}
+	return n
+}
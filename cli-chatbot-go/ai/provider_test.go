@@ -0,0 +1,511 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var conversation = []Message{
+	{Role: RoleSystem, Content: "Be brief."},
+	{Role: RoleUser, Content: "Hi"},
+	{Role: RoleAssistant, Content: "Hello!"},
+	{Role: RoleUser, Content: "Count to three"},
+}
+
+var temperature = 0.2
+
+// request is what every test sends: the conversation, a low temperature
+// and a cap on the reply length.
+var request = Request{Messages: conversation, Settings: Settings{Temperature: &temperature, MaxTokens: 64}}
+
+// plain returns the messages a backend was sent without the fields of tool
+// rounds.
+func plain(t *testing.T, messages any) []Message {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []Message
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// stand-in servers for each backend, answering "one two three" in chunks.
+
+func openAIServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			http.Error(w, `{"error":{"message":"bad key"}}`, http.StatusUnauthorized)
+			return
+		}
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if !reflect.DeepEqual(plain(t, req.Messages), conversation) || req.Model != "local-model" ||
+			req.Temperature == nil || *req.Temperature != temperature || req.TopP != nil || req.MaxTokens != 64 {
+			t.Errorf("request = %+v", req)
+		}
+		if !req.Stream {
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"one two three"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`)
+			return
+		}
+		if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("stream options = %+v, want usage included", req.StreamOptions)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{"one", " two", " three"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":3}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+	mux.HandleFunc("GET /models", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[{"id":"local-model"},{"id":"other"}]}`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func ollamaServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/chat", func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if req.Model == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"model \"missing\" not found, try pulling it first"}`)
+			return
+		}
+		if !reflect.DeepEqual(plain(t, req.Messages), conversation) {
+			t.Errorf("messages = %+v", req.Messages)
+		}
+		if o := req.Options; o.Temperature == nil || *o.Temperature != temperature || o.TopP != nil || o.NumPredict != 64 {
+			t.Errorf("options = %+v", o)
+		}
+		if !req.Stream {
+			fmt.Fprint(w, `{"message":{"role":"assistant","content":"one two three"},"done":true,"prompt_eval_count":12,"eval_count":3}`)
+			return
+		}
+		for _, chunk := range []string{"one", " two", " three"} {
+			fmt.Fprintf(w, "{\"message\":{\"role\":\"assistant\",\"content\":%q},\"done\":false}\n", chunk)
+		}
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":3}`+"\n")
+	})
+	mux.HandleFunc("GET /api/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models":[{"name":"llama3.2:latest"},{"name":"qwen2.5:7b"}]}`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func geminiServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	check := func(r *http.Request) {
+		var req struct {
+			Contents []struct {
+				Role  string
+				Parts []struct{ Text string }
+			}
+			SystemInstruction struct {
+				Parts []struct{ Text string }
+			}
+			GenerationConfig map[string]any
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		var turns []string
+		for _, c := range req.Contents {
+			turns = append(turns, c.Role+": "+c.Parts[0].Text)
+		}
+		if want := []string{"user: Hi", "model: Hello!", "user: Count to three"}; !reflect.DeepEqual(turns, want) {
+			t.Errorf("contents = %q, want %q", turns, want)
+		}
+		if len(req.SystemInstruction.Parts) == 0 || req.SystemInstruction.Parts[0].Text != "Be brief." {
+			t.Errorf("system instruction = %+v", req.SystemInstruction)
+		}
+		if c := req.GenerationConfig; c["temperature"] != temperature || c["maxOutputTokens"] != 64.0 || c["topP"] != nil {
+			t.Errorf("generation config = %v", c)
+		}
+	}
+	// Each chunk of a stream has the usage so far.
+	reply := func(text string, response int) string {
+		return fmt.Sprintf(`{"candidates":[{"content":{"role":"model","parts":[{"text":%q}]}}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":%d}}`, text, response)
+	}
+	mux.HandleFunc("POST /v1beta/models/gemini-test:streamGenerateContent", func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i, chunk := range []string{"one", " two", " three"} {
+			fmt.Fprintf(w, "data: %s\n\n", reply(chunk, i+1))
+		}
+	})
+	mux.HandleFunc("POST /v1beta/models/gemini-test:generateContent", func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		fmt.Fprint(w, reply("one two three", 3))
+	})
+	mux.HandleFunc("POST /v1beta/models/gemini-test:countTokens", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"totalTokens":17}`)
+	})
+	mux.HandleFunc("GET /v1beta/models", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models":[
+			{"name":"models/gemini-test","supportedGenerationMethods":["generateContent","countTokens"]},
+			{"name":"models/embedding-test","supportedGenerationMethods":["embedContent"]}]}`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestProviders(t *testing.T) {
+	tests := []struct {
+		config Config
+		server func(*testing.T) *httptest.Server
+		models []string
+		tokens int // 0 for an estimate
+	}{
+		{Config{Provider: "openai", Model: "local-model", APIKey: "sk-test"}, openAIServer, []string{"local-model", "other"}, 0},
+		{Config{Provider: "ollama"}, ollamaServer, []string{"llama3.2:latest", "qwen2.5:7b"}, 0},
+		{Config{Provider: "gemini", Model: "gemini-test", APIKey: "key"}, geminiServer, []string{"gemini-test"}, 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.config.Provider, func(t *testing.T) {
+			srv := tt.server(t)
+			defer srv.Close()
+			tt.config.BaseURL = srv.URL
+			ctx := context.Background()
+
+			p, err := New(ctx, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Name() != tt.config.Provider {
+				t.Errorf("Name() = %q", p.Name())
+			}
+
+			var usage []TokenUsage
+			req := request
+			req.Usage = func(u TokenUsage) { usage = append(usage, u) }
+			reported := []TokenUsage{{Prompt: 12, Response: 3}}
+
+			var chunks []string
+			full, err := p.Stream(ctx, req, func(s string) { chunks = append(chunks, s) })
+			if err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			if want := []string{"one", " two", " three"}; !reflect.DeepEqual(chunks, want) || full != "one two three" {
+				t.Errorf("Stream = %q in chunks %q", full, chunks)
+			}
+			if !reflect.DeepEqual(usage, reported) {
+				t.Errorf("Stream reported usage %+v, want %+v", usage, reported)
+			}
+
+			usage = nil
+			text, err := p.Complete(ctx, req)
+			if err != nil || text != "one two three" {
+				t.Errorf("Complete = %q, %v", text, err)
+			}
+			if !reflect.DeepEqual(usage, reported) {
+				t.Errorf("Complete reported usage %+v, want %+v", usage, reported)
+			}
+
+			models, err := p.ListModels(ctx)
+			if err != nil || !reflect.DeepEqual(models, tt.models) {
+				t.Errorf("ListModels = %q, %v, want %q", models, err, tt.models)
+			}
+
+			tokens, err := p.CountTokens(ctx, conversation)
+			want := tt.tokens
+			if want == 0 {
+				want = estimateTokens(conversation)
+			}
+			if err != nil || tokens != want {
+				t.Errorf("CountTokens = %d, %v, want %d", tokens, err, want)
+			}
+		})
+	}
+}
+
+// add is the tool offered in TestStreamTools. The stand-in servers call it
+// with {"a":1,"b":2} after saying "Let me add.", and answer "3" once they
+// get the result.
+var add = Tool{
+	Name:        "add",
+	Description: "Adds two numbers.",
+	Parameters: map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"a": map[string]any{"type": "number"}, "b": map[string]any{"type": "number"}},
+	},
+}
+
+func openAIToolServer(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Type != "function" || req.Tools[0].Function.Name != "add" {
+			t.Errorf("tools = %+v", req.Tools)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		if last := req.Messages[len(req.Messages)-1]; last.Role == "tool" {
+			call := req.Messages[len(req.Messages)-2]
+			if len(call.ToolCalls) != 1 || call.ToolCalls[0].ID != "call_1" || call.ToolCalls[0].Function.Arguments != `{"a":1,"b":2}` ||
+				call.Content != "Let me add." || last.ToolCallID != "call_1" || last.Content != "3" {
+				t.Errorf("round = %+v, %+v", call, last)
+			}
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"3\"}}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		for _, event := range []string{
+			`{"content":"Let me add."}`,
+			`{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"add","arguments":"{\"a\":1,"}}]}`,
+			`{"tool_calls":[{"index":0,"function":{"arguments":"\"b\":2}"}}]}`,
+		} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":%s}]}\n\n", event)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}
+}
+
+func ollamaToolServer(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if req.Model == "gemma" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"registry.ollama.ai/library/gemma:latest does not support tools"}`)
+			return
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Function.Name != "add" {
+			t.Errorf("tools = %+v", req.Tools)
+		}
+		if last := req.Messages[len(req.Messages)-1]; last.Role == "tool" {
+			call := req.Messages[len(req.Messages)-2]
+			if len(call.ToolCalls) != 1 || string(call.ToolCalls[0].Function.Arguments) != `{"a":1,"b":2}` ||
+				call.Content != "Let me add." || last.ToolName != "add" || last.Content != "3" {
+				t.Errorf("round = %+v, %+v", call, last)
+			}
+			fmt.Fprint(w, `{"message":{"role":"assistant","content":"3"},"done":true}`+"\n")
+			return
+		}
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"Let me add."},"done":false}`+"\n")
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"add","arguments":{"a":1,"b":2}}}]},"done":false}`+"\n")
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":""},"done":true}`+"\n")
+	}
+}
+
+func geminiToolServer(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Contents []struct {
+				Role  string
+				Parts []map[string]json.RawMessage
+			}
+			Tools []struct {
+				FunctionDeclarations []struct{ Name string }
+			}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		if len(req.Tools) != 1 || len(req.Tools[0].FunctionDeclarations) != 1 || req.Tools[0].FunctionDeclarations[0].Name != "add" {
+			t.Errorf("tools = %+v", req.Tools)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		if n := len(req.Contents); n > 2 && req.Contents[n-1].Parts[0]["functionResponse"] != nil {
+			call, result := req.Contents[n-2], req.Contents[n-1]
+			if call.Role != "model" || len(call.Parts) != 2 || string(call.Parts[1]["functionCall"]) != `{"args":{"a":1,"b":2},"name":"add"}` ||
+				string(call.Parts[1]["thoughtSignature"]) != `"c2ln"` ||
+				string(result.Parts[0]["functionResponse"]) != `{"name":"add","response":{"output":"3"}}` {
+				t.Errorf("round = %+v, %+v", call, result)
+			}
+			fmt.Fprint(w, `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"3"}]}}]}`+"\n\n")
+			return
+		}
+		fmt.Fprint(w, `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Let me add."}]}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"add","args":{"a":1,"b":2}},"thoughtSignature":"c2ln"}]}}]}`+"\n\n")
+	}
+}
+
+func TestStreamTools(t *testing.T) {
+	tests := []struct {
+		config  Config
+		path    string
+		handler func(*testing.T) http.HandlerFunc
+	}{
+		{Config{Provider: "openai", APIKey: "sk-test"}, "POST /chat/completions", openAIToolServer},
+		{Config{Provider: "ollama"}, "POST /api/chat", ollamaToolServer},
+		{Config{Provider: "gemini", Model: "gemini-test", APIKey: "key"}, "POST /v1beta/models/gemini-test:streamGenerateContent", geminiToolServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.config.Provider, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.Handle(tt.path, tt.handler(t))
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+			tt.config.BaseURL = srv.URL
+			ctx := context.Background()
+
+			p, err := New(ctx, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc, ok := p.(ToolCaller)
+			if !ok {
+				t.Fatalf("%T is not a ToolCaller", p)
+			}
+
+			req := Request{Messages: conversation, Tools: []Tool{add}}
+			round, err := tc.StreamTools(ctx, req, nil)
+			if err != nil {
+				t.Fatalf("StreamTools: %v", err)
+			}
+			if round.Text != "Let me add." || len(round.Calls) != 1 || round.Calls[0].Name != "add" || string(round.Calls[0].Args) != `{"a":1,"b":2}` {
+				t.Fatalf("round = %+v", round)
+			}
+
+			round.Results = []string{"3"}
+			req.Rounds = []ToolRound{round}
+			round, err = tc.StreamTools(ctx, req, nil)
+			if err != nil || round.Text != "3" || len(round.Calls) != 0 {
+				t.Errorf("answer = %+v, %v", round, err)
+			}
+		})
+	}
+
+	srv := httptest.NewServer(ollamaToolServer(t))
+	defer srv.Close()
+	o, _ := NewOllama(Config{BaseURL: srv.URL, Model: "gemma"})
+	if _, err := o.StreamTools(context.Background(), Request{Messages: conversation, Tools: []Tool{add}}, nil); !errors.Is(err, ErrToolsUnsupported) {
+		t.Errorf("model without tools: err = %v, want ErrToolsUnsupported", err)
+	}
+}
+
+// sameJSON reports whether got and want hold the same JSON value.
+func sameJSON(got json.RawMessage, want string) bool {
+	var g, w any
+	return json.Unmarshal(got, &g) == nil && json.Unmarshal([]byte(want), &w) == nil && reflect.DeepEqual(g, w)
+}
+
+func TestImages(t *testing.T) {
+	img := Image{Name: "dot.png", MIMEType: "image/png", Data: []byte("\x89PNG")}
+	tests := []struct {
+		config      Config
+		path, reply string
+		last        func(body []byte) json.RawMessage // the turn the image goes with
+		want        string
+	}{
+		{
+			Config{Provider: "openai", APIKey: "sk-test"}, "POST /chat/completions",
+			`{"choices":[{"message":{"role":"assistant","content":"a dot"}}]}`,
+			func(body []byte) json.RawMessage {
+				var req struct{ Messages []json.RawMessage }
+				json.Unmarshal(body, &req)
+				return req.Messages[len(req.Messages)-1]
+			},
+			`{"role":"user","content":[{"type":"text","text":"Count to three"},{"type":"image_url","image_url":{"url":"data:image/png;base64,iVBORw=="}}]}`,
+		},
+		{
+			Config{Provider: "ollama"}, "POST /api/chat",
+			`{"message":{"role":"assistant","content":"a dot"},"done":true}`,
+			func(body []byte) json.RawMessage {
+				var req struct{ Messages []json.RawMessage }
+				json.Unmarshal(body, &req)
+				return req.Messages[len(req.Messages)-1]
+			},
+			`{"role":"user","content":"Count to three","images":["iVBORw=="]}`,
+		},
+		{
+			Config{Provider: "gemini", Model: "gemini-test", APIKey: "key"}, "POST /v1beta/models/gemini-test:generateContent",
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"a dot"}]}}]}`,
+			func(body []byte) json.RawMessage {
+				var req struct{ Contents []json.RawMessage }
+				json.Unmarshal(body, &req)
+				return req.Contents[len(req.Contents)-1]
+			},
+			`{"role":"user","parts":[{"text":"Count to three"},{"inlineData":{"mimeType":"image/png","data":"iVBORw=="}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.config.Provider, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc(tt.path, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if got := tt.last(body); !sameJSON(got, tt.want) {
+					t.Errorf("last turn = %s, want %s", got, tt.want)
+				}
+				fmt.Fprint(w, tt.reply)
+			})
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+			tt.config.BaseURL = srv.URL
+
+			p, err := New(context.Background(), tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := Request{Messages: conversation, Images: []Image{img}}
+			if text, err := p.Complete(context.Background(), req); err != nil || text != "a dot" {
+				t.Errorf("Complete = %q, %v", text, err)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := openAIServer(t)
+	defer srv.Close()
+	p, _ := NewOpenAI(Config{BaseURL: srv.URL, APIKey: "wrong"})
+	if _, err := p.Complete(context.Background(), request); err == nil || !strings.Contains(err.Error(), "401 Unauthorized: bad key") {
+		t.Errorf("wrong key: err = %v", err)
+	}
+
+	srv = ollamaServer(t)
+	defer srv.Close()
+	o, _ := NewOllama(Config{BaseURL: strings.TrimPrefix(srv.URL, "http://"), Model: "missing"})
+	if _, err := o.Stream(context.Background(), request, nil); err == nil || !strings.Contains(err.Error(), `model "missing" not found`) {
+		t.Errorf("missing model: err = %v", err)
+	}
+
+	if _, err := New(context.Background(), Config{Provider: "gemini"}); err == nil {
+		t.Error("gemini without a key: no error")
+	}
+	if _, err := New(context.Background(), Config{Provider: "openai"}); err == nil {
+		t.Error("openai without a key or base URL: no error")
+	}
+	if _, err := New(context.Background(), Config{Provider: "claude"}); err == nil {
+		t.Error("unknown provider: no error")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want Config
+	}{
+		{map[string]string{"GEMINI_API_KEY": "g"}, Config{Provider: "gemini", APIKey: "g"}},
+		{map[string]string{"AI_PROVIDER": "OpenAI", "OPENAI_API_KEY": "o", "GEMINI_API_KEY": "g", "AI_MODEL": "m"},
+			Config{Provider: "openai", Model: "m", APIKey: "o"}},
+		{map[string]string{"AI_PROVIDER": "ollama", "OLLAMA_HOST": "box:11434"}, Config{Provider: "ollama", BaseURL: "box:11434"}},
+		{map[string]string{"AI_PROVIDER": "ollama", "OLLAMA_HOST": "box:11434", "AI_BASE_URL": "http://gpu:11434"},
+			Config{Provider: "ollama", BaseURL: "http://gpu:11434"}},
+	}
+	for _, tt := range tests {
+		if got := ConfigFromEnv(func(k string) string { return tt.env[k] }); got != tt.want {
+			t.Errorf("ConfigFromEnv(%v) = %+v, want %+v", tt.env, got, tt.want)
+		}
+	}
+}
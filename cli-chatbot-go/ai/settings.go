@@ -0,0 +1,101 @@
+package ai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Request is what a provider is asked to reply to.
+type Request struct {
+	Messages []Message
+	Settings Settings
+	// Tools are offered to the model and Rounds are the calls it made to
+	// them for this reply so far. Only ToolCaller.StreamTools uses them.
+	Tools  []Tool
+	Rounds []ToolRound
+	// Images go with the last user message.
+	Images []Image
+	// Usage is called with the token counts the backend reports for the
+	// reply. Backends that report none do not call it.
+	Usage func(TokenUsage)
+}
+
+// TokenUsage is how many tokens a request took, as the backend counted
+// them.
+type TokenUsage struct {
+	Prompt   int
+	Response int
+}
+
+// report passes the counts the backend reported on to r.Usage, if set.
+func (r Request) report(prompt, response int) {
+	if r.Usage != nil {
+		r.Usage(TokenUsage{Prompt: prompt, Response: response})
+	}
+}
+
+// Settings tune how a reply is generated. Unset fields leave the choice to
+// the backend.
+type Settings struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+}
+
+// SettingNames lists the settings Set accepts.
+var SettingNames = []string{"temperature", "top_p", "max_tokens"}
+
+// Set changes the setting called name. The value "default" unsets it.
+func (s *Settings) Set(name, value string) error {
+	value = strings.TrimSpace(value)
+	reset := value == "default"
+	switch name {
+	case "temperature":
+		if reset {
+			s.Temperature = nil
+			return nil
+		}
+		return setFloat(&s.Temperature, name, value, 2)
+	case "top_p":
+		if reset {
+			s.TopP = nil
+			return nil
+		}
+		return setFloat(&s.TopP, name, value, 1)
+	case "max_tokens":
+		if reset {
+			s.MaxTokens = 0
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid max_tokens %q, want a positive number", value)
+		}
+		s.MaxTokens = n
+		return nil
+	}
+	return fmt.Errorf("unknown setting %q (settings: %s)", name, strings.Join(SettingNames, ", "))
+}
+
+func setFloat(f **float64, name, value string, max float64) error {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < 0 || v > max {
+		return fmt.Errorf("invalid %s %q, want a number from 0 to %g", name, value, max)
+	}
+	*f = &v
+	return nil
+}
+
+// Get returns the value of the setting called name, or "default".
+func (s Settings) Get(name string) string {
+	switch {
+	case name == "temperature" && s.Temperature != nil:
+		return strconv.FormatFloat(*s.Temperature, 'g', -1, 64)
+	case name == "top_p" && s.TopP != nil:
+		return strconv.FormatFloat(*s.TopP, 'g', -1, 64)
+	case name == "max_tokens" && s.MaxTokens > 0:
+		return strconv.Itoa(s.MaxTokens)
+	}
+	return "default"
+}
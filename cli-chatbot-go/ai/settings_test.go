@@ -0,0 +1,38 @@
+package ai
+
+import "testing"
+
+func TestSettings(t *testing.T) {
+	var s Settings
+	for _, set := range []struct{ name, value string }{
+		{"temperature", "0.7"},
+		{"top_p", "0.9"},
+		{"max_tokens", "512"},
+		{"temperature", "0"},
+	} {
+		if err := s.Set(set.name, set.value); err != nil {
+			t.Fatalf("Set(%q, %q): %v", set.name, set.value, err)
+		}
+	}
+	for name, want := range map[string]string{"temperature": "0", "top_p": "0.9", "max_tokens": "512"} {
+		if got := s.Get(name); got != want {
+			t.Errorf("Get(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if err := s.Set("top_p", "default"); err != nil || s.TopP != nil || s.Get("top_p") != "default" {
+		t.Errorf("resetting top_p: %v, %+v", err, s)
+	}
+
+	for _, bad := range []struct{ name, value string }{
+		{"temperature", "2.5"},
+		{"temperature", "warm"},
+		{"top_p", "-0.1"},
+		{"max_tokens", "0"},
+		{"seed", "42"},
+	} {
+		if err := s.Set(bad.name, bad.value); err == nil {
+			t.Errorf("Set(%q, %q): no error", bad.name, bad.value)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxToolRounds caps how many times the model may call tools for one
+// reply.
+const MaxToolRounds = 8
+
+// maxToolOutput caps the result of a call sent back to the model, in
+// bytes.
+const maxToolOutput = 16 << 10
+
+// ErrDeclined is the result of a dangerous call the user did not allow.
+var ErrDeclined = errors.New("the user did not allow this call")
+
+// ErrToolsUnsupported is returned by a ToolCaller whose model cannot call
+// tools. The reply is then asked for without them.
+var ErrToolsUnsupported = errors.New("the model does not support tools")
+
+// Tool is a local function the model can call.
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON schema of the arguments object; nil for a
+	// tool without arguments.
+	Parameters map[string]any
+	// Dangerous tools change things or run commands, so each call needs
+	// the user's go-ahead.
+	Dangerous bool
+	Run       func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// schema is the JSON schema of the arguments, never nil.
+func (t Tool) schema() map[string]any {
+	if t.Parameters == nil {
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return t.Parameters
+}
+
+// ToolCall is the model asking for a tool to be run.
+type ToolCall struct {
+	ID   string          // set by backends that match results to calls
+	Name string          // the tool
+	Args json.RawMessage // a JSON object
+
+	signature []byte // Gemini's thought signature, sent back with the call
+}
+
+// ToolRound is a turn where the model calls tools, and their results.
+// Rounds only live for the reply they lead to and are never saved.
+type ToolRound struct {
+	Text    string // what the model said along with the calls
+	Calls   []ToolCall
+	Results []string // one for each call
+}
+
+// ToolRegistry holds the tools offered to the model.
+type ToolRegistry struct {
+	tools  []Tool
+	byName map[string]int
+}
+
+// NewToolRegistry returns a registry holding tools.
+func NewToolRegistry(tools ...Tool) *ToolRegistry {
+	r := &ToolRegistry{byName: map[string]int{}}
+	for _, t := range tools {
+		r.Register(t)
+	}
+	return r
+}
+
+// Register adds t. It panics if a tool of the same name is registered.
+func (r *ToolRegistry) Register(t Tool) {
+	if _, ok := r.byName[t.Name]; ok {
+		panic("ai: tool " + t.Name + " registered twice")
+	}
+	r.byName[t.Name] = len(r.tools)
+	r.tools = append(r.tools, t)
+}
+
+// Lookup returns the tool called name.
+func (r *ToolRegistry) Lookup(name string) (Tool, bool) {
+	i, ok := r.byName[name]
+	if !ok {
+		return Tool{}, false
+	}
+	return r.tools[i], true
+}
+
+// List returns the tools in the order they were registered.
+func (r *ToolRegistry) List() []Tool {
+	return r.tools
+}
+
+// ToolUse is how the chat lets the model use tools.
+type ToolUse struct {
+	Registry *ToolRegistry
+	// Confirm asks the user whether a dangerous call may run. Without it
+	// dangerous calls are declined.
+	Confirm func(call ToolCall) bool
+	// Show is told about each call once it has run, or was declined.
+	Show func(call ToolCall, result string, err error)
+}
+
+var (
+	toolUse ToolUse
+	// noTools is the model that turned out not to support tools.
+	noTools string
+)
+
+// SetToolUse sets the tools offered to the model from now on.
+func SetToolUse(u ToolUse) {
+	toolUse = u
+}
+
+// ToolsEnabled reports whether the model is offered tools.
+func ToolsEnabled() bool {
+	if _, ok := provider.(ToolCaller); !ok {
+		return false
+	}
+	return toolUse.Registry != nil && len(toolUse.Registry.List()) > 0 && noTools != provider.Model()
+}
+
+// reply sends base and returns the model's answer, running the tools it
+// calls on the way. Text from every round is passed to onChunk, which may
+// be nil.
+func reply(ctx context.Context, base Request, onChunk func(string)) (string, error) {
+	tc, ok := provider.(ToolCaller)
+	if !ok || !ToolsEnabled() {
+		return provider.Stream(ctx, base, onChunk)
+	}
+
+	req := base
+	req.Tools = toolUse.Registry.List()
+	var text strings.Builder
+	for {
+		if len(req.Rounds) == MaxToolRounds {
+			return text.String(), fmt.Errorf("the model kept calling tools after %d rounds", MaxToolRounds)
+		}
+		turn, err := tc.StreamTools(ctx, req, onChunk)
+		if errors.Is(err, ErrToolsUnsupported) && len(req.Rounds) == 0 {
+			noTools = provider.Model()
+			return provider.Stream(ctx, base, onChunk)
+		}
+		text.WriteString(turn.Text)
+		if err != nil || len(turn.Calls) == 0 {
+			return text.String(), err
+		}
+
+		for _, call := range turn.Calls {
+			turn.Results = append(turn.Results, runTool(ctx, call))
+		}
+		req.Rounds = append(req.Rounds, turn)
+		if err := ctx.Err(); err != nil {
+			return text.String(), err
+		}
+	}
+}
+
+// runTool runs call and returns the result to send back to the model.
+// Errors are sent back too, so the model can explain or try again.
+func runTool(ctx context.Context, call ToolCall) string {
+	result, err := callTool(ctx, call)
+	if toolUse.Show != nil {
+		toolUse.Show(call, result, err)
+	}
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return result
+}
+
+func callTool(ctx context.Context, call ToolCall) (string, error) {
+	t, ok := toolUse.Registry.Lookup(call.Name)
+	if !ok {
+		return "", fmt.Errorf("there is no tool called %q", call.Name)
+	}
+	if t.Dangerous && (toolUse.Confirm == nil || !toolUse.Confirm(call)) {
+		return "", ErrDeclined
+	}
+	args := call.Args
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	out, err := t.Run(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	if len(out) > maxToolOutput {
+		out = strings.ToValidUTF8(out[:maxToolOutput], "") + "\n[output truncated]"
+	}
+	return out, nil
+}
+
+// functionTool is a tool in the format of the OpenAI API, which Ollama
+// uses too.
+type functionTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		Parameters  map[string]any `json:"parameters"`
+	} `json:"function"`
+}
+
+// callID is the ID a backend matches the result of call, the i-th call of
+// round r, by. Backends that do not set one get a made-up one.
+func callID(call ToolCall, r, i int) string {
+	if call.ID != "" {
+		return call.ID
+	}
+	return fmt.Sprintf("call_%d_%d", r, i)
+}
+
+// toolsError turns the error of a request offering tools to a model that
+// cannot call them into ErrToolsUnsupported, going by what Ollama and
+// OpenAI-compatible servers answer with.
+func toolsError(err error, req Request) error {
+	if err == nil || len(req.Tools) == 0 {
+		return err
+	}
+	if msg := err.Error(); strings.Contains(msg, "does not support tools") || strings.Contains(msg, "tool choice") {
+		return fmt.Errorf("%w: %v", ErrToolsUnsupported, err)
+	}
+	return err
+}
+
+func functionTools(tools []Tool) []functionTool {
+	out := make([]functionTool, len(tools))
+	for i, t := range tools {
+		out[i].Type = "function"
+		out[i].Function.Name = t.Name
+		out[i].Function.Description = t.Description
+		out[i].Function.Parameters = t.schema()
+	}
+	return out
+}
@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// scripted answers each StreamTools call with the next of its rounds, and
+// keeps the requests it was sent. Once out of rounds it keeps calling the
+// "echo" tool. Each round takes 10 prompt and 2 response tokens; Stream
+// reports none.
+type scripted struct {
+	rounds      []ToolRound
+	unsupported bool // StreamTools fails with ErrToolsUnsupported
+	requests    []Request
+}
+
+func (s *scripted) Name() string  { return "scripted" }
+func (s *scripted) Model() string { return "test" }
+
+func (s *scripted) Stream(ctx context.Context, req Request, onChunk func(string)) (string, error) {
+	s.requests = append(s.requests, req)
+	if onChunk != nil {
+		onChunk("no tools")
+	}
+	return "no tools", nil
+}
+
+func (s *scripted) StreamTools(ctx context.Context, req Request, onChunk func(string)) (ToolRound, error) {
+	s.requests = append(s.requests, req)
+	if s.unsupported {
+		return ToolRound{}, ErrToolsUnsupported
+	}
+	req.report(10, 2)
+	round := ToolRound{Calls: []ToolCall{{Name: "echo", Args: json.RawMessage(`{"text":"again"}`)}}}
+	if len(s.requests) <= len(s.rounds) {
+		round = s.rounds[len(s.requests)-1]
+	}
+	if onChunk != nil && round.Text != "" {
+		onChunk(round.Text)
+	}
+	return round, nil
+}
+
+func (s *scripted) Complete(ctx context.Context, req Request) (string, error) {
+	return s.Stream(ctx, req, nil)
+}
+
+func (s *scripted) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (s *scripted) CountTokens(ctx context.Context, messages []Message) (int, error) {
+	return estimateTokens(messages), nil
+}
+
+// shown is a tool call as ToolUse.Show was told about it.
+type shown struct {
+	name, result string
+	err          error
+}
+
+// useTools offers echo and a dangerous remove tool, confirming calls with
+// confirm, and returns the calls shown.
+func useTools(t *testing.T, confirm func(ToolCall) bool) *[]shown {
+	t.Cleanup(func() { toolUse, noTools = ToolUse{}, "" })
+	var calls []shown
+	SetToolUse(ToolUse{
+		Registry: NewToolRegistry(
+			Tool{
+				Name: "echo",
+				Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+					var a struct{ Text string }
+					err := json.Unmarshal(args, &a)
+					return a.Text, err
+				},
+			},
+			Tool{
+				Name:      "remove",
+				Dangerous: true,
+				Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+					return "removed", nil
+				},
+			},
+		),
+		Confirm: confirm,
+		Show: func(call ToolCall, result string, err error) {
+			calls = append(calls, shown{call.Name, result, err})
+		},
+	})
+	return &calls
+}
+
+func call(name, args string) ToolCall {
+	return ToolCall{ID: name + "-1", Name: name, Args: json.RawMessage(args)}
+}
+
+func TestToolLoop(t *testing.T) {
+	p := &scripted{rounds: []ToolRound{
+		{Text: "Let me check. ", Calls: []ToolCall{call("echo", `{"text":"hi"}`), call("missing", `{}`)}},
+		{Calls: []ToolCall{call("remove", `{"path":"x"}`)}},
+		{Text: "Done."},
+	}}
+	use(t, p, 0)
+	calls := useTools(t, nil)
+
+	var chunks string
+	err := StreamResponse(context.Background(), "Go", func(chunk string) { chunks += chunk }, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunks != "Let me check. Done." {
+		t.Errorf("chunks = %q", chunks)
+	}
+	if want := (Message{Role: RoleAssistant, Content: "Let me check. Done."}); len(history) != 2 || history[1] != want {
+		t.Errorf("history = %q", history)
+	}
+
+	if len(p.requests) != 3 {
+		t.Fatalf("%d requests, want 3", len(p.requests))
+	}
+	if names := []string{p.requests[0].Tools[0].Name, p.requests[0].Tools[1].Name}; !reflect.DeepEqual(names, []string{"echo", "remove"}) {
+		t.Errorf("tools offered = %q", names)
+	}
+	rounds := p.requests[2].Rounds
+	if len(rounds) != 2 {
+		t.Fatalf("%d rounds sent back, want 2", len(rounds))
+	}
+	if got := rounds[0].Results; len(got) != 2 || got[0] != "hi" || got[1] != `error: there is no tool called "missing"` {
+		t.Errorf("first results = %q", got)
+	}
+	if got := rounds[1].Results; len(got) != 1 || got[0] != "error: "+ErrDeclined.Error() {
+		t.Errorf("declined result = %q", got)
+	}
+
+	if len(*calls) != 3 || (*calls)[0] != (shown{"echo", "hi", nil}) || !errors.Is((*calls)[2].err, ErrDeclined) {
+		t.Errorf("shown = %+v", *calls)
+	}
+
+	// Every round counts towards the usage.
+	if u, ok := LastUsage(); !ok || u != (TokenUsage{Prompt: 30, Response: 6}) {
+		t.Errorf("LastUsage = %+v, %v, want 30 and 6 tokens", u, ok)
+	}
+}
+
+func TestToolConfirm(t *testing.T) {
+	p := &scripted{rounds: []ToolRound{
+		{Calls: []ToolCall{call("remove", `{"path":"x"}`)}},
+		{Text: "Removed."},
+	}}
+	use(t, p, 0)
+	var asked []string
+	useTools(t, func(c ToolCall) bool {
+		asked = append(asked, c.Name)
+		return true
+	})
+
+	reply, err := GetResponse(context.Background(), "Remove x")
+	if err != nil || reply != "Removed." {
+		t.Fatalf("GetResponse = %q, %v", reply, err)
+	}
+	if !reflect.DeepEqual(asked, []string{"remove"}) {
+		t.Errorf("asked about %q", asked)
+	}
+	if got := p.requests[1].Rounds[0].Results; len(got) != 1 || got[0] != "removed" {
+		t.Errorf("results = %q", got)
+	}
+}
+
+func TestToolRoundLimit(t *testing.T) {
+	p := &scripted{}
+	use(t, p, 0)
+	useTools(t, nil)
+
+	_, err := GetResponse(context.Background(), "Loop")
+	if err == nil || !strings.Contains(err.Error(), "kept calling tools") {
+		t.Errorf("err = %v, want the round limit", err)
+	}
+	if len(p.requests) != MaxToolRounds {
+		t.Errorf("%d requests, want %d", len(p.requests), MaxToolRounds)
+	}
+	if len(history) != 0 {
+		t.Errorf("history = %q, want nothing kept", history)
+	}
+}
+
+func TestToolsUnsupported(t *testing.T) {
+	p := &scripted{unsupported: true}
+	use(t, p, 0)
+	useTools(t, nil)
+
+	if !ToolsEnabled() {
+		t.Fatal("tools disabled before the model refused them")
+	}
+	reply, err := GetResponse(context.Background(), "Hi")
+	if err != nil || reply != "no tools" {
+		t.Fatalf("GetResponse = %q, %v", reply, err)
+	}
+	if ToolsEnabled() {
+		t.Error("tools still offered to a model that refused them")
+	}
+	if len(p.requests) != 2 || p.requests[1].Tools != nil {
+		t.Errorf("requests = %+v", p.requests)
+	}
+	if u, ok := LastUsage(); ok {
+		t.Errorf("LastUsage = %+v from a reply whose backend reported none", u)
+	}
+}
+
+func TestToolOutputCap(t *testing.T) {
+	t.Cleanup(func() { toolUse = ToolUse{} })
+	SetToolUse(ToolUse{Registry: NewToolRegistry(Tool{
+		Name: "big",
+		Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+			return strings.Repeat("x", maxToolOutput+10), nil
+		},
+	})})
+	out := runTool(context.Background(), ToolCall{Name: "big"})
+	if !strings.HasPrefix(out, strings.Repeat("x", maxToolOutput)+"\n") || !strings.HasSuffix(out, "[output truncated]") {
+		t.Errorf("output of %d bytes ends %q", len(out), out[len(out)-30:])
+	}
+}
@@ -0,0 +1,384 @@
+// Package attach expands the @-references in a prompt, such as @main.go
+// or @ai/, into the contents of the files and directories they name, and
+// the images among them into parts sent alongside the prompt.
+package attach
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"cli_chatbot_go/ai"
+)
+
+const (
+	// maxFile caps a file attached by name, which is cut off there; files
+	// in an attached directory that are bigger are left out.
+	maxFile = 100 << 10
+	// maxTotal caps the text attached to one prompt.
+	maxTotal = 200 << 10
+	// maxImage caps an attached image.
+	maxImage = 5 << 20
+	// maxFiles caps how many files a directory attaches.
+	maxFiles = 200
+	// sniffLen is how much of a file is looked at to tell if it is binary.
+	sniffLen = 8000
+)
+
+// header is how each attachment starts in an expanded prompt.
+const header = "\n\n<attached path="
+
+// images are the types of image attached as images rather than skipped
+// as binary files.
+var images = map[string]bool{"image/png": true, "image/jpeg": true, "image/gif": true, "image/webp": true}
+
+// ref matches an @-reference: @ at the start of a word, then a path, in
+// quotes if it has spaces.
+var ref = regexp.MustCompile(`(^|\s)@("[^"]+"|\S+)`)
+
+// Attachment sums up what a reference attached.
+type Attachment struct {
+	Ref       string // the path as written, with a slash after a directory
+	Dir       bool
+	Image     bool
+	Files     int      // files attached
+	Bytes     int      // bytes attached
+	Size      int      // size of a file that was cut off
+	Skipped   []string // files left out, with why, e.g. "app (binary file)"
+	Rest      int      // files in a directory left unread once a limit was hit
+	Truncated bool
+}
+
+// String is a compact summary, such as "main.go (3.4 KB)" or "ai/ (12
+// files, 48 KB, 3 skipped)".
+func (a Attachment) String() string {
+	switch {
+	case a.Dir:
+		s := fmt.Sprintf("%s (%s, %s", a.Ref, plural(a.Files, "file"), size(a.Bytes))
+		if n := len(a.Skipped) + a.Rest; n > 0 {
+			s += fmt.Sprintf(", %d skipped", n)
+		}
+		return s + ")"
+	case len(a.Skipped) > 0:
+		return fmt.Sprintf("%s (skipped: %s)", a.Ref, a.Skipped[0])
+	case a.Image:
+		return fmt.Sprintf("%s (image, %s)", a.Ref, size(a.Bytes))
+	case a.Truncated:
+		return fmt.Sprintf("%s (%s of %s, truncated)", a.Ref, size(a.Bytes), size(a.Size))
+	}
+	return fmt.Sprintf("%s (%s)", a.Ref, size(a.Bytes))
+}
+
+// Expansion is a prompt with its references expanded.
+type Expansion struct {
+	// Prompt is the prompt as written, followed by the text attached.
+	Prompt      string
+	Images      []ai.Image
+	Attachments []Attachment
+}
+
+// Expand attaches what the @-references in prompt name. References to
+// paths that do not exist are left as they are, so an email address or a
+// @mention does no harm, and a path referred to twice is attached once.
+func Expand(prompt string) Expansion {
+	x := expander{exp: Expansion{Prompt: prompt}, seen: map[string]bool{}}
+	for _, m := range ref.FindAllStringSubmatch(prompt, -1) {
+		x.attach(m[2])
+	}
+	if len(x.parts) > 0 {
+		x.exp.Prompt += "\n\n" + strings.Join(x.parts, "\n\n")
+	}
+	return x.exp
+}
+
+// Strip cuts the attachments off an expanded prompt, leaving what was
+// written.
+func Strip(prompt string) string {
+	if i := strings.Index(prompt, header); i >= 0 {
+		return prompt[:i]
+	}
+	return prompt
+}
+
+// expander keeps track of what has been attached to a prompt.
+type expander struct {
+	exp   Expansion
+	parts []string
+	seen  map[string]bool
+	total int // bytes of text attached
+}
+
+// attach attaches what written refers to, if it exists.
+func (x *expander) attach(written string) {
+	name, info := find(written)
+	if info == nil {
+		return
+	}
+	abs, err := filepath.Abs(home(name))
+	if err != nil || x.seen[abs] {
+		return
+	}
+	x.seen[abs] = true
+
+	if info.IsDir() {
+		x.exp.Attachments = append(x.exp.Attachments, x.dir(name, abs))
+		return
+	}
+	a := Attachment{Ref: name}
+	data, err := read(abs, maxImage)
+	switch {
+	case err != nil:
+		a.Skipped = []string{err.Error()}
+	case images[http.DetectContentType(data)]:
+		if info.Size() > maxImage {
+			a.Skipped = []string{"image over " + size(maxImage)}
+			break
+		}
+		mime := http.DetectContentType(data)
+		x.exp.Images = append(x.exp.Images, ai.Image{Name: filepath.Base(name), MIMEType: mime, Data: data})
+		x.parts = append(x.parts, fmt.Sprintf(`<attached path="%s" image="%s"/>`, slash(name), mime))
+		a.Image, a.Files, a.Bytes = true, 1, len(data)
+	case binary(data, info.Size() > maxImage):
+		a.Skipped = []string{"binary file"}
+	case x.total >= maxTotal:
+		a.Skipped = []string{"over the " + size(maxTotal) + " attached to a prompt"}
+	default:
+		text := string(data)
+		if limit := min(maxFile, maxTotal-x.total); int(info.Size()) > limit {
+			text = cut(text, limit)
+			a.Truncated, a.Size = true, int(info.Size())
+		}
+		a.Bytes = len(text)
+		a.Files = 1
+		x.total += len(text)
+		x.parts = append(x.parts, block(slash(name), text, a))
+	}
+	if len(a.Skipped) > 0 {
+		x.parts = append(x.parts, fmt.Sprintf(`<attached path="%s" skipped="%s"/>`, slash(name), a.Skipped[0]))
+	}
+	x.exp.Attachments = append(x.exp.Attachments, a)
+}
+
+// dir attaches the text files in the directory name, leaving out hidden
+// files, such as .env, what .gitignore files ignore, images and binary
+// files. Once it has attached maxFiles files, or has no room left even
+// for the tag of a skipped file, the files that remain are only counted,
+// and one tag says how many were left out.
+func (x *expander) dir(name, abs string) Attachment {
+	a := Attachment{Ref: strings.TrimSuffix(slash(name), "/") + "/", Dir: true}
+	ig := newIgnorer(abs)
+	var rest string // why the remaining files are left out, once they are
+	skip := func(p, why string) {
+		rel, _ := filepath.Rel(abs, p)
+		tag := fmt.Sprintf(`<attached path="%s" skipped="%s"/>`, a.Ref+filepath.ToSlash(rel), why)
+		// The tag takes up room too, with the blank line before it.
+		if x.total+len(tag)+2 > maxTotal {
+			rest = "over the " + size(maxTotal) + " attached to a prompt"
+			a.Rest++
+			return
+		}
+		a.Skipped = append(a.Skipped, fmt.Sprintf("%s (%s)", filepath.ToSlash(rel), why))
+		x.parts = append(x.parts, tag)
+		x.total += len(tag) + 2
+	}
+	filepath.WalkDir(abs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == abs {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || ig.ignored(p, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			ig.load(p)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if a.Files == maxFiles && rest == "" {
+			rest = fmt.Sprintf("over %d files", maxFiles)
+		}
+		if rest != "" {
+			a.Rest++
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.Size() > maxFile {
+			skip(p, "over "+size(maxFile))
+			return nil
+		}
+		data, err := read(p, maxFile)
+		switch {
+		case err != nil:
+			skip(p, err.Error())
+		case binary(data, false):
+			skip(p, "binary file")
+		case x.total+len(data) > maxTotal:
+			skip(p, "over the "+size(maxTotal)+" attached to a prompt")
+		default:
+			rel, _ := filepath.Rel(abs, p)
+			x.parts = append(x.parts, block(a.Ref+filepath.ToSlash(rel), string(data), Attachment{}))
+			x.total += len(data)
+			a.Files++
+			a.Bytes += len(data)
+		}
+		return nil
+	})
+	if a.Rest > 0 {
+		more := fmt.Sprintf("%d more files", a.Rest)
+		if a.Rest == 1 {
+			more = "1 more file"
+		}
+		tag := fmt.Sprintf(`<attached path="%s" skipped="%s: %s"/>`, a.Ref, more, rest)
+		x.parts = append(x.parts, tag)
+		x.total += len(tag) + 2
+	}
+	return a
+}
+
+// find returns the path written refers to, unquoted, and what it is, or
+// a nil info if there is none. Punctuation after a path, as in "see
+// @main.go.", is dropped when the path without it exists.
+func find(written string) (string, fs.FileInfo) {
+	quoted := len(written) >= 2 && written[0] == '"'
+	if quoted {
+		written = written[1 : len(written)-1]
+	}
+	for name := written; name != ""; name = name[:len(name)-1] {
+		if info, err := os.Stat(home(name)); err == nil {
+			return name, info
+		}
+		if quoted || !strings.ContainsAny(name[len(name)-1:], `.,;:!?)]}'"`) {
+			break
+		}
+	}
+	return "", nil
+}
+
+// home expands a leading ~/ to the home directory.
+func home(name string) string {
+	if rest, ok := strings.CutPrefix(name, "~/"); ok {
+		if dir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(dir, rest)
+		}
+	}
+	return name
+}
+
+// read reads at most limit bytes of the file at p.
+func read(p string, limit int64) ([]byte, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, limit))
+}
+
+// binary reports whether data is not text: it has a NUL byte near the
+// start, or is not UTF-8. A file read only in part may end in the middle
+// of a character.
+func binary(data []byte, part bool) bool {
+	if strings.ContainsRune(string(data[:min(len(data), sniffLen)]), 0) {
+		return true
+	}
+	for i := 0; part && i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+		data = data[:len(data)-1]
+	}
+	return !utf8.Valid(data)
+}
+
+// cut cuts text to at most limit bytes, at the end of a line if there is
+// one in the second half.
+func cut(text string, limit int) string {
+	text = text[:limit]
+	if i := strings.LastIndexByte(text, '\n'); i > limit/2 {
+		return text[:i+1]
+	}
+	for !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+	return text
+}
+
+// block is a text file as attached: its content fenced, in a tag with its
+// path.
+func block(p, text string, a Attachment) string {
+	attrs := fmt.Sprintf(`path="%s"`, p)
+	if a.Truncated {
+		attrs += fmt.Sprintf(` truncated="first %s of %s"`, size(a.Bytes), size(a.Size))
+	}
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return fmt.Sprintf("<attached %s>\n%s%s\n%s%s\n</attached>", attrs, fence, language(p), text, fence)
+}
+
+// language is the name of the language a file is in, for the fence, from
+// its extension.
+func language(p string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(p), "."))
+	switch ext {
+	case "py":
+		return "python"
+	case "js", "mjs":
+		return "javascript"
+	case "ts":
+		return "typescript"
+	case "rs":
+		return "rust"
+	case "rb":
+		return "ruby"
+	case "md":
+		return "markdown"
+	case "yml":
+		return "yaml"
+	case "h":
+		return "c"
+	case "go", "c", "cpp", "java", "json", "yaml", "toml", "sh", "html", "css", "sql", "xml":
+		return ext
+	}
+	if filepath.Base(p) == "Makefile" {
+		return "makefile"
+	}
+	return ""
+}
+
+// slash is a path with forward slashes, as the model is shown it.
+func slash(p string) string {
+	return filepath.ToSlash(p)
+}
+
+// size is a byte count for people, such as "3.4 KB".
+func size(n int) string {
+	switch {
+	case n < 1<<10:
+		return fmt.Sprintf("%d B", n)
+	case n < 1<<20:
+		return strings.Replace(fmt.Sprintf("%.1f KB", float64(n)/(1<<10)), ".0 ", " ", 1)
+	}
+	return strings.Replace(fmt.Sprintf("%.1f MB", float64(n)/(1<<20)), ".0 ", " ", 1)
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
@@ -0,0 +1,238 @@
+package attach
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// png is the start of a PNG file, enough to be recognized as one.
+const png = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+// tree creates files, given as path and content pairs, in a temporary
+// directory and makes it the working directory.
+func tree(t *testing.T, files ...string) string {
+	dir := t.TempDir()
+	for i := 0; i+1 < len(files); i += 2 {
+		p := filepath.Join(dir, filepath.FromSlash(files[i]))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(files[i+1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+func summaries(x Expansion) []string {
+	var out []string
+	for _, a := range x.Attachments {
+		out = append(out, a.String())
+	}
+	return out
+}
+
+func TestExpand(t *testing.T) {
+	tree(t,
+		"main.go", "package main\n",
+		"notes.md", "Run:\n```\ngo test\n```",
+		"my notes.txt", "hi",
+		"data.bin", "ab\x00cd",
+		"latin1.txt", "caf\xe9",
+		"shot.png", png,
+	)
+
+	tests := []struct {
+		prompt string
+		want   string   // what is appended to the prompt
+		sums   []string // the summaries
+	}{
+		{"Explain @main.go, please", "<attached path=\"main.go\">\n```go\npackage main\n```\n</attached>", []string{"main.go (13 B)"}},
+		{"@notes.md", "<attached path=\"notes.md\">\n````markdown\nRun:\n```\ngo test\n```\n````\n</attached>", []string{"notes.md (20 B)"}},
+		{`Read @"my notes.txt"`, "<attached path=\"my notes.txt\">\n```\nhi\n```\n</attached>", []string{"my notes.txt (2 B)"}},
+		{"@main.go and @./main.go", "<attached path=\"main.go\">\n```go\npackage main\n```\n</attached>", []string{"main.go (13 B)"}},
+		{"@data.bin", `<attached path="data.bin" skipped="binary file"/>`, []string{"data.bin (skipped: binary file)"}},
+		{"@latin1.txt", `<attached path="latin1.txt" skipped="binary file"/>`, []string{"latin1.txt (skipped: binary file)"}},
+		{"What is in @shot.png?", `<attached path="shot.png" image="image/png"/>`, []string{"shot.png (image, 16 B)"}},
+		{"@missing.go, mail me@example.com or ask @sam", "", nil},
+	}
+	for _, tt := range tests {
+		x := Expand(tt.prompt)
+		want := tt.prompt
+		if tt.want != "" {
+			want += "\n\n" + tt.want
+		}
+		if x.Prompt != want {
+			t.Errorf("%q: prompt =\n%s\nwant\n%s", tt.prompt, x.Prompt, want)
+		}
+		if got := summaries(x); !reflect.DeepEqual(got, tt.sums) {
+			t.Errorf("%q: summaries = %q, want %q", tt.prompt, got, tt.sums)
+		}
+		if got := Strip(x.Prompt); got != tt.prompt {
+			t.Errorf("%q: Strip = %q", tt.prompt, got)
+		}
+	}
+
+	x := Expand("@shot.png")
+	if len(x.Images) != 1 || x.Images[0].Name != "shot.png" || x.Images[0].MIMEType != "image/png" || string(x.Images[0].Data) != png {
+		t.Errorf("images = %+v", x.Images)
+	}
+	if x := Expand("@main.go"); x.Images != nil {
+		t.Errorf("images of a text file = %+v", x.Images)
+	}
+}
+
+func TestExpandDir(t *testing.T) {
+	dir := tree(t,
+		".git/HEAD", "ref: refs/heads/main\n",
+		".gitignore", "*.log\n",
+		"src/.env", "TOKEN=secret\n",
+		"src/.gitignore", "/build/\n!keep.log\n",
+		"src/a.go", "package a\n",
+		"src/b/c.txt", "c\n",
+		"src/app.log", "noise\n",
+		"src/keep.log", "kept\n",
+		"src/build/out.go", "package out\n",
+		"src/logo.png", png,
+	)
+
+	x := Expand("Review @src")
+	want := []string{
+		"<attached path=\"src/a.go\">\n```go\npackage a\n```\n</attached>",
+		"<attached path=\"src/b/c.txt\">\n```\nc\n```\n</attached>",
+		"<attached path=\"src/keep.log\">\n```\nkept\n```\n</attached>",
+		`<attached path="src/logo.png" skipped="binary file"/>`,
+	}
+	if got := strings.Split(strings.TrimPrefix(x.Prompt, "Review @src\n\n"), "\n\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("prompt parts =\n%s\nwant\n%s", strings.Join(got, "\n\n"), strings.Join(want, "\n\n"))
+	}
+	if got := summaries(x); !reflect.DeepEqual(got, []string{"src/ (3 files, 17 B, 1 skipped)"}) {
+		t.Errorf("summaries = %q", got)
+	}
+
+	// A file named outright is attached even if it is ignored.
+	if x := Expand("@src/app.log"); len(x.Attachments) != 1 || x.Attachments[0].Files != 1 {
+		t.Errorf("ignored file by name: %q", summaries(x))
+	}
+
+	// Rules above the directory apply, up to the top of the repository.
+	t.Chdir(filepath.Join(dir, "src"))
+	if x := Expand("@."); !reflect.DeepEqual(summaries(x), []string{"./ (3 files, 17 B, 1 skipped)"}) {
+		t.Errorf("from inside: %q", summaries(x))
+	}
+}
+
+func TestLimits(t *testing.T) {
+	line := strings.Repeat("x", 1023) + "\n"
+	big := strings.Repeat(line, 150)
+	tree(t,
+		"a.txt", big,
+		"b.txt", big,
+		"c.txt", "small",
+		"dir/big.txt", big,
+		"dir/ok.txt", "ok",
+	)
+
+	x := Expand("@a.txt @b.txt @c.txt @dir")
+	want := []string{
+		"a.txt (100 KB of 150 KB, truncated)",
+		"b.txt (100 KB of 150 KB, truncated)",
+		"c.txt (skipped: over the 200 KB attached to a prompt)",
+		"dir/ (0 files, 0 B, 2 skipped)",
+	}
+	if got := summaries(x); !reflect.DeepEqual(got, want) {
+		t.Errorf("summaries = %q, want %q", got, want)
+	}
+	if !strings.Contains(x.Prompt, `<attached path="a.txt" truncated="first 100 KB of 150 KB">`) {
+		t.Error("truncated file not marked")
+	}
+	// With no room left, not even a tag for each file left out.
+	if !strings.HasSuffix(x.Prompt, "\n\n"+`<attached path="dir/" skipped="2 more files: over the 200 KB attached to a prompt"/>`) {
+		t.Errorf("directory after the limit ends the prompt with\n%s", x.Prompt[len(x.Prompt)-200:])
+	}
+	if n := len(Strip(x.Prompt)); n != len("@a.txt @b.txt @c.txt @dir") {
+		t.Errorf("stripped prompt has %d bytes", n)
+	}
+}
+
+func TestManyFiles(t *testing.T) {
+	var files []string
+	for i := range maxFiles + 50 {
+		files = append(files, fmt.Sprintf("dir/%03d.txt", i), "x")
+	}
+	for i := range 5000 {
+		files = append(files, fmt.Sprintf("bin/%04d.bin", i), "\x00")
+	}
+	tree(t, files...)
+
+	x := Expand("@dir")
+	if got := summaries(x); !reflect.DeepEqual(got, []string{"dir/ (200 files, 200 B, 50 skipped)"}) {
+		t.Errorf("summaries = %q", got)
+	}
+	if n := strings.Count(x.Prompt, "skipped="); n != 1 || !strings.HasSuffix(x.Prompt, `<attached path="dir/" skipped="50 more files: over 200 files"/>`) {
+		t.Errorf("%d skipped tags, ending the prompt with\n%s", n, x.Prompt[len(x.Prompt)-200:])
+	}
+
+	// Tags for skipped files count towards the limit of a prompt.
+	x = Expand("@bin")
+	if len(x.Prompt) > maxTotal+100 {
+		t.Errorf("prompt has %d bytes, want at most about %d", len(x.Prompt), maxTotal)
+	}
+	if a := x.Attachments[0]; len(a.Skipped)+a.Rest != 5000 || a.Rest == 0 {
+		t.Errorf("%d skipped with a tag and %d without, want 5000 in all", len(a.Skipped), a.Rest)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.log", "app.log", true},
+		{"*.log", "app.go", false},
+		{"build", "build", true},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/sub/a.md", false},
+		{"**/tmp", "a/b/tmp", true},
+		{"**/tmp", "tmp", true},
+		{"a/**/z", "a/z", true},
+		{"a/**/z", "a/b/c/z", true},
+		{"a/**", "a/b/c", true},
+		{"a/**", "b/c", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v", tt.pattern, tt.name, got)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tree(t,
+		"main.go", "",
+		"models.go", "",
+		"ai/provider.go", "",
+		".env", "",
+		".git/HEAD", "",
+	)
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"@m", []string{"@main.go", "@models.go"}},
+		{"@a", []string{"@ai/"}},
+		{"@ai/", []string{"@ai/provider.go"}},
+		{"@", []string{"@ai/", "@main.go", "@models.go"}},
+		{"@.", []string{"@.env"}},
+		{"@x", nil},
+		{"main", nil},
+	}
+	for _, tt := range tests {
+		if got := Complete(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package attach
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Complete returns the paths an @-reference being typed, such as "@ai/pr",
+// can be completed to, directories with a slash after them. Names that
+// start with a dot are offered once the dot is typed; .git never is.
+func Complete(word string) []string {
+	written, ok := strings.CutPrefix(word, "@")
+	if !ok {
+		return nil
+	}
+	dir, prefix := "", written
+	if i := strings.LastIndex(written, "/"); i >= 0 {
+		dir, prefix = written[:i+1], written[i+1:]
+	}
+	read := dir
+	if read == "" {
+		read = "."
+	}
+	entries, err := os.ReadDir(home(read))
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) || name == ".git" || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		// Follow symlinks, so a link to a directory completes like one.
+		if info, err := os.Stat(filepath.Join(home(read), name)); err == nil && info.IsDir() {
+			name += "/"
+		}
+		out = append(out, "@"+dir+name)
+	}
+	return out
+}
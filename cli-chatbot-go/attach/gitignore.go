@@ -0,0 +1,155 @@
+package attach
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// rule is a line of a .gitignore file.
+type rule struct {
+	pattern  string
+	negate   bool // "!pattern" brings back what an earlier rule ignored
+	dirOnly  bool // "pattern/" matches directories only
+	anchored bool // a pattern with a slash matches from the file's directory
+}
+
+// parseIgnore reads the rules of a .gitignore file.
+func parseIgnore(data string) []rule {
+	var rules []rule
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r rule
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// matches reports whether r matches rel, a slash-separated path relative
+// to the directory of the .gitignore file.
+func (r rule) matches(rel string, dir bool) bool {
+	if r.dirOnly && !dir {
+		return false
+	}
+	if !r.anchored {
+		return matchGlob(r.pattern, path.Base(rel))
+	}
+	return matchGlob(r.pattern, rel)
+}
+
+// matchGlob matches name against a pattern in which ** stands for any
+// number of directories.
+func matchGlob(pattern, name string) bool {
+	return matchParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(name); i >= 0; i-- {
+				if matchParts(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignorer tells which files the .gitignore files around a directory
+// leave out.
+type ignorer struct {
+	dirs  []string // directories with rules, outermost first
+	rules map[string][]rule
+}
+
+// newIgnorer reads the .gitignore files in root and in the directories
+// above it, up to the top of the git repository it is in.
+func newIgnorer(root string) *ignorer {
+	ig := &ignorer{rules: map[string][]rule{}}
+	var above []string
+	for dir := root; ; {
+		above = append(above, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Not in a repository: only the directory's own rules apply.
+			above = above[:1]
+			break
+		}
+		dir = parent
+	}
+	for i := len(above) - 1; i >= 0; i-- {
+		ig.load(above[i])
+	}
+	return ig
+}
+
+// load reads the .gitignore file in dir, if there is one.
+func (ig *ignorer) load(dir string) {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	if rules := parseIgnore(string(data)); len(rules) > 0 {
+		ig.dirs = append(ig.dirs, dir)
+		ig.rules[dir] = rules
+	}
+}
+
+// ignored reports whether p is left out. The last rule that matches
+// decides, and rules in deeper directories come later.
+func (ig *ignorer) ignored(p string, dir bool) bool {
+	if filepath.Base(p) == ".git" {
+		return true
+	}
+	ignored := false
+	for _, d := range ig.dirs {
+		rel, err := filepath.Rel(d, p)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, r := range ig.rules[d] {
+			if r.matches(rel, dir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"cli_chatbot_go/ai"
+	"cli_chatbot_go/attach"
+	"cli_chatbot_go/commands"
+	"cli_chatbot_go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// historyLength is how many messages /history shows by default.
+const historyLength = 10
+
+// historyWidth caps each message in /history.
+const historyWidth = 200
+
+var (
+	registry   *commands.Registry
+	interrupts chan os.Signal
+)
+
+// newRegistry registers the built-in commands.
+func newRegistry() *commands.Registry {
+	r := commands.NewRegistry()
+	r.Register(
+		&commands.Func{Names: []string{"/help", "help"}, Help: "Show the commands", Handler: helpCommand},
+		&commands.Func{Names: []string{"/clear", "clear"}, Help: "Clear the screen", Handler: clearCommand},
+		&commands.Func{Names: []string{"/exit", "exit", "/quit"}, Help: "Leave the chat", Handler: exitCommand},
+
+		&commands.Func{Names: []string{"/sessions"}, Help: "List saved sessions", Handler: sessionsCommand},
+		&commands.Func{Names: []string{"/save"}, Args: "[name]", MaxArgs: 1, Help: "Save the session, optionally under a new name", Handler: saveCommand},
+		&commands.Func{Names: []string{"/load"}, Args: "<name>", MinArgs: 1, MaxArgs: 1, Help: "Switch to a saved session", Handler: loadCommand},
+		&commands.Func{Names: []string{"/rename"}, Args: "<name>", MinArgs: 1, MaxArgs: 1, Help: "Rename the session", Handler: renameCommand},
+
+		&commands.Func{Names: []string{"/context"}, Help: "Show how much of the token budget the chat uses", Handler: contextCommand},
+		&commands.Func{Names: []string{"/persona"}, Args: "[name]", MaxArgs: 1, Help: "List personas or switch to one", Handler: personaCommand},
+		&commands.Func{Names: []string{"/set"}, Args: "[<setting> <value>]", MaxArgs: 2, Help: "Show or change temperature, top_p and max_tokens", Handler: setCommand},
+		&commands.Func{Names: []string{"/model"}, Args: "[list|<name>]", MaxArgs: 1, Help: "Show, list or switch the model", Handler: modelCommand},
+		&commands.Func{Names: []string{"/tools"}, Help: "List the tools the model can call", Handler: toolsCommand},
+
+		&commands.Func{Names: []string{"/history"}, Args: "[n]", MaxArgs: 1, Help: "Show the last messages of the conversation", Handler: historyCommand},
+		&commands.Func{Names: []string{"/retry"}, Help: "Ask the last question again", Handler: retryCommand},
+		&commands.Func{Names: []string{"/edit-last"}, Args: "<text>", MinArgs: 1, MaxArgs: -1, Help: "Replace the last question and ask it", Handler: editLastCommand},
+		&commands.Func{Names: []string{"/undo"}, Help: "Forget the last question and its reply", Handler: undoCommand},
+		&commands.Func{Names: []string{"/copy-last"}, Help: "Copy the last reply to the clipboard", Handler: copyLastCommand},
+	)
+	return r
+}
+
+// runCommand runs input if it is a command, and reports whether it was
+// and whether the chat should end.
+func runCommand(input string) (handled, exit bool) {
+	out, ok, err := registry.Run(context.Background(), input)
+	if !ok {
+		if commands.IsCommand(input) {
+			printMessage(registry.Suggest(input), true)
+			return true, false
+		}
+		return false, false
+	}
+	switch {
+	case errors.Is(err, commands.ErrExit):
+		return true, true
+	case err != nil:
+		printMessage(fmt.Sprintf("%s%s%s", colorOrange, err, colorReset), true)
+	case out != "":
+		printMessage(out, true)
+	}
+	return true, false
+}
+
+// completeInput completes what is typed at the prompt for Tab: an
+// @-reference to a path, or the name of a slash command.
+func completeInput(text string) []string {
+	start := strings.LastIndexAny(text, " \t\n") + 1
+	if word := text[start:]; strings.HasPrefix(word, "@") {
+		var out []string
+		for _, c := range attach.Complete(word) {
+			out = append(out, text[:start]+c)
+		}
+		return out
+	}
+	return completeCommand(text)
+}
+
+// completeCommand completes the name of a slash command for Tab.
+func completeCommand(text string) []string {
+	if strings.ContainsRune(text, ' ') || !commands.IsCommand(text) {
+		return nil
+	}
+	return registry.Complete(text)
+}
+
+func helpCommand(ctx context.Context, args []string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%sCommands%s\n", bold, colorReset)
+	for _, line := range strings.Split(registry.Help(), "\n") {
+		fmt.Fprintf(&b, "\n  %s", line)
+	}
+	fmt.Fprintf(&b, "\n\n  %s%sinfo  time  joke  quote%s also answer without the AI.", bold, colorGreen, colorReset)
+	b.WriteString("\n  💡 Tip: When AI is enabled, you can ask me anything!")
+	return b.String(), nil
+}
+
+func clearCommand(ctx context.Context, args []string) (string, error) {
+	printWelcome()
+	return "", nil
+}
+
+func exitCommand(ctx context.Context, args []string) (string, error) {
+	return "", commands.ErrExit
+}
+
+// modelCommand runs /model [list|<name>].
+func modelCommand(ctx context.Context, args []string) (string, error) {
+	p := ai.Current()
+	if p == nil {
+		return "", errors.New("AI is not enabled")
+	}
+	if len(args) == 0 {
+		return fmt.Sprintf("Model: %s%s%s (%s)\n   /model list shows the others, /model <name> switches.", bold, p.Model(), colorReset, p.Name()), nil
+	}
+	if args[0] == "list" {
+		models, err := ai.Models(ctx)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Models on %s", p.Name())
+		for _, m := range models {
+			fmt.Fprintf(&b, "\n  %s•%s %s", colorGreen, colorReset, m)
+			if m == p.Model() {
+				fmt.Fprintf(&b, " %s(current)%s", colorPurple, colorReset)
+			}
+		}
+		return b.String(), nil
+	}
+	if err := ai.SwitchModel(ctx, args[0]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Switched to %s%s%s. The conversation so far is kept.", bold, args[0], colorReset), nil
+}
+
+// historyCommand runs /history [n].
+func historyCommand(ctx context.Context, args []string) (string, error) {
+	n := historyLength
+	if len(args) == 1 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			return "", fmt.Errorf("invalid number of messages %q", args[0])
+		}
+	}
+	history := ai.History()
+	if len(history) == 0 {
+		return "No messages yet.", nil
+	}
+	first := max(0, len(history)-n)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Last %d of %d messages", len(history)-first, len(history))
+	for i, m := range history[first:] {
+		who := "You"
+		switch {
+		case ai.IsSummary(m):
+			who = "Summary"
+		case m.Role == ai.RoleAssistant:
+			who = "Assistant"
+		}
+		text := strings.Join(strings.Fields(attach.Strip(m.Content)), " ")
+		if r := []rune(text); len(r) > historyWidth {
+			text = string(r[:historyWidth-3]) + "..."
+		}
+		fmt.Fprintf(&b, "\n  %s%3d%s %s%s:%s %s", dim, first+i+1, colorReset, bold, who, colorReset, text)
+	}
+	return b.String(), nil
+}
+
+// retryCommand runs /retry, asking the last question again.
+func retryCommand(ctx context.Context, args []string) (string, error) {
+	prompt, ok := ai.Undo()
+	if !ok {
+		return "", errors.New("nothing to retry yet")
+	}
+	respond(attach.Strip(prompt))
+	return "", nil
+}
+
+// editLastCommand runs /edit-last <text>, replacing the last question.
+func editLastCommand(ctx context.Context, args []string) (string, error) {
+	if _, ok := ai.Undo(); !ok {
+		return "", errors.New("nothing to edit yet")
+	}
+	respond(args[0])
+	return "", nil
+}
+
+// undoCommand runs /undo.
+func undoCommand(ctx context.Context, args []string) (string, error) {
+	prompt, ok := ai.Undo()
+	if !ok {
+		return "", errors.New("nothing to undo")
+	}
+	saveExchange()
+	return fmt.Sprintf("Forgot %s%q%s and its reply.", dim, attach.Strip(prompt), colorReset), nil
+}
+
+// copyLastCommand runs /copy-last.
+func copyLastCommand(ctx context.Context, args []string) (string, error) {
+	_, reply, _ := ai.LastExchange()
+	if reply == "" {
+		return "", errors.New("no reply to copy yet")
+	}
+	by, err := utils.CopyToClipboard(reply, os.Stdout)
+	if err != nil {
+		return "", fmt.Errorf("could not copy: %w", err)
+	}
+	return fmt.Sprintf("Copied the last reply (%d characters) with %s.", len([]rune(reply)), by), nil
+}
@@ -0,0 +1,203 @@
+// Package commands is the registry of slash commands. Each command
+// describes itself, so help and completion follow from what is registered.
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Command is something the user can run by typing its name.
+type Command interface {
+	// Name is what the user types, with its leading slash, e.g. "/load".
+	Name() string
+	// Aliases are other names for the command. An alias without a slash
+	// lets the bare word run the command, as "exit" does.
+	Aliases() []string
+	// Usage describes the arguments, e.g. "<name>", or is empty.
+	Usage() string
+	// Summary is a one-line description for help.
+	Summary() string
+	// Parse splits the text after the name into arguments, or returns a
+	// UsageError when it does not fit the command.
+	Parse(args string) ([]string, error)
+	// Run executes the command and returns the text to show, if any.
+	Run(ctx context.Context, args []string) (string, error)
+}
+
+// ErrExit is returned by a command that ends the program.
+var ErrExit = errors.New("exit")
+
+// UsageError reports arguments a command cannot take.
+type UsageError struct {
+	Command Command
+}
+
+func (e *UsageError) Error() string {
+	return "Usage: " + strings.TrimSpace(e.Command.Name()+" "+e.Command.Usage())
+}
+
+// Func is a Command built from its fields.
+type Func struct {
+	Names   []string // name first, then aliases
+	Args    string   // usage of the arguments
+	Help    string   // one-line summary
+	MinArgs int
+	// MaxArgs caps the arguments; -1 takes the rest of the line as the
+	// last argument, spaces included.
+	MaxArgs int
+	Handler func(ctx context.Context, args []string) (string, error)
+}
+
+func (f *Func) Name() string      { return f.Names[0] }
+func (f *Func) Aliases() []string { return f.Names[1:] }
+func (f *Func) Usage() string     { return f.Args }
+func (f *Func) Summary() string   { return f.Help }
+
+// Parse splits args on white space and checks their number.
+func (f *Func) Parse(args string) ([]string, error) {
+	var fields []string
+	if f.MaxArgs < 0 {
+		fields = splitRest(args, max(f.MinArgs, 1))
+	} else {
+		fields = strings.Fields(args)
+	}
+	if len(fields) < f.MinArgs || (f.MaxArgs >= 0 && len(fields) > f.MaxArgs) {
+		return nil, &UsageError{f}
+	}
+	return fields, nil
+}
+
+// splitRest splits s into at most n fields, the last one holding the rest
+// of s as typed.
+func splitRest(s string, n int) []string {
+	var fields []string
+	s = strings.TrimSpace(s)
+	for s != "" && len(fields) < n-1 {
+		field, rest, _ := strings.Cut(s, " ")
+		fields = append(fields, field)
+		s = strings.TrimSpace(rest)
+	}
+	if s != "" {
+		fields = append(fields, s)
+	}
+	return fields
+}
+
+func (f *Func) Run(ctx context.Context, args []string) (string, error) {
+	return f.Handler(ctx, args)
+}
+
+// Registry holds the commands by name and alias.
+type Registry struct {
+	commands []Command
+	byName   map[string]Command
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: map[string]Command{}}
+}
+
+// Register adds commands. It panics when a name is taken, as that is a
+// programming error.
+func (r *Registry) Register(commands ...Command) {
+	for _, c := range commands {
+		for _, name := range append([]string{c.Name()}, c.Aliases()...) {
+			if _, taken := r.byName[name]; taken {
+				panic(fmt.Sprintf("commands: %s registered twice", name))
+			}
+			r.byName[name] = c
+		}
+		r.commands = append(r.commands, c)
+	}
+}
+
+// Commands returns the registered commands sorted by name.
+func (r *Registry) Commands() []Command {
+	sorted := append([]Command(nil), r.commands...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+	return sorted
+}
+
+// Lookup returns the command input runs and the text of its arguments.
+// Slash commands take arguments; bare aliases only match the whole input.
+func (r *Registry) Lookup(input string) (Command, string, bool) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "/") {
+		c, ok := r.byName[strings.ToLower(input)]
+		return c, "", ok
+	}
+	name, args, _ := strings.Cut(input, " ")
+	c, ok := r.byName[strings.ToLower(name)]
+	return c, strings.TrimSpace(args), ok
+}
+
+// IsCommand reports whether input looks like a slash command, known or not.
+func IsCommand(input string) bool {
+	return strings.HasPrefix(strings.TrimSpace(input), "/")
+}
+
+// Run parses and runs the command input names. It reports false when
+// input is not a registered command.
+func (r *Registry) Run(ctx context.Context, input string) (string, bool, error) {
+	c, args, ok := r.Lookup(input)
+	if !ok {
+		return "", false, nil
+	}
+	parsed, err := c.Parse(args)
+	if err != nil {
+		return "", true, err
+	}
+	out, err := c.Run(ctx, parsed)
+	return out, true, err
+}
+
+// Complete returns the command names and aliases starting with prefix, in
+// order. Only slash names are offered.
+func (r *Registry) Complete(prefix string) []string {
+	var names []string
+	for name := range r.byName {
+		if strings.HasPrefix(name, "/") && strings.HasPrefix(name, strings.ToLower(prefix)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Suggest returns a hint for an unknown command, naming the commands its
+// name is a prefix of, if any.
+func (r *Registry) Suggest(input string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(input), " ")
+	msg := fmt.Sprintf("Unknown command %s.", name)
+	if names := r.Complete(name); len(names) > 0 {
+		msg += " Did you mean " + strings.Join(names, ", ") + "?"
+	}
+	return msg + " Type /help for the list."
+}
+
+// Help lists the commands with their usage and summary, aligned.
+func (r *Registry) Help() string {
+	commands := r.Commands()
+	usages := make([]string, len(commands))
+	width := 0
+	for i, c := range commands {
+		usages[i] = strings.TrimSpace(c.Name() + " " + c.Usage())
+		width = max(width, len(usages[i]))
+	}
+	var b strings.Builder
+	for i, c := range commands {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%-*s  %s", width, usages[i], c.Summary())
+		if aliases := c.Aliases(); len(aliases) > 0 {
+			fmt.Fprintf(&b, " (also %s)", strings.Join(aliases, ", "))
+		}
+	}
+	return b.String()
+}
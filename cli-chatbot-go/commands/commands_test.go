@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// echo returns its arguments joined by "|".
+func echo(ctx context.Context, args []string) (string, error) {
+	return strings.Join(args, "|"), nil
+}
+
+func registry() *Registry {
+	r := NewRegistry()
+	r.Register(
+		&Func{Names: []string{"/exit", "exit", "/quit"}, Help: "Leave", Handler: func(context.Context, []string) (string, error) { return "", ErrExit }},
+		&Func{Names: []string{"/load"}, Args: "<name>", MinArgs: 1, MaxArgs: 1, Help: "Load a session", Handler: echo},
+		&Func{Names: []string{"/edit-last"}, Args: "<text>", MinArgs: 1, MaxArgs: -1, Help: "Edit", Handler: echo},
+		&Func{Names: []string{"/set"}, Args: "[<setting> <value>]", MaxArgs: 2, Help: "Settings", Handler: echo},
+		&Func{Names: []string{"/sessions"}, Help: "List sessions", Handler: echo},
+	)
+	return r
+}
+
+func TestRun(t *testing.T) {
+	r := registry()
+	tests := []struct {
+		input   string
+		out     string
+		handled bool
+		err     string
+	}{
+		{"/load work", "work", true, ""},
+		{"/LOAD  work ", "work", true, ""},
+		{"/load", "", true, "Usage: /load <name>"},
+		{"/load a b", "", true, "Usage: /load <name>"},
+		{"/edit-last  what is  a monad? ", "what is  a monad?", true, ""},
+		{"/edit-last", "", true, "Usage: /edit-last <text>"},
+		{"/set temperature 0.2", "temperature|0.2", true, ""},
+		{"/set", "", true, ""},
+		{"/sessions now", "", true, "Usage: /sessions"},
+		{"exit", "", true, "exit"},
+		{"/quit", "", true, "exit"},
+		{"exit now", "", false, ""},
+		{"load work", "", false, ""},
+		{"/unknown", "", false, ""},
+		{"hello", "", false, ""},
+	}
+	for _, tt := range tests {
+		out, handled, err := r.Run(context.Background(), tt.input)
+		if out != tt.out || handled != tt.handled || (err == nil) != (tt.err == "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("Run(%q) = %q, %v, %v, want %q, %v, %q", tt.input, out, handled, err, tt.out, tt.handled, tt.err)
+		}
+	}
+
+	_, _, err := r.Run(context.Background(), "/load")
+	var usage *UsageError
+	if !errors.As(err, &usage) || usage.Command.Name() != "/load" {
+		t.Errorf("err = %#v, want a UsageError for /load", err)
+	}
+	if _, _, err := r.Run(context.Background(), "exit"); !errors.Is(err, ErrExit) {
+		t.Errorf("exit: err = %v", err)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	r := registry()
+	for prefix, want := range map[string][]string{
+		"/se": {"/sessions", "/set"},
+		"/q":  {"/quit"},
+		"/":   {"/edit-last", "/exit", "/load", "/quit", "/sessions", "/set"},
+		"/x":  nil,
+		"ex":  nil,
+	} {
+		if got := r.Complete(prefix); !reflect.DeepEqual(got, want) {
+			t.Errorf("Complete(%q) = %q, want %q", prefix, got, want)
+		}
+	}
+
+	if got := r.Suggest("/se foo"); got != "Unknown command /se. Did you mean /sessions, /set? Type /help for the list." {
+		t.Errorf("Suggest = %q", got)
+	}
+	if got := r.Suggest("/nope"); got != "Unknown command /nope. Type /help for the list." {
+		t.Errorf("Suggest = %q", got)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	want := strings.Join([]string{
+		"/edit-last <text>         Edit",
+		"/exit                     Leave (also exit, /quit)",
+		"/load <name>              Load a session",
+		"/sessions                 List sessions",
+		"/set [<setting> <value>]  Settings",
+	}, "\n")
+	if got := registry().Help(); got != want {
+		t.Errorf("Help =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering /quit twice did not panic")
+		}
+	}()
+	registry().Register(&Func{Names: []string{"/bye", "/quit"}, Handler: echo})
+}
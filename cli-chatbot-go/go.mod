@@ -4,6 +4,8 @@ go 1.24.0
 
 require (
 	github.com/joho/godotenv v1.5.1
+	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/term v0.36.0
 	google.golang.org/genai v1.32.0
 )
 
@@ -0,0 +1,128 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultHistorySize is how many lines a History keeps when Max is not set.
+const DefaultHistorySize = 1000
+
+// History is the lines entered at the prompt, oldest first. When it has a
+// file, each line is appended to it as it is added, so the history
+// survives the program and a crash.
+type History struct {
+	Max     int // lines kept; 0 means DefaultHistorySize
+	path    string
+	entries []string
+}
+
+// LoadHistory reads the history kept in path. A missing file is not an
+// error; it is created when the first line is added.
+func LoadHistory(path string, max int) (*History, error) {
+	h := &History{Max: max, path: path}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if line := unescape(scanner.Text()); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return h, err
+	}
+	if len(h.entries) > h.max() {
+		h.entries = h.entries[len(h.entries)-h.max():]
+		return h, h.rewrite()
+	}
+	return h, nil
+}
+
+func (h *History) max() int {
+	if h.Max <= 0 {
+		return DefaultHistorySize
+	}
+	return h.Max
+}
+
+// Len is the number of lines in the history.
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// At returns line i, counting from the oldest.
+func (h *History) At(i int) string {
+	return h.entries[i]
+}
+
+// Add appends line unless it is blank or repeats the last line.
+func (h *History) Add(line string) error {
+	if strings.TrimSpace(line) == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == line) {
+		return nil
+	}
+	h.entries = append(h.entries, line)
+	if len(h.entries) > h.max() {
+		h.entries = h.entries[len(h.entries)-h.max():]
+	}
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(escape(line) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rewrite replaces the file with the lines in memory.
+func (h *History) rewrite() error {
+	var b strings.Builder
+	for _, line := range h.entries {
+		b.WriteString(escape(line) + "\n")
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// escape keeps a multi-line entry on one line of the file.
+func escape(line string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(line)
+}
+
+func unescape(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) {
+			i++
+			if line[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(line[i])
+	}
+	return b.String()
+}
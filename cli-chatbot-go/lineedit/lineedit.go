@@ -0,0 +1,733 @@
+// Package lineedit reads lines at a terminal prompt with emacs-style
+// editing, history recall, reverse search and multi-line input. When the
+// input is not a terminal it reads plain lines instead.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ErrInterrupted is returned by ReadLine when Ctrl-C is pressed.
+var ErrInterrupted = errors.New("interrupted")
+
+// Fence starts and ends a block of lines that is read as one input: a
+// line that begins with it keeps Enter inserting new lines until a line
+// that is only the fence.
+const Fence = `"""`
+
+// Control keys.
+const (
+	ctrlA     = 1
+	ctrlB     = 2
+	ctrlC     = 3
+	ctrlD     = 4
+	ctrlE     = 5
+	ctrlF     = 6
+	ctrlG     = 7
+	ctrlH     = 8
+	tab       = 9
+	ctrlJ     = 10
+	ctrlK     = 11
+	ctrlL     = 12
+	enter     = 13
+	ctrlN     = 14
+	ctrlP     = 16
+	ctrlR     = 18
+	ctrlT     = 20
+	ctrlU     = 21
+	ctrlW     = 23
+	ctrlY     = 25
+	esc       = 27
+	backspace = 127
+)
+
+// Keys that arrive as escape sequences are given values past the last
+// Unicode code point.
+const (
+	keyUp rune = unicode.MaxRune + 1 + iota
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+	keyWordLeft
+	keyWordRight
+	keyKillWord
+	keyBackwardKillWord
+	keyNewline
+	keyPaste
+	keyUnknown
+)
+
+const (
+	bracketedPasteOn  = "\033[?2004h"
+	bracketedPasteOff = "\033[?2004l"
+	pasteEnd          = "\033[201~"
+)
+
+// tabWidth is how many columns a tab in the input is shown as.
+const tabWidth = 4
+
+// Editor reads lines from a terminal.
+type Editor struct {
+	// Prompt is shown before the input and may contain color codes.
+	Prompt string
+	// ContinuationPrompt is shown before each further line of
+	// multi-line input.
+	ContinuationPrompt string
+	// History is recalled with the arrow keys and Ctrl-R, and gets each
+	// line read from the terminal. It may be nil.
+	History *History
+	// Complete returns what the text before the cursor can be completed
+	// to with Tab, each candidate replacing that text. It may be nil. A
+	// space follows a single candidate, unless it ends in a slash, as a
+	// directory does.
+	Complete func(text string) []string
+
+	fd       int
+	terminal bool
+	in       *bufio.Reader
+	out      io.Writer
+	killed   []rune // text removed by the last kill, for Ctrl-Y
+}
+
+// New returns an Editor reading from in and drawing on out. It edits lines
+// only when in is a terminal that understands escape sequences.
+func New(in *os.File, out io.Writer) *Editor {
+	fd := int(in.Fd())
+	return &Editor{
+		fd:       fd,
+		terminal: term.IsTerminal(fd) && os.Getenv("TERM") != "dumb",
+		in:       bufio.NewReader(in),
+		out:      out,
+	}
+}
+
+// Terminal reports whether lines are edited, rather than read as they
+// arrive.
+func (e *Editor) Terminal() bool {
+	return e.terminal
+}
+
+// ReadLine shows the prompt and returns the next input, without the fence
+// lines around a multi-line block. It returns io.EOF at the end of input,
+// or when Ctrl-D is pressed on an empty line.
+func (e *Editor) ReadLine() (string, error) {
+	if !e.terminal {
+		return e.readPlain()
+	}
+	state, err := term.MakeRaw(e.fd)
+	if err != nil {
+		return e.readPlain()
+	}
+	defer term.Restore(e.fd, state)
+	fmt.Fprint(e.out, bracketedPasteOn)
+	defer fmt.Fprint(e.out, bracketedPasteOff)
+
+	text, err := e.edit()
+	if err != nil {
+		return "", err
+	}
+	if e.History != nil {
+		// Failing to save the history should not lose the line.
+		_ = e.History.Add(strings.TrimSpace(text))
+	}
+	return unfence(text), nil
+}
+
+// Ask shows prompt and reads a line that is neither recalled from the
+// history nor added to it, such as the answer to a question.
+func (e *Editor) Ask(prompt string) (string, error) {
+	a := *e
+	a.Prompt, a.History, a.Complete = prompt, nil, nil
+	return a.ReadLine()
+}
+
+// readPlain reads a line, or a fenced block of lines, without editing.
+func (e *Editor) readPlain() (string, error) {
+	fmt.Fprint(e.out, e.Prompt)
+	var lines []string
+	for {
+		line, err := e.in.ReadString('\n')
+		if line == "" && err != nil {
+			if len(lines) > 0 && errors.Is(err, io.EOF) {
+				break
+			}
+			return "", err
+		}
+		lines = append(lines, strings.TrimRight(line, "\r\n"))
+		if err != nil || !fenceOpen(strings.Join(lines, "\n")) {
+			break
+		}
+	}
+	return unfence(strings.Join(lines, "\n")), nil
+}
+
+// line is the input being edited.
+type line struct {
+	buf   []rune
+	pos   int    // cursor, as an index into buf
+	hist  int    // history entry shown; History.Len() is the new line
+	draft []rune // the new line, kept while an older entry is shown
+	row   int    // row of the cursor, counted from the prompt
+}
+
+// edit runs the editing keys until Enter, and returns the text entered.
+func (e *Editor) edit() (string, error) {
+	l := &line{hist: e.historyLen()}
+	e.draw(l, e.Prompt, l.buf, l.pos)
+	for {
+		r, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+		if r == ctrlR {
+			if r, err = e.search(l); err != nil {
+				return "", err
+			}
+		}
+
+		switch r {
+		case enter, ctrlJ:
+			if fenceOpen(string(l.buf)) {
+				l.insert([]rune{'\n'})
+				break
+			}
+			e.finish(l, "")
+			return string(l.buf), nil
+		case keyNewline:
+			l.insert([]rune{'\n'})
+		case ctrlC:
+			e.finish(l, "^C")
+			return "", ErrInterrupted
+		case ctrlD:
+			if len(l.buf) == 0 {
+				e.finish(l, "")
+				return "", io.EOF
+			}
+			l.delete(l.pos, l.pos+1)
+		case keyDelete:
+			l.delete(l.pos, l.pos+1)
+		case backspace, ctrlH:
+			l.delete(l.pos-1, l.pos)
+		case ctrlA, keyHome:
+			l.pos = l.lineStart()
+		case ctrlE, keyEnd:
+			l.pos = l.lineEnd()
+		case ctrlB, keyLeft:
+			l.pos = max(0, l.pos-1)
+		case ctrlF, keyRight:
+			l.pos = min(len(l.buf), l.pos+1)
+		case keyWordLeft:
+			l.pos = l.wordLeft()
+		case keyWordRight:
+			l.pos = l.wordRight()
+		case ctrlK:
+			end := l.lineEnd()
+			if end == l.pos && end < len(l.buf) {
+				end++ // join the next line
+			}
+			e.kill(l, l.pos, end)
+		case ctrlU:
+			e.kill(l, l.lineStart(), l.pos)
+		case ctrlW:
+			start := l.pos
+			for start > 0 && unicode.IsSpace(l.buf[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(l.buf[start-1]) {
+				start--
+			}
+			e.kill(l, start, l.pos)
+		case keyBackwardKillWord:
+			e.kill(l, l.wordLeft(), l.pos)
+		case keyKillWord:
+			e.kill(l, l.pos, l.wordRight())
+		case ctrlY:
+			l.insert(e.killed)
+		case ctrlT:
+			if l.pos > 0 && len(l.buf) > 1 {
+				if l.pos == len(l.buf) {
+					l.pos--
+				}
+				l.buf[l.pos-1], l.buf[l.pos] = l.buf[l.pos], l.buf[l.pos-1]
+				l.pos++
+			}
+		case ctrlP, keyUp:
+			if l.lineStart() > 0 {
+				l.moveLine(-1)
+			} else {
+				e.recall(l, l.hist-1)
+			}
+		case ctrlN, keyDown:
+			if l.lineEnd() < len(l.buf) {
+				l.moveLine(1)
+			} else {
+				e.recall(l, l.hist+1)
+			}
+		case ctrlL:
+			fmt.Fprint(e.out, "\033[H\033[2J")
+			l.row = 0
+		case tab:
+			if e.Complete == nil || strings.ContainsRune(string(l.buf), '\n') {
+				l.insert([]rune{'\t'})
+				break
+			}
+			e.complete(l)
+		case keyPaste:
+			text, err := e.readPaste()
+			if err != nil {
+				return "", err
+			}
+			l.insert(text)
+		case ctrlG, keyUnknown:
+		default:
+			if r >= ' ' && r <= unicode.MaxRune {
+				l.insert([]rune{r})
+			}
+		}
+		e.draw(l, e.Prompt, l.buf, l.pos)
+	}
+}
+
+// finish moves below the input, after showing mark at its end.
+func (e *Editor) finish(l *line, mark string) {
+	e.draw(l, e.Prompt, l.buf, len(l.buf))
+	fmt.Fprint(e.out, mark+"\r\n")
+}
+
+func (e *Editor) historyLen() int {
+	if e.History == nil {
+		return 0
+	}
+	return e.History.Len()
+}
+
+// recall shows history entry i in place of the line.
+func (e *Editor) recall(l *line, i int) {
+	if i < 0 || i > e.historyLen() || i == l.hist {
+		return
+	}
+	if l.hist == e.historyLen() {
+		l.draft = l.buf
+	}
+	l.hist = i
+	if i == e.historyLen() {
+		l.buf = l.draft
+	} else {
+		l.buf = []rune(e.History.At(i))
+	}
+	l.pos = len(l.buf)
+}
+
+// kill removes buf[start:end] and keeps it for Ctrl-Y.
+func (e *Editor) kill(l *line, start, end int) {
+	if start >= end {
+		return
+	}
+	e.killed = append([]rune(nil), l.buf[start:end]...)
+	l.delete(start, end)
+}
+
+// complete replaces the text before the cursor with what it completes to,
+// or lists the candidates when they have nothing more in common.
+func (e *Editor) complete(l *line) {
+	head := string(l.buf[:l.pos])
+	candidates := e.Complete(head)
+	switch len(candidates) {
+	case 0:
+		fmt.Fprint(e.out, "\a")
+		return
+	case 1:
+		l.replaceHead(candidates[0])
+		if strings.HasSuffix(candidates[0], "/") {
+			return
+		}
+		if l.pos == len(l.buf) || l.buf[l.pos] != ' ' {
+			l.insert([]rune{' '})
+		}
+		return
+	}
+	prefix := commonPrefix(candidates)
+	if len(prefix) > len(head) {
+		l.replaceHead(prefix)
+		return
+	}
+	// List the last words only, leaving out the words they share.
+	shared := strings.LastIndex(prefix, " ") + 1
+	words := make([]string, len(candidates))
+	for i, c := range candidates {
+		words[i] = c[shared:]
+	}
+	e.draw(l, e.Prompt, l.buf, len(l.buf))
+	fmt.Fprint(e.out, "\r\n"+strings.Join(words, "  ")+"\r\n")
+	l.row = 0
+}
+
+// search runs Ctrl-R: each key refines a search back through the history
+// until one that is not part of the search, which is returned to be run
+// on the line found.
+func (e *Editor) search(l *line) (rune, error) {
+	var query []rune
+	match, found := l.hist, true
+	find := func(from int) {
+		for i := min(from, e.historyLen()-1); i >= 0; i-- {
+			if strings.Contains(e.History.At(i), string(query)) {
+				match, found = i, true
+				return
+			}
+		}
+		found = false
+	}
+
+	for {
+		label := "(reverse-i-search)"
+		if !found {
+			label = "(failing reverse-i-search)"
+		}
+		shown := ""
+		if match < e.historyLen() {
+			shown = strings.ReplaceAll(e.History.At(match), "\n", " ↵ ")
+		}
+		text := []rune(fmt.Sprintf("%s'%s': %s", label, string(query), shown))
+		e.draw(l, "", text, len(text))
+
+		r, err := e.readKey()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case r == ctrlR:
+			find(match - 1)
+		case r == backspace || r == ctrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				find(e.historyLen() - 1)
+			}
+		case r == ctrlG:
+			return 0, nil
+		case r >= ' ' && r <= unicode.MaxRune:
+			query = append(query, r)
+			find(match)
+		default:
+			if match < e.historyLen() {
+				e.recall(l, match)
+			}
+			return r, nil
+		}
+	}
+}
+
+// readKey reads a key press, turning escape sequences into the keys they
+// stand for.
+func (e *Editor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != esc {
+		return r, err
+	}
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	switch r {
+	case enter, ctrlJ:
+		return keyNewline, nil
+	case 'b':
+		return keyWordLeft, nil
+	case 'f':
+		return keyWordRight, nil
+	case 'd':
+		return keyKillWord, nil
+	case backspace, ctrlH:
+		return keyBackwardKillWord, nil
+	case 'O':
+		r, _, err = e.in.ReadRune()
+		return escapeKeys[string(r)], err
+	case '[':
+	default:
+		return keyUnknown, nil
+	}
+
+	// A control sequence: parameters, then a final byte from @ to ~.
+	var seq strings.Builder
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		seq.WriteRune(r)
+		if r >= '@' && r <= '~' {
+			break
+		}
+	}
+	if k, ok := escapeKeys[seq.String()]; ok {
+		return k, nil
+	}
+	return keyUnknown, nil
+}
+
+// escapeKeys maps what follows ESC [ or ESC O to the key it stands for.
+var escapeKeys = map[string]rune{
+	"A": keyUp, "B": keyDown, "C": keyRight, "D": keyLeft,
+	"H": keyHome, "F": keyEnd, "1~": keyHome, "7~": keyHome, "4~": keyEnd, "8~": keyEnd,
+	"3~":   keyDelete,
+	"1;5C": keyWordRight, "1;3C": keyWordRight, "1;5D": keyWordLeft, "1;3D": keyWordLeft,
+	"13;2u": keyNewline,
+	"200~":  keyPaste,
+}
+
+// readPaste reads pasted text up to the end of the paste, so its new lines
+// do not submit the input.
+func (e *Editor) readPaste() ([]rune, error) {
+	// String does not copy what the builder holds, so checking the end
+	// after each rune stays cheap for long pastes.
+	var text strings.Builder
+	for !strings.HasSuffix(text.String(), pasteEnd) {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return nil, err
+		}
+		text.WriteRune(r)
+	}
+	pasted := strings.TrimSuffix(text.String(), pasteEnd)
+	pasted = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(pasted)
+	return []rune(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, pasted)), nil
+}
+
+// draw replaces what was drawn of the input with prompt and text, and
+// puts the cursor before text[cursor].
+func (e *Editor) draw(l *line, prompt string, text []rune, cursor int) {
+	width := e.width()
+	var b strings.Builder
+	if l.row > 0 {
+		fmt.Fprintf(&b, "\033[%dA", l.row)
+	}
+	b.WriteString("\r\033[J" + prompt)
+
+	row, col := 0, visibleWidth(prompt)
+	curRow, curCol := 0, col
+	for i, r := range text {
+		if i == cursor {
+			curRow, curCol = row, col
+		}
+		if r == '\n' {
+			b.WriteString("\r\n" + e.ContinuationPrompt)
+			row, col = row+1, visibleWidth(e.ContinuationPrompt)
+			continue
+		}
+		w := runeWidth(r)
+		if col+w > width {
+			row, col = row+1, 0
+		}
+		if r == '\t' {
+			b.WriteString(strings.Repeat(" ", tabWidth))
+		} else {
+			b.WriteRune(r)
+		}
+		col += w
+	}
+	if col >= width {
+		// The terminal waits for the next character before wrapping,
+		// so wrap now to have somewhere to put the cursor.
+		b.WriteString("\r\n")
+		row, col = row+1, 0
+	}
+	if cursor >= len(text) {
+		curRow, curCol = row, col
+	}
+	if curCol >= width {
+		curRow, curCol = curRow+1, 0
+	}
+
+	if row > curRow {
+		fmt.Fprintf(&b, "\033[%dA", row-curRow)
+	}
+	b.WriteString("\r")
+	if curCol > 0 {
+		fmt.Fprintf(&b, "\033[%dC", curCol)
+	}
+	l.row = curRow
+	fmt.Fprint(e.out, b.String())
+}
+
+// width is the number of columns of the terminal.
+func (e *Editor) width() int {
+	if e.terminal {
+		if w, _, err := term.GetSize(e.fd); err == nil && w > 0 {
+			return w
+		}
+	}
+	return 80
+}
+
+func (l *line) insert(text []rune) {
+	l.buf = append(l.buf[:l.pos], append(append([]rune(nil), text...), l.buf[l.pos:]...)...)
+	l.pos += len(text)
+}
+
+// delete removes buf[start:end], keeping the cursor on the same text.
+func (l *line) delete(start, end int) {
+	start, end = max(0, start), min(len(l.buf), end)
+	if start >= end {
+		return
+	}
+	l.buf = append(l.buf[:start], l.buf[end:]...)
+	if l.pos > end {
+		l.pos -= end - start
+	} else if l.pos > start {
+		l.pos = start
+	}
+}
+
+// replaceHead replaces the text before the cursor.
+func (l *line) replaceHead(text string) {
+	tail := l.buf[l.pos:]
+	l.buf = append([]rune(text), tail...)
+	l.pos = len([]rune(text))
+}
+
+// lineStart is where the line holding the cursor starts.
+func (l *line) lineStart() int {
+	i := l.pos
+	for i > 0 && l.buf[i-1] != '\n' {
+		i--
+	}
+	return i
+}
+
+// lineEnd is where the line holding the cursor ends.
+func (l *line) lineEnd() int {
+	i := l.pos
+	for i < len(l.buf) && l.buf[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+// moveLine moves the cursor to the line above (-1) or below (1), keeping
+// its column where the line is long enough.
+func (l *line) moveLine(dir int) {
+	col := l.pos - l.lineStart()
+	if dir < 0 {
+		l.pos = l.lineStart() - 1
+	} else {
+		l.pos = l.lineEnd() + 1
+	}
+	l.pos = min(l.lineStart()+col, l.lineEnd())
+}
+
+func (l *line) wordLeft() int {
+	i := l.pos
+	for i > 0 && !isWord(l.buf[i-1]) {
+		i--
+	}
+	for i > 0 && isWord(l.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+func (l *line) wordRight() int {
+	i := l.pos
+	for i < len(l.buf) && !isWord(l.buf[i]) {
+		i++
+	}
+	for i < len(l.buf) && isWord(l.buf[i]) {
+		i++
+	}
+	return i
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// fenceOpen reports whether text starts a fenced block that has not
+// been closed yet.
+func fenceOpen(text string) bool {
+	lines := strings.Split(text, "\n")
+	first := strings.TrimSpace(lines[0])
+	if !strings.HasPrefix(first, Fence) {
+		return false
+	}
+	if len(lines) == 1 {
+		return len(first) < 2*len(Fence) || !strings.HasSuffix(first, Fence)
+	}
+	return strings.TrimSpace(lines[len(lines)-1]) != Fence
+}
+
+// unfence returns text without the fences around a block.
+func unfence(text string) string {
+	lines := strings.Split(text, "\n")
+	first := strings.TrimSpace(lines[0])
+	if !strings.HasPrefix(first, Fence) {
+		return text
+	}
+	lines[0] = strings.TrimPrefix(first, Fence)
+	last := len(lines) - 1
+	if last > 0 && strings.TrimSpace(lines[last]) == Fence {
+		lines = lines[:last]
+	} else if last == 0 {
+		lines[0] = strings.TrimSuffix(lines[0], Fence)
+	}
+	if lines[0] == "" {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// ansi matches the color codes in a prompt.
+var ansi = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// visibleWidth is the number of columns s takes on the screen.
+func visibleWidth(s string) int {
+	n := 0
+	for _, r := range ansi.ReplaceAllString(s, "") {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth is the number of columns r takes: none for combining marks,
+// two for wide East Asian characters and emoji.
+func runeWidth(r rune) int {
+	switch {
+	case r == '\t':
+		return tabWidth
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1100 && r <= 0x115F, r >= 0x2E80 && r <= 0xA4CF && r != 0x303F,
+		r >= 0xAC00 && r <= 0xD7A3, r >= 0xF900 && r <= 0xFAFF, r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60, r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F, r >= 0x1F900 && r <= 0x1F9FF, r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,228 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// editor returns an Editor that reads keys as if they were typed at a
+// terminal.
+func editor(keys string, h *History) *Editor {
+	return &Editor{
+		Prompt:  "> ",
+		History: h,
+		in:      bufio.NewReader(strings.NewReader(keys)),
+		out:     io.Discard,
+	}
+}
+
+func history(lines ...string) *History {
+	h := &History{}
+	for _, line := range lines {
+		h.Add(line)
+	}
+	return h
+}
+
+func TestEdit(t *testing.T) {
+	h := history("first", "second\nline", "third")
+	tests := []struct {
+		name, keys, want string
+	}{
+		{"type", "hello\r", "hello"},
+		{"backspace", "helo\x7f\x7fllo\r", "hello"},
+		{"home and end", "ello\x01h\x05!\r", "hello!"},
+		{"arrows", "hllo\x1b[D\x1b[D\x1b[De\x1b[C\x1b[Cx\r", "hellxo"},
+		{"kill and yank", "one two\x1bb\x0b\x01\x19 \r", "two one "},
+		{"kill word", "one two three\x17\x17x\r", "one x"},
+		{"transpose", "abc\x14\r", "acb"},
+		{"delete", "abc\x01\x04\x1b[3~\r", "c"},
+		{"word motion", "one two\x1b[1;5Dx\x1b[1;5C!\r", "one xtwo!"},
+		{"previous", "\x1b[A\r", "third"},
+		{"previous twice", "\x10\x10\r", "second\nline"},
+		{"back to draft", "dr\x1b[A\x1b[A\x1b[B\x1b[Baft\r", "draft"},
+		{"search", "\x12fir\r", "first"},
+		{"search again", "\x12i\x12\x12\r", "first"},
+		{"search and edit", "\x12sec\x1b[A\x05!\r", "second!\nline"},
+		{"search cancelled", "x\x12zzz\x07y\r", "xy"},
+		{"alt-enter", "one\x1b\rtwo\r", "one\ntwo"},
+		{"lines", "a\x1b\rbcd\x1b[Ax\x1b[By\r", "ax\nbcyd"},
+		{"fence", "\"\"\"\rcode\r\"\"\"\r", "\"\"\"\ncode\n\"\"\""},
+		{"paste", "say \x1b[200~one\r\ntwo\x1b[201~\r", "say one\ntwo"},
+		{"tab in block", "a\x1b\r\tb\r", "a\n\tb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := editor(tt.keys, h).edit()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("edit = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLongPaste(t *testing.T) {
+	code := strings.Repeat("fmt.Println(\"héllo\")\n", 10000)
+	got, err := editor("\x1b[200~"+code+"\x1b[201~\r", nil).edit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != code {
+		t.Errorf("pasted %d bytes, got %d back", len(code), len(got))
+	}
+}
+
+func TestEditEnd(t *testing.T) {
+	if _, err := editor("abc\x03", nil).edit(); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("Ctrl-C: err = %v, want ErrInterrupted", err)
+	}
+	if _, err := editor("\x04", nil).edit(); !errors.Is(err, io.EOF) {
+		t.Errorf("Ctrl-D: err = %v, want io.EOF", err)
+	}
+	if _, err := editor("abc", nil).edit(); !errors.Is(err, io.EOF) {
+		t.Errorf("end of input: err = %v, want io.EOF", err)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	names := []string{"/help", "/history", "/save", "/sessions", "/set", "ask @src/", "ask @sum.go", "ask @sub.go"}
+	complete := func(text string) []string {
+		var out []string
+		for _, n := range names {
+			if strings.HasPrefix(n, text) {
+				out = append(out, n)
+			}
+		}
+		return out
+	}
+	tests := []struct{ keys, want string }{
+		{"/sa\tx\r", "/save x"},
+		{"/h\te\t\r", "/help "},
+		{"/s\t\r", "/s"},
+		{"/se\t\r", "/se"},
+		{"/x\t\r", "/x"},
+		{"ask @sr\tx\r", "ask @src/x"},
+	}
+	for _, tt := range tests {
+		e := editor(tt.keys, nil)
+		e.Complete = complete
+		got, err := e.edit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: edit = %q, want %q", tt.keys, got, tt.want)
+		}
+	}
+
+	e := editor("ask @su\t\r", nil)
+	e.Complete = complete
+	var out strings.Builder
+	e.out = &out
+	if _, err := e.edit(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "\r\n@sum.go  @sub.go\r\n") {
+		t.Errorf("listed %q, want the last words only", out.String())
+	}
+}
+
+func TestAsk(t *testing.T) {
+	h := history("first")
+	e := editor("y\n", h)
+	var out strings.Builder
+	e.out = &out
+	got, err := e.Ask("Run it? ")
+	if err != nil || got != "y" {
+		t.Errorf("Ask = %q, %v", got, err)
+	}
+	if out.String() != "Run it? " || e.Prompt != "> " {
+		t.Errorf("prompted %q, prompt now %q", out.String(), e.Prompt)
+	}
+	if got := entries(h); !reflect.DeepEqual(got, []string{"first"}) {
+		t.Errorf("history = %q after Ask", got)
+	}
+}
+
+func TestReadPlain(t *testing.T) {
+	e := editor("hello\r\n\"\"\"\nfunc f() {\n}\n\"\"\"\nlast", nil)
+	var got []string
+	for {
+		line, err := e.ReadLine()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, line)
+	}
+	if want := []string{"hello", "func f() {\n}", "last"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+}
+
+func TestUnfence(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"\"\"\"\na\nb\n\"\"\"", "a\nb"},
+		{"\"\"\"explain:\na\n\"\"\"", "explain:\na"},
+		{"\"\"\"one line\"\"\"", "one line"},
+		{"\"\"\"\nunclosed", "unclosed"},
+	}
+	for _, tt := range tests {
+		if got := unfence(tt.in); got != tt.want {
+			t.Errorf("unfence(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "history")
+	h, err := LoadHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"one", "one", " ", "two\nlines", `back\slash`, "four"} {
+		if err := h.Add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"two\nlines", `back\slash`, "four"}
+	if got := entries(h); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+
+	// The file keeps every line until it is loaded again.
+	h, err = LoadHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := entries(h); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded = %q, want %q", got, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "two\\nlines\nback\\\\slash\nfour\n"; string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+}
+
+func entries(h *History) []string {
+	var out []string
+	for i := range h.Len() {
+		out = append(out, h.At(i))
+	}
+	return out
+}
@@ -1,12 +1,20 @@
 package main
 
 import (
-	"bufio"
 	"cli_chatbot_go/ai"
+	"cli_chatbot_go/attach"
+	"cli_chatbot_go/lineedit"
 	"cli_chatbot_go/responses"
+	"cli_chatbot_go/session"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -40,8 +48,8 @@ func printWelcome() {
 	fmt.Printf("     %s%sInspired by Claude Code & LazyVim%s\n", dim, colorBlue, colorReset)
 
 	// Show AI status
-	if ai.IsEnabled() {
-		fmt.Printf("     %s%s🤖 AI Mode: Enabled (Gemini)%s\n", dim, colorGreen, colorReset)
+	if p := ai.Current(); p != nil {
+		fmt.Printf("     %s%s🤖 AI Mode: Enabled (%s, %s)%s\n", dim, colorGreen, p.Name(), p.Model(), colorReset)
 	} else {
 		fmt.Printf("     %s%s💡 AI Mode: Disabled (Fallback responses)%s\n", dim, colorGray, colorReset)
 	}
@@ -53,12 +61,16 @@ func printWelcome() {
 	// Commands section
 	fmt.Printf("  %s%s📋 Available Commands:%s\n", dim, colorGray, colorReset)
 	fmt.Printf("     help  info  time  joke  quote  clear  exit\n")
+	fmt.Printf("     %s%s/help lists the slash commands%s\n", dim, colorGray, colorReset)
+	fmt.Printf("     %s%sAlt-Enter adds a line, %s starts a block, ↑ and Ctrl-R recall%s\n", dim, colorGray, lineedit.Fence, colorReset)
 	fmt.Printf("\n")
 	fmt.Printf("  %s%s💬 Start typing your message...%s\n", dim, colorGray, colorReset)
 	fmt.Printf("\n")
 }
 
-func printMessage(message string, isBot bool) {
+// printMessage shows a message in a box; notes, such as what a prompt
+// attached, go in dim lines under a message of the user's.
+func printMessage(message string, isBot bool, notes ...string) {
 	timestamp := time.Now().Format("15:04")
 
 	if isBot {
@@ -68,14 +80,13 @@ func printMessage(message string, isBot bool) {
 	} else {
 		fmt.Printf("%s%s┌─ %sYou %s• %s%s%s\n", dim, colorGray, colorGreen, colorGray, timestamp, colorReset, colorReset)
 		fmt.Printf("%s%s│%s  %s\n", dim, colorGray, colorReset, message)
+		for _, note := range notes {
+			fmt.Printf("%s%s│  %s%s\n", dim, colorGray, note, colorReset)
+		}
 		fmt.Printf("%s%s└─%s\n", dim, colorGray, colorReset)
 	}
 }
 
-func printPrompt() {
-	fmt.Printf("%s%s❯%s ", bold, colorPurple, colorReset)
-}
-
 func showLoadingIndicator(done chan bool, message string) {
 	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 	i := 0
@@ -99,135 +110,310 @@ func showLoadingIndicator(done chan bool, message string) {
 	}
 }
 
+// contextBarWidth is the width of the /context usage bar.
+const contextBarWidth = 30
+
+// contextCommand runs /context, showing how much of the token budget the
+// conversation uses.
+func contextCommand(ctx context.Context, args []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	u, err := ai.ContextUsage(ctx)
+	if err != nil {
+		return "", fmt.Errorf("context usage is not available: %w", err)
+	}
+
+	var b strings.Builder
+	if u.Budget == 0 {
+		fmt.Fprintf(&b, "%s%d tokens%s sent with each prompt (no budget set)", bold, u.Sent, colorReset)
+	} else {
+		filled := min(contextBarWidth, u.Sent*contextBarWidth/u.Budget)
+		fmt.Fprintf(&b, "%s%s%s%s%s  %s%d%s of %d tokens (%d%%)", colorPurple, strings.Repeat("█", filled), colorGray,
+			strings.Repeat("░", contextBarWidth-filled), colorReset, bold, u.Sent, colorReset, u.Budget, u.Sent*100/u.Budget)
+	}
+	fmt.Fprintf(&b, "\n   History: %d messages, %d tokens with the system prompt", u.Messages, u.Total)
+	if u.Dropped > 0 {
+		fmt.Fprintf(&b, "\n   Left out: %d older messages", u.Dropped)
+	}
+	if u.Summaries > 0 {
+		fmt.Fprintf(&b, "\n   Summaries: %d", u.Summaries)
+	}
+	fmt.Fprintf(&b, "\n   %sStrategy: %s", dim, u.Strategy)
+	if u.Pin > 0 {
+		fmt.Fprintf(&b, ", first %d messages pinned", u.Pin)
+	}
+	b.WriteString(colorReset)
+	return b.String(), nil
+}
+
+func printGoodbye() {
+	fmt.Printf("  %s%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", bold, colorPurple, colorReset)
+	fmt.Printf("%s%s%s  %sThanks for chatting! See you next time. %s✨%s           %s%s%s\n", bold, colorPurple, colorReset, colorWhite, colorOrange, colorReset, bold, colorPurple, colorReset)
+	fmt.Printf("  %s%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", bold, colorPurple, colorReset)
+}
+
+// newEditor returns the line editor for the prompt, with the input
+// history kept next to the sessions.
+func newEditor() *lineedit.Editor {
+	ed := lineedit.New(os.Stdin, os.Stdout)
+	ed.Prompt = fmt.Sprintf("%s%s❯%s ", bold, colorPurple, colorReset)
+	ed.ContinuationPrompt = fmt.Sprintf("%s%s…%s ", dim, colorGray, colorReset)
+	ed.Complete = completeInput
+	dir, err := session.DataDir()
+	if err != nil {
+		log.Printf("History: %v\n", err)
+		return ed
+	}
+	ed.History, err = lineedit.LoadHistory(filepath.Join(dir, "history"), 0)
+	if err != nil {
+		log.Printf("History: %v\n", err)
+	}
+	return ed
+}
+
+// typed is a line read at the prompt, or the error that ended reading.
+type typed struct {
+	line string
+	err  error
+}
+
+// lineReader reads lines in the background, one at a time when asked, so
+// the prompt can also wait for Ctrl-C.
+type lineReader struct {
+	ed      *lineedit.Editor
+	lines   chan typed
+	waiting bool // a line is being read
+}
+
+// next starts reading a line unless one is already being read, and
+// returns the channel it arrives on.
+func (r *lineReader) next() <-chan typed {
+	if !r.waiting {
+		r.waiting = true
+		go func() {
+			line, err := r.ed.ReadLine()
+			r.lines <- typed{line, err}
+		}()
+	}
+	return r.lines
+}
+
+// interruptedReason describes why a response was cut short.
+func interruptedReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timed out"
+	}
+	return "interrupted"
+}
+
 func main() {
+	resume := flag.Bool("resume", false, "continue the most recent session")
+	var once oneShot
+	flag.StringVar(&once.prompt, "p", "", "answer `prompt`, with any piped input appended, and exit")
+	flag.BoolVar(&once.json, "json", false, "with -p, print the reply and its metadata as JSON")
+	flag.BoolVar(&once.noStream, "no-stream", false, "with -p, print the reply once it is complete")
+	flag.Parse()
+	oneShotMode := once.prompt != "" || once.json || once.noStream
+	if oneShotMode && *resume {
+		fmt.Fprintln(os.Stderr, "chatbot: --resume cannot be combined with -p")
+		os.Exit(exitUsage)
+	}
+
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
+	if err := godotenv.Load(); err != nil && !oneShotMode {
 		log.Println("Warning: .env file not found, using default settings")
 	}
 
 	// Initialize AI
-	if err := ai.Initialize(); err != nil {
-		log.Printf("AI initialization: %v\n", err)
+	aiErr := ai.Initialize()
+	if oneShotMode {
+		// Nothing can be confirmed, so dangerous tools are declined.
+		useTools(nil, showToolPlain(os.Stderr))
+		code := once.run(os.Stdin, os.Stdout, os.Stderr, aiErr)
+		ai.Close()
+		os.Exit(code)
+	}
+	if aiErr != nil {
+		log.Printf("AI initialization: %v\n", aiErr)
 	}
 	defer ai.Close()
 
+	loadPersonas()
+	if err := openSessions(*resume); err != nil {
+		log.Printf("Sessions: %v\n", err)
+	}
+	registry = newRegistry()
+
+	// Ctrl-C cancels the response in flight; at the prompt, pressing it
+	// twice in a row exits.
+	interrupts = make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+
 	printWelcome()
-	reader := bufio.NewReader(os.Stdin)
+	if *resume && len(current.Messages) > 0 {
+		replay(current)
+	}
+	ed := newEditor()
+	useTools(confirmTool(ed), showTool)
+	lines := &lineReader{ed: ed, lines: make(chan typed)}
+	exitArmed := false
 
 	for {
-		printPrompt()
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Printf("\n%s%sError reading input%s\n", colorReset, colorOrange, colorReset)
+		var in typed
+		select {
+		case <-interrupts:
+			fmt.Printf("\n")
+			in.err = lineedit.ErrInterrupted
+		case in = <-lines.next():
+			lines.waiting = false
+		}
+		switch {
+		case errors.Is(in.err, lineedit.ErrInterrupted):
+			if exitArmed {
+				printGoodbye()
+				return
+			}
+			exitArmed = true
+			fmt.Printf("%s%s(Press Ctrl-C again to exit)%s\n", dim, colorGray, colorReset)
+			if lines.waiting {
+				fmt.Print(lines.ed.Prompt)
+			}
 			continue
+		case in.err != nil:
+			if !errors.Is(in.err, io.EOF) {
+				fmt.Printf("\n%s%sError reading input: %v%s\n", colorReset, colorOrange, in.err, colorReset)
+			}
+			fmt.Printf("\n")
+			printGoodbye()
+			return
 		}
+		exitArmed = false
 
-		input = strings.TrimSpace(input)
+		input := strings.TrimSpace(in.line)
 		if input == "" {
 			continue
 		}
 
-		// Handle exit command
-		if input == "exit" || input == "/exit" {
-			fmt.Printf("  %s%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", bold, colorPurple, colorReset)
-			fmt.Printf("%s%s%s  %sThanks for chatting! See you next time. %s✨%s           %s%s%s\n", bold, colorPurple, colorReset, colorWhite, colorOrange, colorReset, bold, colorPurple, colorReset)
-			fmt.Printf("  %s%s━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━%s\n", bold, colorPurple, colorReset)
-			os.Exit(0)
-		}
-
-		// Handle clear command
-		if input == "clear" || input == "/clear" {
-			printWelcome()
+		if handled, exit := runCommand(input); exit {
+			printGoodbye()
+			return
+		} else if handled {
 			continue
 		}
 
-		// Show user message
-		printMessage(input, false)
+		respond(input)
+	}
+}
 
-		// Get response - try AI first, fallback to predefined responses
-		var resp string
+// respond shows input as the user's message and answers it: canned
+// replies first, then the AI, then the fallback responses.
+func respond(input string) {
+	// Attach what @-references name, for the AI only
+	exp := attach.Expansion{Prompt: input}
+	if ai.IsEnabled() && !responses.IsCommand(input) {
+		exp = attach.Expand(input)
+	}
+	var notes []string
+	for _, a := range exp.Attachments {
+		notes = append(notes, "📎 "+a.String())
+	}
 
-		// Check if it's a built-in command first
-		if responses.IsCommand(input) {
-			resp = responses.GetResponse(input)
+	// Show user message
+	printMessage(input, false, notes...)
 
-			// Simple loading for commands
-			done := make(chan bool)
-			go showLoadingIndicator(done, "processing")
-			time.Sleep(300 * time.Millisecond)
-			done <- true
+	// Get response - try AI first, fallback to predefined responses
+	var resp string
 
-			// Clear the spinner header line and print the response
-			fmt.Print("\033[1A\r\033[K")
-			printMessage(resp, true)
+	// Check if it's a built-in command first
+	if responses.IsCommand(input) {
+		resp = responses.GetResponse(input)
 
-			continue
-		}
+		// Simple loading for commands
+		done := make(chan bool)
+		go showLoadingIndicator(done, "processing")
+		time.Sleep(300 * time.Millisecond)
+		done <- true
 
-		// If AI enabled, stream; otherwise fallback
-		if ai.IsEnabled() {
-			// Show "sending prompt..." indicator briefly
-			sendDone := make(chan bool)
-			go showLoadingIndicator(sendDone, "sending prompt")
-			time.Sleep(350 * time.Millisecond)
-			sendDone <- true
-
-			// Start "thinking" spinner
-			thinkDone := make(chan bool)
-			go showLoadingIndicator(thinkDone, "thinking")
-
-			// Collect response
-			var fullResponse strings.Builder
-			timestamp := time.Now().Format("15:04")
-			firstChunk := true
-
-			err := ai.StreamResponse(input,
-				func(chunk string) {
-					if firstChunk {
-						// Stop the thinking spinner
-						thinkDone <- true
-
-						// Clear spinner header
-						fmt.Print("\033[1A\r\033[K")
-
-						// Print actual assistant header (no leading \n)
-						fmt.Printf("%s%s┌─ %sAssistant %s• %s%s%s\n", dim, colorGray, colorBlue, colorGray, timestamp, colorReset, colorReset)
-
-						// Print initial content prefix and first chunk
-						fmt.Printf("%s%s│%s  %s", dim, colorGray, colorReset, chunk)
-						firstChunk = false
-					} else {
-						// Print subsequent chunks raw (streaming effect)
-						fmt.Print(chunk)
-					}
-					fullResponse.WriteString(chunk)
-				},
+		// Clear the spinner header line and print the response
+		fmt.Print("\033[1A\r\033[K")
+		printMessage(resp, true)
+		return
+	}
+
+	// If AI enabled, stream; otherwise fallback
+	if ai.IsEnabled() {
+		// Show "sending prompt..." indicator briefly
+		sendDone := make(chan bool)
+		go showLoadingIndicator(sendDone, "sending prompt")
+		time.Sleep(350 * time.Millisecond)
+		sendDone <- true
+
+		// Start "thinking" spinner; the box replaces it when the first
+		// chunk or tool call arrives.
+		thinkDone := make(chan bool)
+		go showLoadingIndicator(thinkDone, "thinking")
+		reply = newReplyBox(thinkDone)
+		box := reply
+		defer func() { reply = nil }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		streamed := make(chan error, 1)
+		go func() {
+			streamed <- ai.StreamResponse(ctx, exp.Prompt,
+				box.write,
 				func(finalText string) {
-					// Close the box (no re-printing to avoid duplicates)
-					fmt.Printf("\n%s%s└─%s\n\n", dim, colorGray, colorReset)
+					box.start()
+					box.close("")
 				},
+				exp.Images...,
 			)
+		}()
+
+		var err error
+		select {
+		case err = <-streamed:
+		case <-interrupts:
+			cancel()
+			err = <-streamed
+		}
+		cancel()
+		if err == nil || ai.IsInterrupted(err) {
+			saveExchange()
+		}
 
-			if err != nil {
-				// Stop spinner if not already
-				if firstChunk {
-					thinkDone <- true
+		if err != nil && ai.IsInterrupted(err) {
+			// Keep what arrived and close the box
+			if !box.open {
+				thinkDone <- true
+				fmt.Printf("%s%s│  (%s before a reply arrived)%s\n", dim, colorGray, interruptedReason(err), colorReset)
+				fmt.Printf("%s%s└─%s\n\n", dim, colorGray, colorReset)
+			} else {
+				box.close(interruptedReason(err))
+			}
+		} else if err != nil {
+			// Stop spinner if not already, or close the partial reply
+			if !box.open {
+				thinkDone <- true
+			} else {
+				if box.midLine {
+					fmt.Println()
 				}
-				log.Printf("AI error: %v, falling back to predefined responses\n", err)
-				resp = responses.GetResponse(input) + "\n(AI error occurred; using fallback)"
-				printMessage(resp, true)
+				fmt.Printf("%s%s└─%s\n", dim, colorGray, colorReset)
 			}
-
-		} else {
-			// Fallback to predefined responses
-			done := make(chan bool)
-			go showLoadingIndicator(done, "processing")
-			time.Sleep(300 * time.Millisecond)
-			done <- true
-
-			resp = responses.GetResponse(input)
-			fmt.Print("\033[1A\r\033[K")
+			log.Printf("AI error: %v, falling back to predefined responses\n", err)
+			resp = responses.GetResponse(input) + "\n(AI error occurred; using fallback)"
 			printMessage(resp, true)
 		}
+
+	} else {
+		// Fallback to predefined responses
+		done := make(chan bool)
+		go showLoadingIndicator(done, "processing")
+		time.Sleep(300 * time.Millisecond)
+		done <- true
+
+		resp = responses.GetResponse(input)
+		fmt.Print("\033[1A\r\033[K")
+		printMessage(resp, true)
 	}
 }
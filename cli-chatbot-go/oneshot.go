@@ -0,0 +1,168 @@
+package main
+
+import (
+	"cli_chatbot_go/ai"
+	"cli_chatbot_go/attach"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Exit codes of a one-shot run.
+const (
+	exitFailed      = 1   // the request failed or timed out
+	exitUsage       = 2   // bad flags or no prompt
+	exitUnavailable = 3   // AI is disabled or not configured
+	exitInterrupted = 130 // Ctrl-C
+)
+
+// maxInput caps how much piped input is sent with the prompt.
+const maxInput = 1 << 20
+
+// oneShot is a prompt answered without the chat loop, for scripts.
+type oneShot struct {
+	prompt   string
+	json     bool // print a JSON object with the reply and metadata
+	noStream bool // print the reply once it is complete
+}
+
+// oneShotResult is what --json prints.
+type oneShotResult struct {
+	Provider  string        `json:"provider,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	Response  string        `json:"response"`
+	Error     string        `json:"error,omitempty"`
+	ElapsedMS int64         `json:"elapsed_ms"`
+	Usage     *oneShotUsage `json:"usage,omitempty"`
+}
+
+type oneShotUsage struct {
+	PromptTokens   int `json:"prompt_tokens"`
+	ResponseTokens int `json:"response_tokens"`
+	TotalTokens    int `json:"total_tokens"`
+}
+
+// run answers the prompt, with any piped input appended, on stdout and
+// returns the exit code.
+func (o oneShot) run(stdin *os.File, stdout, stderr io.Writer, aiErr error) int {
+	fail := func(code int, err error) int {
+		if o.json {
+			writeJSON(stdout, oneShotResult{Error: err.Error()})
+		}
+		fmt.Fprintf(stderr, "chatbot: %v\n", err)
+		return code
+	}
+
+	prompt := o.prompt
+	if !term.IsTerminal(int(stdin.Fd())) {
+		input, err := io.ReadAll(io.LimitReader(stdin, maxInput+1))
+		if err != nil {
+			return fail(exitUsage, fmt.Errorf("reading input: %w", err))
+		}
+		if len(input) > maxInput {
+			input = input[:maxInput]
+			fmt.Fprintf(stderr, "chatbot: input cut to the first %d bytes\n", maxInput)
+		}
+		prompt = withInput(prompt, string(input))
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return fail(exitUsage, errors.New("no prompt: pass one with -p or pipe it in"))
+	}
+	if aiErr != nil {
+		return fail(exitUnavailable, aiErr)
+	}
+	if !ai.IsEnabled() {
+		return fail(exitUnavailable, errors.New("AI is disabled (AI_ENABLED=false)"))
+	}
+	// Attachments go after the piped input; references in the input are
+	// not expanded.
+	exp := attach.Expand(o.prompt)
+	prompt += strings.TrimPrefix(exp.Prompt, o.prompt)
+	for _, a := range exp.Attachments {
+		if len(a.Skipped) > 0 || a.Truncated {
+			fmt.Fprintf(stderr, "chatbot: attached %s\n", a)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	p := ai.Current()
+	start := time.Now()
+
+	var reply string
+	var err error
+	if o.json || o.noStream {
+		reply, err = ai.GetResponse(ctx, prompt, exp.Images...)
+		if err == nil && !o.json {
+			fmt.Fprint(stdout, reply)
+		}
+	} else {
+		ai.TypingDelay = 0
+		err = ai.StreamResponse(ctx, prompt, func(chunk string) {
+			fmt.Fprint(stdout, chunk)
+			reply += chunk
+		}, nil, exp.Images...)
+	}
+	if !o.json && reply != "" && !strings.HasSuffix(reply, "\n") {
+		fmt.Fprintln(stdout)
+	}
+
+	code := 0
+	switch {
+	case err != nil && ai.IsInterrupted(err) && ctx.Err() != nil:
+		code = exitInterrupted
+	case err != nil:
+		code = exitFailed
+	}
+	if err != nil && !o.json {
+		fmt.Fprintf(stderr, "chatbot: %v\n", err)
+	}
+	if !o.json {
+		return code
+	}
+
+	res := oneShotResult{
+		Provider:  p.Name(),
+		Model:     p.Model(),
+		Response:  reply,
+		ElapsedMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		res.Error = err.Error()
+	} else if u, ok := ai.LastUsage(); ok {
+		res.Usage = &oneShotUsage{PromptTokens: u.Prompt, ResponseTokens: u.Response, TotalTokens: u.Prompt + u.Response}
+	}
+	writeJSON(stdout, res)
+	return code
+}
+
+func writeJSON(w io.Writer, v any) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// withInput appends input to prompt as a fenced block for it to refer to.
+// Either may be empty.
+func withInput(prompt, input string) string {
+	input = strings.TrimRight(input, "\r\n")
+	if strings.TrimSpace(input) == "" {
+		return prompt
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return input
+	}
+	fence := "```"
+	for strings.Contains(input, fence) {
+		fence += "`"
+	}
+	return prompt + "\n\n" + fence + "\n" + input + "\n" + fence
+}
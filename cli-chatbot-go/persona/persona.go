@@ -0,0 +1,107 @@
+// Package persona loads the named system prompts the chatbot can take on.
+package persona
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cli_chatbot_go/ai"
+)
+
+// Default is the persona used when none is chosen.
+const Default = "default"
+
+//go:embed builtin/*.md
+var builtin embed.FS
+
+// Persona is a named system prompt.
+type Persona struct {
+	Name   string
+	Prompt string
+	File   string // file it was loaded from, empty for built-in personas
+}
+
+// Summary is the first line of the prompt, for listings.
+func (p Persona) Summary() string {
+	line, _, _ := strings.Cut(p.Prompt, "\n")
+	return line
+}
+
+// Dir is where personas are looked for: $CHATBOT_CONFIG_DIR/personas if
+// set, otherwise cli-chatbot/personas in the user's config directory.
+func Dir() (string, error) {
+	if dir := os.Getenv("CHATBOT_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "personas"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cli-chatbot", "personas"), nil
+}
+
+// Load returns the built-in personas together with those in dir, one per
+// .md or .txt file named after the persona. A file overrides a built-in
+// persona of the same name. A missing dir is not an error.
+func Load(dir string) (map[string]Persona, error) {
+	personas := map[string]Persona{
+		Default: {Name: Default, Prompt: ai.DefaultSystemPrompt},
+	}
+	if err := add(personas, builtin, "builtin", ""); err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return personas, nil
+	}
+	if err := add(personas, os.DirFS(dir), ".", dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return personas, err
+	}
+	return personas, nil
+}
+
+// add reads the personas in dir of fsys. Empty files are skipped.
+func add(personas map[string]Persona, fsys fs.FS, dir, source string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".md" && ext != ".txt") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		prompt := strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n"))
+		if prompt == "" {
+			continue
+		}
+		p := Persona{Name: strings.TrimSuffix(e.Name(), ext), Prompt: prompt}
+		if source != "" {
+			p.File = filepath.Join(source, e.Name())
+		}
+		personas[p.Name] = p
+	}
+	return nil
+}
+
+// Names returns the persona names in alphabetical order, with the default
+// persona first.
+func Names(personas map[string]Persona) []string {
+	var names []string
+	for name := range personas {
+		if name != Default {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{Default}, names...)
+}
@@ -0,0 +1,70 @@
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cli_chatbot_go/ai"
+)
+
+func TestBuiltin(t *testing.T) {
+	personas, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"default", "reviewer", "shell-helper", "translator"}; !reflect.DeepEqual(Names(personas), want) {
+		t.Errorf("Names = %q, want %q", Names(personas), want)
+	}
+	if personas[Default].Prompt != ai.DefaultSystemPrompt {
+		t.Errorf("default prompt = %q", personas[Default].Prompt)
+	}
+	for _, p := range personas {
+		if p.File != "" || p.Summary() == "" || strings.HasSuffix(p.Prompt, "\n") {
+			t.Errorf("built-in persona %+v", p)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"pirate.txt":  "Talk like a pirate.\r\nKeep it short.\r\n",
+		"reviewer.md": "Review Go code only.\n",
+		"empty.md":    "  \n",
+		"notes.json":  "{}",
+		"drafts/x.md": "ignored",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	personas, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"default", "pirate", "reviewer", "shell-helper", "translator"}; !reflect.DeepEqual(Names(personas), want) {
+		t.Errorf("Names = %q, want %q", Names(personas), want)
+	}
+	want := Persona{Name: "pirate", Prompt: "Talk like a pirate.\nKeep it short.", File: filepath.Join(dir, "pirate.txt")}
+	if personas["pirate"] != want {
+		t.Errorf("pirate = %+v, want %+v", personas["pirate"], want)
+	}
+	if p := personas["reviewer"]; p.Prompt != "Review Go code only." || p.File == "" {
+		t.Errorf("reviewer was not overridden: %+v", p)
+	}
+	if p := personas["pirate"]; p.Summary() != "Talk like a pirate." {
+		t.Errorf("Summary = %q", p.Summary())
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("missing dir: %v", err)
+	}
+}
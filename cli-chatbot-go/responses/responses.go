@@ -16,27 +16,11 @@ const (
 	bold        = "\033[1m"
 )
 
-var commands = map[string]bool{
-	"hello":     true,
-	"hi":        true,
-	"hey":       true,
-	"help":      true,
-	"info":      true,
-	"time":      true,
-	"joke":      true,
-	"quote":     true,
-	"thanks":    true,
-	"thank you": true,
-	"bye":       true,
-	"goodbye":   true,
-}
-
 var replyMap = map[string]string{
 	"hello":     "Hi there! 👋 How can I assist you today?",
 	"hi":        "Hello! Great to see you. What can I help you with?",
 	"hey":       "Hey! What's on your mind?",
-	"help":      fmt.Sprintf("Available commands:\n  %s%shelp%s - Show this help message\n  %s%sinfo%s - Learn about this chatbot\n  %s%stime%s - Get current time\n  %s%sjoke%s - Hear a programming joke\n  %s%squote%s - Get an inspiring quote\n  %s%sclear%s - Clear the screen\n  %s%sexit%s - Exit the chatbot\n\n  💡 Tip: When AI is enabled, you can ask me anything!", bold, colorGreen, colorReset, bold, colorGreen, colorReset, bold, colorGreen, colorReset, bold, colorGreen, colorReset, bold, colorGreen, colorReset, bold, colorGreen, colorReset, bold, colorGreen, colorReset),
-	"info":      fmt.Sprintf("✨ %sCLI Chatbot v2.0%s\n\nA modern command-line chatbot inspired by Claude Code and LazyVim aesthetics.\nBuilt with Go for speed and simplicity.\n\nFeatures:\n  • Clean, colorful interface\n  • Real-time responses\n  • Google Gemini AI integration\n  • Extensible command system\n  • Smart fallback responses", bold, colorReset),
+	"info":      fmt.Sprintf("✨ %sCLI Chatbot v2.0%s\n\nA modern command-line chatbot inspired by Claude Code and LazyVim aesthetics.\nBuilt with Go for speed and simplicity.\n\nFeatures:\n  • Clean, colorful interface\n  • Real-time responses\n  • AI backends: Gemini, OpenAI-compatible servers and Ollama\n  • Extensible command system\n  • Smart fallback responses", bold, colorReset),
 	"time":      getCurrentTime(),
 	"joke":      getRandomJoke(),
 	"quote":     getRandomQuote(),
@@ -83,10 +67,11 @@ func getRandomQuote() string {
 	return quotes[rand.Intn(len(quotes))]
 }
 
-// IsCommand checks if the input is a built-in command
+// IsCommand checks if the input has a canned reply
 func IsCommand(input string) bool {
 	input = strings.ToLower(strings.TrimSpace(input))
-	return commands[input]
+	_, ok := replyMap[input]
+	return ok
 }
 
 func GetResponse(input string) string {
@@ -114,7 +99,7 @@ func GetResponse(input string) string {
 	}
 
 	if containsAny(input, []string{"help", "commands", "what can you do"}) {
-		return replyMap["help"]
+		return fmt.Sprintf("Type %s%s/help%s to see the commands. 💡 When AI is enabled, you can ask me anything!", bold, colorGreen, colorReset)
 	}
 
 	if containsAny(input, []string{"thank", "thanks", "appreciate"}) {
@@ -142,7 +127,7 @@ func GetResponse(input string) string {
 	}
 
 	if containsAny(input, []string{"who are you", "what are you", "your name"}) {
-		return fmt.Sprintf("I'm a CLI chatbot built with Go and powered by Google Gemini AI! Type %s%shelp%s to see what I can do!", bold, colorGreen, colorReset)
+		return fmt.Sprintf("I'm a CLI chatbot built with Go and powered by Gemini, OpenAI-compatible models or Ollama! Type %s%s/help%s to see what I can do!", bold, colorGreen, colorReset)
 	}
 
 	// Default response
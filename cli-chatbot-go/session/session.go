@@ -0,0 +1,185 @@
+// Package session saves conversations to disk so they can be resumed.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"cli_chatbot_go/ai"
+)
+
+// Session is a saved conversation.
+type Session struct {
+	Name     string       `json:"-"` // file name without .json
+	Title    string       `json:"title"`
+	Provider string       `json:"provider,omitempty"`
+	Model    string       `json:"model,omitempty"`
+	Persona  string       `json:"persona,omitempty"`
+	Settings ai.Settings  `json:"settings"`
+	Created  time.Time    `json:"created"`
+	Updated  time.Time    `json:"updated"`
+	Messages []ai.Message `json:"messages"`
+}
+
+// New starts a session named after the time it began.
+func New(now time.Time) *Session {
+	return &Session{Name: now.Format("2006-01-02-150405"), Created: now, Updated: now}
+}
+
+// validName keeps session names usable as file names everywhere.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// CheckName reports whether name can be used for a session.
+func CheckName(name string) error {
+	if !validName.MatchString(name) || len(name) > 64 {
+		return fmt.Errorf("invalid session name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// ErrNotFound is returned for a session that does not exist.
+var ErrNotFound = errors.New("session not found")
+
+// Store keeps sessions as JSON files in a directory.
+type Store struct {
+	Dir string
+}
+
+// DataDir is where the chatbot keeps its data: $CHATBOT_DATA_DIR if set,
+// otherwise cli-chatbot in the user's data directory.
+func DataDir() (string, error) {
+	if dir := os.Getenv("CHATBOT_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" && runtime.GOOS == "windows" {
+		dir = os.Getenv("LocalAppData")
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "cli-chatbot"), nil
+}
+
+// DefaultDir is where sessions are kept: the sessions directory in
+// DataDir.
+func DefaultDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions"), nil
+}
+
+func (st Store) path(name string) string {
+	return filepath.Join(st.Dir, name+".json")
+}
+
+// Save writes s, replacing any earlier save of it.
+func (st Store) Save(s *Session) error {
+	if err := CheckName(s.Name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(st.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write a temporary file first so a crash never leaves half a session.
+	tmp := st.path(s.Name) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, st.path(s.Name))
+}
+
+// Load reads the session called name.
+func (st Store) Load(name string) (*Session, error) {
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(st.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{Name: name}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("session %s cannot be read: %w", name, err)
+	}
+	return s, nil
+}
+
+// List returns the saved sessions, most recently updated first. Files that
+// cannot be read are skipped.
+func (st Store) List() ([]*Session, error) {
+	entries, err := os.ReadDir(st.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sessions []*Session
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if s, err := st.Load(name); err == nil {
+			sessions = append(sessions, s)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Updated.After(sessions[j].Updated)
+	})
+	return sessions, nil
+}
+
+// Latest returns the most recently updated session.
+func (st Store) Latest() (*Session, error) {
+	sessions, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("%w: no saved sessions in %s", ErrNotFound, st.Dir)
+	}
+	return sessions[0], nil
+}
+
+// Rename gives s a new name, moving its saved file if there is one.
+func (st Store) Rename(s *Session, name string) error {
+	if err := CheckName(name); err != nil {
+		return err
+	}
+	if name == s.Name {
+		return nil
+	}
+	if _, err := os.Stat(st.path(name)); err == nil {
+		return fmt.Errorf("a session called %s already exists", name)
+	}
+	err := os.Rename(st.path(s.Name), st.path(name))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	s.Name = name
+	return nil
+}
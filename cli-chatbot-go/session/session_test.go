@@ -0,0 +1,134 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"cli_chatbot_go/ai"
+)
+
+var start = time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+
+func TestStore(t *testing.T) {
+	st := Store{Dir: filepath.Join(t.TempDir(), "sessions")}
+
+	if _, err := st.Latest(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Latest on an empty store: err = %v", err)
+	}
+
+	first := New(start)
+	first.Title = "Go generics"
+	first.Model = "llama3.2"
+	first.Messages = []ai.Message{{Role: ai.RoleUser, Content: "What are generics?"}, {Role: ai.RoleAssistant, Content: "Type parameters."}}
+	second := New(start.Add(time.Hour))
+	second.Title = "Dinner ideas"
+	for _, s := range []*Session{first, second} {
+		if err := st.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.Name != "2026-10-19-093000" {
+		t.Errorf("name = %q", first.Name)
+	}
+
+	got, err := st.Load(first.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, first) {
+		t.Errorf("Load = %+v, want %+v", got, first)
+	}
+
+	if err := st.Rename(first, "generics"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Load("2026-10-19-093000"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old name still loads: err = %v", err)
+	}
+	if err := st.Rename(second, "generics"); err == nil {
+		t.Error("renaming onto an existing session: no error")
+	}
+
+	list, err := st.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range list {
+		names = append(names, s.Name)
+	}
+	if want := []string{"2026-10-19-103000", "generics"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("List = %q, want most recent first %q", names, want)
+	}
+	if latest, err := st.Latest(); err != nil || latest.Title != "Dinner ideas" {
+		t.Errorf("Latest = %+v, %v", latest, err)
+	}
+}
+
+func TestStoreSkipsBrokenFiles(t *testing.T) {
+	st := Store{Dir: t.TempDir()}
+	if err := os.WriteFile(filepath.Join(st.Dir, "broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.Load("broken"); err == nil {
+		t.Error("Load of a broken file: no error")
+	}
+	if list, err := st.List(); err != nil || len(list) != 0 {
+		t.Errorf("List = %v, %v, want the broken file skipped", list, err)
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	for name, ok := range map[string]bool{
+		"work":       true,
+		"2026-10-19": true,
+		"v1.2_final": true,
+		"":           false,
+		"../escape":  false,
+		"a b":        false,
+		".hidden":    false,
+	} {
+		if err := CheckName(name); (err == nil) != ok {
+			t.Errorf("CheckName(%q) = %v", name, err)
+		}
+	}
+}
+
+// titler answers every request with a fixed reply.
+type titler struct {
+	reply string
+	err   error
+}
+
+func (p titler) Name() string  { return "titler" }
+func (p titler) Model() string { return "test" }
+func (p titler) Stream(ctx context.Context, req ai.Request, onChunk func(string)) (string, error) {
+	return p.Complete(ctx, req)
+}
+func (p titler) Complete(context.Context, ai.Request) (string, error)   { return p.reply, p.err }
+func (p titler) ListModels(context.Context) ([]string, error)           { return nil, nil }
+func (p titler) CountTokens(context.Context, []ai.Message) (int, error) { return 0, nil }
+
+func TestTitle(t *testing.T) {
+	prompt := "Can you explain how goroutines and channels work together in Go?"
+	tests := []struct {
+		p    ai.Provider
+		want string
+	}{
+		{titler{reply: "\"Goroutines and Channels Explained\"\n"}, "Goroutines and Channels Explained"},
+		{titler{reply: "A very long title that goes on and on forever."}, "A very long title that goes"},
+		{titler{err: errors.New("offline")}, "Can you explain how goroutines and"},
+		{titler{}, "Can you explain how goroutines and"},
+		{nil, "Can you explain how goroutines and"},
+	}
+	for _, tt := range tests {
+		if got := Title(context.Background(), tt.p, prompt, "They communicate."); got != tt.want {
+			t.Errorf("Title with %+v = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"cli_chatbot_go/ai"
+)
+
+// maxTitleWords caps generated titles and the fallback taken from the
+// first prompt.
+const maxTitleWords = 6
+
+// titleTimeout keeps a slow backend from holding up the prompt.
+const titleTimeout = 15 * time.Second
+
+const titlePrompt = "Write a title of at most six words for the conversation below. " +
+	"Reply with the title only, without quotes or punctuation at the end."
+
+// Title names a conversation from its first exchange. It asks p for a
+// title and falls back to the start of the first prompt when p is nil or
+// fails.
+func Title(ctx context.Context, p ai.Provider, prompt, reply string) string {
+	if p != nil {
+		ctx, cancel := context.WithTimeout(ctx, titleTimeout)
+		defer cancel()
+		title, err := p.Complete(ctx, ai.Request{Messages: []ai.Message{
+			{Role: ai.RoleSystem, Content: titlePrompt},
+			{Role: ai.RoleUser, Content: "User: " + prompt + "\n\nAssistant: " + reply},
+		}})
+		if title = clean(title); err == nil && title != "" {
+			return title
+		}
+	}
+	return clean(prompt)
+}
+
+// clean reduces s to a single line of at most maxTitleWords words.
+func clean(s string) string {
+	words := strings.Fields(s)
+	if len(words) > maxTitleWords {
+		words = words[:maxTitleWords]
+	}
+	title := strings.Join(words, " ")
+	title = strings.Trim(title, "\"'`*#")
+	return strings.TrimRight(title, ".:;!?,")
+}
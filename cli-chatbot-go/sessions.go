@@ -0,0 +1,153 @@
+package main
+
+import (
+	"cli_chatbot_go/ai"
+	"cli_chatbot_go/attach"
+	"cli_chatbot_go/session"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// replayMessages is how many messages of a loaded session are shown again.
+const replayMessages = 4
+
+var (
+	store   session.Store
+	current *session.Session
+)
+
+// openSessions sets up the session store and starts a new session, or
+// continues the most recent one when resume is set.
+func openSessions(resume bool) error {
+	current = session.New(time.Now())
+	dir, err := session.DefaultDir()
+	if err != nil {
+		return err
+	}
+	store = session.Store{Dir: dir}
+	if !resume {
+		return nil
+	}
+	s, err := store.Latest()
+	if err != nil {
+		return err
+	}
+	use(s)
+	return nil
+}
+
+// use makes s the current session and hands its messages, persona and
+// settings to the AI.
+func use(s *session.Session) {
+	current = s
+	ai.SetHistory(s.Messages)
+	applySettings(s)
+}
+
+// saveExchange stores the conversation after a reply, naming the session
+// from its first exchange.
+func saveExchange() {
+	current.Messages = ai.History()
+	if len(current.Messages) == 0 && current.Title == "" {
+		return // never saved
+	}
+	if p := ai.Current(); p != nil {
+		current.Provider, current.Model = p.Name(), p.Model()
+	}
+	current.Persona, current.Settings = currentPersona, ai.CurrentSettings()
+	if current.Title == "" && len(current.Messages) >= 2 {
+		current.Title = session.Title(context.Background(), ai.Current(), attach.Strip(current.Messages[0].Content), current.Messages[1].Content)
+	}
+	current.Updated = time.Now()
+	if err := store.Save(current); err != nil {
+		log.Printf("Saving session: %v\n", err)
+	}
+}
+
+// saveCommand runs /save [name].
+func saveCommand(ctx context.Context, args []string) (string, error) {
+	if len(current.Messages) == 0 {
+		return "Nothing to save yet - start chatting first.", nil
+	}
+	if len(args) == 1 {
+		if err := store.Rename(current, args[0]); err != nil {
+			return "", err
+		}
+	}
+	current.Updated = time.Now()
+	if err := store.Save(current); err != nil {
+		return "", fmt.Errorf("could not save the session: %w", err)
+	}
+	return fmt.Sprintf("Saved session %s%s%s.", bold, current.Name, colorReset), nil
+}
+
+// loadCommand runs /load <name>.
+func loadCommand(ctx context.Context, args []string) (string, error) {
+	s, err := store.Load(args[0])
+	if err != nil {
+		return "", err
+	}
+	use(s)
+	printWelcome()
+	replay(s)
+	return "", nil
+}
+
+// renameCommand runs /rename <name>.
+func renameCommand(ctx context.Context, args []string) (string, error) {
+	if err := store.Rename(current, args[0]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("This session is now called %s%s%s.", bold, current.Name, colorReset), nil
+}
+
+// replay shows where a resumed session left off.
+func replay(s *session.Session) {
+	note := fmt.Sprintf("Resumed %s%s%s (%s, %d messages)", bold, s.Name, colorReset, titleOf(s), len(s.Messages))
+	if p := ai.Current(); p != nil && s.Model != "" && s.Model != p.Model() {
+		note += fmt.Sprintf("\n   Saved with %s, continuing with %s.", s.Model, p.Model())
+	}
+	printMessage(note, true)
+
+	msgs := s.Messages
+	if len(msgs) > replayMessages {
+		msgs = msgs[len(msgs)-replayMessages:]
+	}
+	for _, m := range msgs {
+		if m.Role == ai.RoleSystem {
+			continue // summaries of older messages
+		}
+		printMessage(attach.Strip(m.Content), m.Role == ai.RoleAssistant)
+	}
+}
+
+// sessionsCommand runs /sessions.
+func sessionsCommand(ctx context.Context, args []string) (string, error) {
+	sessions, err := store.List()
+	if err != nil {
+		return "", fmt.Errorf("could not list sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return "No saved sessions yet.", nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Saved sessions %s(%s)%s", dim, store.Dir, colorReset)
+	for _, s := range sessions {
+		fmt.Fprintf(&b, "\n  %s•%s %s%s%s  %s  %s%d messages, %s%s",
+			colorGreen, colorReset, bold, s.Name, colorReset, titleOf(s), dim, len(s.Messages), s.Updated.Format("Jan 2 15:04"), colorReset)
+		if s.Name == current.Name {
+			fmt.Fprintf(&b, " %s(current)%s", colorPurple, colorReset)
+		}
+	}
+	return b.String(), nil
+}
+
+func titleOf(s *session.Session) string {
+	if s.Title == "" {
+		return "untitled"
+	}
+	return s.Title
+}
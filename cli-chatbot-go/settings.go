@@ -0,0 +1,138 @@
+package main
+
+import (
+	"cli_chatbot_go/ai"
+	"cli_chatbot_go/persona"
+	"cli_chatbot_go/session"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+var (
+	personas       map[string]persona.Persona
+	currentPersona = persona.Default
+)
+
+// loadPersonas reads the built-in personas and the user's own.
+func loadPersonas() {
+	dir, err := persona.Dir()
+	if err != nil {
+		log.Printf("Personas: %v\n", err)
+	}
+	if personas, err = persona.Load(dir); err != nil {
+		log.Printf("Personas: %v\n", err)
+	}
+}
+
+// applySettings switches to the persona and generation settings saved
+// with s.
+func applySettings(s *session.Session) {
+	name := s.Persona
+	if name == "" {
+		name = persona.Default
+	}
+	if err := usePersona(name); err != nil {
+		log.Printf("%v, using the default persona\n", err)
+		usePersona(persona.Default)
+	}
+	ai.SetSettings(s.Settings)
+}
+
+func usePersona(name string) error {
+	p, ok := personas[name]
+	if !ok {
+		return fmt.Errorf("unknown persona %q (personas: %s)", name, strings.Join(persona.Names(personas), ", "))
+	}
+	currentPersona = name
+	ai.SetSystemPrompt(p.Prompt)
+	return nil
+}
+
+// rememberSettings stores the persona and settings with the current
+// session, saving it if it has been saved before.
+func rememberSettings() {
+	current.Persona = currentPersona
+	current.Settings = ai.CurrentSettings()
+	if len(current.Messages) == 0 {
+		return
+	}
+	current.Updated = time.Now()
+	if err := store.Save(current); err != nil {
+		log.Printf("Saving session: %v\n", err)
+	}
+}
+
+// personaCommand runs /persona [name].
+func personaCommand(ctx context.Context, args []string) (string, error) {
+	if len(args) == 0 {
+		return listPersonas(), nil
+	}
+	if err := usePersona(args[0]); err != nil {
+		return "", err
+	}
+	rememberSettings()
+	return fmt.Sprintf("Persona set to %s%s%s. The conversation so far is kept.", bold, currentPersona, colorReset), nil
+}
+
+// setCommand runs /set [<setting> <value>].
+func setCommand(ctx context.Context, args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return listSettings(), nil
+	case 1:
+		return "", fmt.Errorf("Usage: /set <%s> <value|default>", strings.Join(ai.SettingNames, "|"))
+	}
+	s := ai.CurrentSettings()
+	if err := s.Set(args[0], args[1]); err != nil {
+		return "", err
+	}
+	ai.SetSettings(s)
+	rememberSettings()
+	return fmt.Sprintf("%s set to %s%s%s.", args[0], bold, s.Get(args[0]), colorReset), nil
+}
+
+func listPersonas() string {
+	var b strings.Builder
+	b.WriteString("Personas")
+	if dir, err := persona.Dir(); err == nil {
+		fmt.Fprintf(&b, " %s(add your own as .md files in %s)%s", dim, dir, colorReset)
+	}
+	names := persona.Names(personas)
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s•%s %s%-*s%s  %s%s%s", colorGreen, colorReset, bold, width, name, colorReset, dim, summary(personas[name]), colorReset)
+		if name == currentPersona {
+			fmt.Fprintf(&b, " %s(current)%s", colorPurple, colorReset)
+		}
+	}
+	b.WriteString("\n\n   Switch with /persona <name>")
+	return b.String()
+}
+
+// summaryWidth caps the persona descriptions in /persona.
+const summaryWidth = 60
+
+func summary(p persona.Persona) string {
+	s := []rune(p.Summary())
+	if len(s) > summaryWidth {
+		return string(s[:summaryWidth-3]) + "..."
+	}
+	return string(s)
+}
+
+func listSettings() string {
+	s := ai.CurrentSettings()
+	var b strings.Builder
+	b.WriteString("Generation settings")
+	for _, name := range ai.SettingNames {
+		fmt.Fprintf(&b, "\n  %s•%s %-12s %s%s%s", colorGreen, colorReset, name, bold, s.Get(name), colorReset)
+	}
+	b.WriteString("\n\n   Change with /set <name> <value>, or /set <name> default")
+	return b.String()
+}
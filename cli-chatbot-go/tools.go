@@ -0,0 +1,172 @@
+package main
+
+import (
+	"cli_chatbot_go/ai"
+	"cli_chatbot_go/lineedit"
+	"cli_chatbot_go/tools"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// toolWidth caps the arguments and results shown for a tool call.
+const toolWidth = 60
+
+// useTools offers the local tools to the model, unless AI_TOOLS=false.
+// confirm asks about dangerous calls; without it they are declined.
+func useTools(confirm func(ai.ToolCall) bool, show func(ai.ToolCall, string, error)) {
+	if os.Getenv("AI_TOOLS") == "false" {
+		return
+	}
+	ai.SetToolUse(ai.ToolUse{Registry: ai.NewToolRegistry(tools.All()...), Confirm: confirm, Show: show})
+}
+
+// clip cuts text to one line of at most width characters.
+func clip(text string, width int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if r := []rune(text); len(r) > width {
+		text = string(r[:width-3]) + "..."
+	}
+	return text
+}
+
+// toolSummary sums up how a call went in a few words.
+func toolSummary(result string, err error) string {
+	switch {
+	case errors.Is(err, ai.ErrDeclined):
+		return "declined"
+	case err != nil:
+		return "failed: " + clip(err.Error(), toolWidth)
+	}
+	if n := strings.Count(strings.TrimRight(result, "\n"), "\n") + 1; n > 1 {
+		return fmt.Sprintf("%d lines", n)
+	}
+	return clip(result, toolWidth)
+}
+
+// showTool puts a call in the transcript, inside the reply box.
+func showTool(call ai.ToolCall, result string, err error) {
+	reply.note(fmt.Sprintf("⚙ %s %s → %s", call.Name, clip(string(call.Args), toolWidth), toolSummary(result, err)))
+}
+
+// confirmTool returns the Confirm of the chat: it asks whether a dangerous
+// call may run, at the prompt of ed. Only y or yes allows it.
+func confirmTool(ed *lineedit.Editor) func(ai.ToolCall) bool {
+	return func(call ai.ToolCall) bool {
+		reply.note("")
+		prompt := fmt.Sprintf("%s%s│%s  %s⚠ %s wants to run %s%s%s. Allow? [y/N]%s ",
+			dim, colorGray, colorReset, colorOrange, call.Name, bold, clip(string(call.Args), 2*toolWidth), colorReset+colorOrange, colorReset)
+		answer, err := ed.Ask(prompt)
+		if reply != nil {
+			reply.midLine = false
+		}
+		if err != nil {
+			return false
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes"
+	}
+}
+
+// showToolPlain reports calls on w, for -p.
+func showToolPlain(w io.Writer) func(ai.ToolCall, string, error) {
+	return func(call ai.ToolCall, result string, err error) {
+		if errors.Is(err, ai.ErrDeclined) {
+			err = errors.New("declined: dangerous tools need the chat to confirm them")
+		}
+		fmt.Fprintf(w, "chatbot: tool %s %s → %s\n", call.Name, clip(string(call.Args), toolWidth), toolSummary(result, err))
+	}
+}
+
+// toolsCommand runs /tools, listing the tools the model can call.
+func toolsCommand(ctx context.Context, args []string) (string, error) {
+	if os.Getenv("AI_TOOLS") == "false" {
+		return "Tools are off (AI_TOOLS=false).", nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%sTools the model can call%s", bold, colorReset)
+	for _, t := range tools.All() {
+		summary, _, _ := strings.Cut(t.Description, ". ")
+		fmt.Fprintf(&b, "\n  %s%-12s%s %s", colorGreen, t.Name, colorReset, strings.TrimSuffix(summary, "."))
+		if t.Dangerous {
+			fmt.Fprintf(&b, " %s(asks first)%s", colorOrange, colorReset)
+		}
+	}
+	if p := ai.Current(); p != nil && !ai.ToolsEnabled() {
+		fmt.Fprintf(&b, "\n  %s%s does not support tools, so they are not offered.%s", dim, p.Model(), colorReset)
+	}
+	return b.String(), nil
+}
+
+// replyBox is the assistant's box a reply is streamed into, opened when
+// the first text or tool call arrives.
+type replyBox struct {
+	thinking  chan bool // stops the spinner shown until then
+	timestamp string
+	open      bool // the header is printed
+	midLine   bool // the last line printed in the box is not finished
+}
+
+// reply is the box being streamed into, nil between replies.
+var reply *replyBox
+
+func newReplyBox(thinking chan bool) *replyBox {
+	return &replyBox{thinking: thinking, timestamp: time.Now().Format("15:04")}
+}
+
+// start stops the spinner and prints the header, once.
+func (b *replyBox) start() {
+	if b.open {
+		return
+	}
+	b.thinking <- true
+	// Clear the spinner header and print the real one in its place.
+	fmt.Print("\033[1A\r\033[K")
+	fmt.Printf("%s%s┌─ %sAssistant %s• %s%s%s\n", dim, colorGray, colorBlue, colorGray, b.timestamp, colorReset, colorReset)
+	b.open = true
+}
+
+// write prints a chunk of the reply.
+func (b *replyBox) write(chunk string) {
+	b.start()
+	if !b.midLine {
+		fmt.Printf("%s%s│%s  ", dim, colorGray, colorReset)
+		b.midLine = true
+	}
+	fmt.Print(chunk)
+}
+
+// note prints a dim line of its own in the box; an empty one only makes
+// sure the next output starts on a new line.
+func (b *replyBox) note(text string) {
+	if b == nil {
+		return
+	}
+	b.start()
+	if b.midLine {
+		fmt.Println()
+		b.midLine = false
+	}
+	if text != "" {
+		fmt.Printf("%s%s│  %s%s\n", dim, colorGray, text, colorReset)
+	}
+}
+
+// close ends the box, after mark if one is given.
+func (b *replyBox) close(mark string) {
+	if mark != "" {
+		if b.midLine {
+			fmt.Printf(" %s%s[%s]%s", dim, colorGray, mark, colorReset)
+		} else {
+			b.note("[" + mark + "]")
+		}
+	}
+	if b.midLine {
+		fmt.Println()
+	}
+	fmt.Printf("%s%s└─%s\n\n", dim, colorGray, colorReset)
+}
@@ -0,0 +1,256 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cli_chatbot_go/ai"
+)
+
+var calculate = ai.Tool{
+	Name: "calculate",
+	Description: "Evaluate an arithmetic expression exactly rather than guessing. Supports + - * / % ^, parentheses, " +
+		"the constants pi and e, and sqrt, abs, ln, log (base 10), exp, sin, cos, tan, floor, ceil, round, min and max.",
+	Parameters: object(1, "expression", "the expression, such as (2 + 3) * sqrt(16)"),
+	Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+		a, err := decode[struct{ Expression string }](args)
+		if err != nil {
+			return "", err
+		}
+		v, err := eval(a.Expression)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	},
+}
+
+var constants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+var functions = map[string]func(...float64) (float64, error){
+	"sqrt":  unary(math.Sqrt),
+	"abs":   unary(math.Abs),
+	"ln":    unary(math.Log),
+	"log":   unary(math.Log10),
+	"exp":   unary(math.Exp),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"round": unary(math.Round),
+	"min":   variadic(math.Min),
+	"max":   variadic(math.Max),
+}
+
+func unary(f func(float64) float64) func(...float64) (float64, error) {
+	return func(args ...float64) (float64, error) {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("takes 1 argument, not %d", len(args))
+		}
+		return f(args[0]), nil
+	}
+}
+
+func variadic(f func(x, y float64) float64) func(...float64) (float64, error) {
+	return func(args ...float64) (float64, error) {
+		if len(args) == 0 {
+			return 0, errors.New("takes at least 1 argument")
+		}
+		v := args[0]
+		for _, a := range args[1:] {
+			v = f(v, a)
+		}
+		return v, nil
+	}
+}
+
+// eval evaluates an arithmetic expression. ^ binds tightest and to the
+// right, so -2^2 is -4 and 2^3^2 is 512.
+func eval(expr string) (float64, error) {
+	p := &parser{src: expr}
+	v, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if p.skip(); p.pos < len(p.src) {
+		return 0, fmt.Errorf("unexpected %q at %d", p.src[p.pos:], p.pos+1)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errors.New("the result is not a finite number")
+	}
+	return v, nil
+}
+
+// parser evaluates as it parses, by recursive descent:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = unary { ("*" | "/" | "%") unary }
+//	unary  = ("+" | "-") unary | power
+//	power  = atom [ "^" unary ]
+//	atom   = number | name [ "(" expr { "," expr } ")" ] | "(" expr ")"
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) skip() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// next consumes c if it is the next character.
+func (p *parser) next(c byte) bool {
+	p.skip()
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expr() (float64, error) {
+	v, err := p.term()
+	for err == nil {
+		var w float64
+		switch {
+		case p.next('+'):
+			w, err = p.term()
+			v += w
+		case p.next('-'):
+			w, err = p.term()
+			v -= w
+		default:
+			return v, nil
+		}
+	}
+	return 0, err
+}
+
+func (p *parser) term() (float64, error) {
+	v, err := p.unary()
+	for err == nil {
+		var w float64
+		switch {
+		case p.next('*'):
+			w, err = p.unary()
+			v *= w
+		case p.next('/'):
+			if w, err = p.unary(); err == nil && w == 0 {
+				return 0, errors.New("division by zero")
+			}
+			v /= w
+		case p.next('%'):
+			if w, err = p.unary(); err == nil && w == 0 {
+				return 0, errors.New("division by zero")
+			}
+			v = math.Mod(v, w)
+		default:
+			return v, nil
+		}
+	}
+	return 0, err
+}
+
+func (p *parser) unary() (float64, error) {
+	switch {
+	case p.next('+'):
+		return p.unary()
+	case p.next('-'):
+		v, err := p.unary()
+		return -v, err
+	}
+	return p.power()
+}
+
+func (p *parser) power() (float64, error) {
+	v, err := p.atom()
+	if err != nil || !p.next('^') {
+		return v, err
+	}
+	w, err := p.unary()
+	return math.Pow(v, w), err
+}
+
+func (p *parser) atom() (float64, error) {
+	p.skip()
+	if p.pos == len(p.src) {
+		return 0, errors.New("unexpected end of expression")
+	}
+	start := p.pos
+	switch c := p.src[p.pos]; {
+	case c == '(':
+		p.pos++
+		v, err := p.expr()
+		if err == nil && !p.next(')') {
+			err = fmt.Errorf("missing ) for the ( at %d", start+1)
+		}
+		return v, err
+	case c == '.' || isDigit(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		// An exponent, as in 1.5e3, but not the constant e in 2e.
+		if rest := p.src[p.pos:]; len(rest) > 1 && (rest[0] == 'e' || rest[0] == 'E') {
+			exp := strings.TrimLeft(rest[1:], "+-")
+			if len(rest)-len(exp) <= 2 && exp != "" && isDigit(exp[0]) {
+				p.pos += len(rest) - len(exp)
+				for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+					p.pos++
+				}
+			}
+		}
+		v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad number %q", p.src[start:p.pos])
+		}
+		return v, nil
+	case unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (unicode.IsLetter(rune(p.src[p.pos])) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		return p.name(strings.ToLower(p.src[start:p.pos]))
+	}
+	return 0, fmt.Errorf("unexpected %q at %d", p.src[p.pos:p.pos+1], p.pos+1)
+}
+
+// name evaluates a constant or a call to a function.
+func (p *parser) name(name string) (float64, error) {
+	f, ok := functions[name]
+	if !ok {
+		if v, ok := constants[name]; ok {
+			return v, nil
+		}
+		return 0, fmt.Errorf("unknown name %q", name)
+	}
+	if !p.next('(') {
+		return 0, fmt.Errorf("%s needs its arguments in parentheses", name)
+	}
+	var args []float64
+	for !p.next(')') {
+		if len(args) > 0 && !p.next(',') {
+			return 0, fmt.Errorf("missing ) after the arguments of %s", name)
+		}
+		v, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, v)
+	}
+	v, err := f(args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s %w", name, err)
+	}
+	return v, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
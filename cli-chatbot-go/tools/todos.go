@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cli_chatbot_go/ai"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// todo is an item of the todo list kept by the CLI-cobra todo app, with
+// the fields worth telling the model about.
+type todo struct {
+	Text     string
+	Priority int // 1 high, 2 medium, 3 low
+	Done     bool
+	State    string
+	Due      time.Time
+	Assignee string
+}
+
+// todoFile is the todo list todos reads: $TODO_FILE if set, otherwise
+// the one the todo app uses. That is $CLI_COBRA_DATAFILE, the datafile
+// key of ~/.cli-cobra.yaml or, by default, ~/.todo.json.
+func todoFile() (string, error) {
+	if path := os.Getenv("TODO_FILE"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	path := os.Getenv("CLI_COBRA_DATAFILE")
+	if path == "" {
+		path = configuredFile(home)
+	}
+	if path == "" {
+		return filepath.Join(home, ".todo.json"), nil
+	}
+	// The todo app expands a leading ~ in the paths it is given.
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || os.IsPathSeparator(rest[0])) {
+		path = filepath.Join(home, rest)
+	}
+	return path, nil
+}
+
+// configuredFile is the datafile set in the todo app's config file in
+// home, or "" if there is none.
+func configuredFile(home string) string {
+	data, err := os.ReadFile(filepath.Join(home, ".cli-cobra.yaml"))
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		DataFile string `yaml:"datafile"`
+	}
+	if yaml.Unmarshal(data, &config) != nil {
+		return ""
+	}
+	return config.DataFile
+}
+
+// readTodos reads the items in path, which is either a bare array, as
+// the todo app first wrote it, or {"Version": 2, "Items": [...]}.
+func readTodos(path string) ([]todo, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("there is no todo list at %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []todo
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &items)
+	} else {
+		var file struct{ Items []todo }
+		err = json.Unmarshal(data, &file)
+		items = file.Items
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return items, nil
+}
+
+var todos = ai.Tool{
+	Name:        "todos",
+	Description: "List the items of the user's todo list, numbered as the todo app numbers them.",
+	Parameters: object(0,
+		"status", "open (the default), done or all",
+		"search", "only items whose text contains this, ignoring case"),
+	Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+		a, err := decode[struct{ Status, Search string }](args)
+		if err != nil {
+			return "", err
+		}
+		status := strings.ToLower(a.Status)
+		switch status {
+		case "":
+			status = "open"
+		case "open", "done", "all":
+		default:
+			return "", fmt.Errorf("unknown status %q: use open, done or all", a.Status)
+		}
+		path, err := todoFile()
+		if err != nil {
+			return "", err
+		}
+		items, err := readTodos(path)
+		if err != nil {
+			return "", err
+		}
+
+		var b strings.Builder
+		for i, t := range items {
+			if (status == "open" && t.Done) || (status == "done" && !t.Done) ||
+				!strings.Contains(strings.ToLower(t.Text), strings.ToLower(a.Search)) {
+				continue
+			}
+			fmt.Fprintf(&b, "%d. %s\n", i+1, describe(t))
+		}
+		if b.Len() == 0 {
+			return "no matching items", nil
+		}
+		return b.String(), nil
+	},
+}
+
+// describe is a line about t, such as "[ ] Buy milk (high priority, due
+// 2026-10-20)".
+func describe(t todo) string {
+	box := "[ ]"
+	if t.Done {
+		box = "[x]"
+	}
+	var notes []string
+	switch t.Priority {
+	case 1:
+		notes = append(notes, "high priority")
+	case 3:
+		notes = append(notes, "low priority")
+	}
+	if t.State != "" && !t.Done {
+		notes = append(notes, t.State)
+	}
+	if !t.Due.IsZero() {
+		notes = append(notes, "due "+t.Due.Format(time.DateOnly))
+	}
+	if t.Assignee != "" {
+		notes = append(notes, "assigned to "+t.Assignee)
+	}
+	if len(notes) == 0 {
+		return box + " " + t.Text
+	}
+	return fmt.Sprintf("%s %s (%s)", box, t.Text, strings.Join(notes, ", "))
+}
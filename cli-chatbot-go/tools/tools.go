@@ -0,0 +1,288 @@
+// Package tools holds the local tools the model can call: files in the
+// working directory, the shell, a calculator, the clock and the todo list.
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"cli_chatbot_go/ai"
+)
+
+// ShellTimeout caps how long a command run by run_shell may take.
+const ShellTimeout = 30 * time.Second
+
+// maxRead caps how much of a file read_file reads, and how much output
+// run_shell keeps, in bytes.
+const maxRead = 64 << 10
+
+// All returns every tool, in the order they are offered to the model.
+func All() []ai.Tool {
+	return []ai.Tool{readFile, listDir, writeFile, runShell, calculate, currentTime, todos}
+}
+
+// object is the JSON schema of an arguments object. props alternates
+// names and descriptions of string properties; the first required are
+// required.
+func object(required int, props ...string) map[string]any {
+	properties := map[string]any{}
+	var names []string
+	for i := 0; i+1 < len(props); i += 2 {
+		properties[props[i]] = map[string]any{"type": "string", "description": props[i+1]}
+		names = append(names, props[i])
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	if required > 0 {
+		schema["required"] = names[:required]
+	}
+	return schema
+}
+
+// decode unmarshals the arguments of a call.
+func decode[T any](args json.RawMessage) (T, error) {
+	var v T
+	if err := json.Unmarshal(args, &v); err != nil {
+		return v, fmt.Errorf("bad arguments: %w", err)
+	}
+	return v, nil
+}
+
+// resolve returns the path name refers to, relative to the working
+// directory, and fails if it leads outside it, following symlinks.
+func resolve(name string) (string, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = "."
+	}
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+	outside := fmt.Errorf("%s is outside the working directory", name)
+	if !within(root, path) {
+		return "", outside
+	}
+
+	// The file may not exist yet, so check the nearest directory that does.
+	real, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	for p := path; ; p = filepath.Dir(p) {
+		if target, err := filepath.EvalSymlinks(p); err == nil {
+			if !within(real, target) {
+				return "", outside
+			}
+			break
+		}
+		if p == filepath.Dir(p) {
+			break
+		}
+	}
+	return path, nil
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+var readFile = ai.Tool{
+	Name:        "read_file",
+	Description: "Read a text file in the working directory.",
+	Parameters:  object(1, "path", "path of the file, relative to the working directory"),
+	Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+		a, err := decode[struct{ Path string }](args)
+		if err != nil {
+			return "", err
+		}
+		path, err := resolve(a.Path)
+		if err != nil {
+			return "", err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err == nil && info.IsDir() {
+			return "", fmt.Errorf("%s is a directory; use list_dir", a.Path)
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxRead+1))
+		if err != nil {
+			return "", err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return "", fmt.Errorf("%s is not a text file", a.Path)
+		}
+		if len(data) > maxRead {
+			return strings.ToValidUTF8(string(data[:maxRead]), "") + "\n[file truncated]", nil
+		}
+		return string(data), nil
+	},
+}
+
+var listDir = ai.Tool{
+	Name:        "list_dir",
+	Description: "List a directory in the working directory. Directories end in a slash; files show their size.",
+	Parameters:  object(0, "path", "path of the directory, relative to the working directory; defaults to the working directory"),
+	Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+		a, err := decode[struct{ Path string }](args)
+		if err != nil {
+			return "", err
+		}
+		path, err := resolve(a.Path)
+		if err != nil {
+			return "", err
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return "", err
+		}
+		if len(entries) == 0 {
+			return "(empty)", nil
+		}
+		var b strings.Builder
+		for _, e := range entries {
+			// Stat follows symlinks, so a link to a directory lists as one.
+			switch info, err := os.Stat(filepath.Join(path, e.Name())); {
+			case err == nil && info.IsDir():
+				fmt.Fprintf(&b, "%s/\n", e.Name())
+			case err == nil && info.Mode().IsRegular():
+				fmt.Fprintf(&b, "%s (%d bytes)\n", e.Name(), info.Size())
+			default:
+				fmt.Fprintf(&b, "%s\n", e.Name())
+			}
+		}
+		return b.String(), nil
+	},
+}
+
+var writeFile = ai.Tool{
+	Name:        "write_file",
+	Description: "Create or replace a file in the working directory.",
+	Parameters: object(2,
+		"path", "path of the file, relative to the working directory",
+		"content", "the whole new content of the file"),
+	Dangerous: true,
+	Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+		a, err := decode[struct{ Path, Content string }](args)
+		if err != nil {
+			return "", err
+		}
+		if a.Path == "" {
+			return "", errors.New("no path given")
+		}
+		path, err := resolve(a.Path)
+		if err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(a.Content), 0644); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("wrote %d bytes to %s", len(a.Content), a.Path), nil
+	},
+}
+
+var runShell = ai.Tool{
+	Name:        "run_shell",
+	Description: fmt.Sprintf("Run a shell command in the working directory and return its output. Commands are stopped after %s.", ShellTimeout),
+	Parameters:  object(1, "command", "the command line"),
+	Dangerous:   true,
+	Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+		a, err := decode[struct{ Command string }](args)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(a.Command) == "" {
+			return "", errors.New("no command given")
+		}
+		ctx, cancel := context.WithTimeout(ctx, ShellTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "sh", "-c", a.Command)
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", a.Command)
+		}
+		out := &capped{limit: maxRead}
+		cmd.Stdout, cmd.Stderr = out, out
+		// Do not wait on background processes that hold the output open.
+		cmd.WaitDelay = time.Second
+		err = cmd.Run()
+		if ctx.Err() == context.DeadlineExceeded {
+			return out.String(), fmt.Errorf("timed out after %s", ShellTimeout)
+		}
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return out.String() + fmt.Sprintf("\n[exit status %d]", exit.ExitCode()), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if out.buf.Len() == 0 {
+			return "(no output)", nil
+		}
+		return out.String(), nil
+	},
+}
+
+// capped keeps the first limit bytes written to it and drops the rest.
+type capped struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (c *capped) Write(p []byte) (int, error) {
+	if room := c.limit - c.buf.Len(); len(p) > room {
+		c.buf.Write(p[:max(room, 0)])
+		c.truncated = true
+		return len(p), nil
+	}
+	return c.buf.Write(p)
+}
+
+func (c *capped) String() string {
+	if c.truncated {
+		return strings.ToValidUTF8(c.buf.String(), "") + "\n[output truncated]"
+	}
+	return c.buf.String()
+}
+
+// now is the clock current_time reads.
+var now = time.Now
+
+var currentTime = ai.Tool{
+	Name:        "current_time",
+	Description: "Get the current date and time, in the local time zone or the one given.",
+	Parameters:  object(0, "timezone", "an IANA time zone such as Europe/Paris; defaults to the local one"),
+	Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+		a, err := decode[struct{ Timezone string }](args)
+		if err != nil {
+			return "", err
+		}
+		loc := time.Local
+		if a.Timezone != "" {
+			if loc, err = time.LoadLocation(a.Timezone); err != nil {
+				return "", fmt.Errorf("unknown time zone %q", a.Timezone)
+			}
+		}
+		return now().In(loc).Format("Monday 2 January 2006, 15:04:05 MST (UTC-07:00)"), nil
+	},
+}
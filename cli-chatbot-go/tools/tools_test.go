@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"cli_chatbot_go/ai"
+)
+
+// run calls tool with args and returns what the model would be sent.
+func run(tool ai.Tool, args string) string {
+	out, err := tool.Run(context.Background(), json.RawMessage(args))
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return out
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret"), []byte("hidden"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "image.png"), []byte("\x89PNG\x00\x00"), 0644)
+	os.Mkdir(filepath.Join(dir, "src"), 0755)
+	if err := os.Symlink(outside, filepath.Join(dir, "escape")); err != nil {
+		t.Logf("no symlinks: %v", err)
+	}
+	t.Chdir(dir)
+
+	tests := []struct {
+		tool       ai.Tool
+		args, want string
+	}{
+		{readFile, `{"path":"notes.txt"}`, "hello\n"},
+		{readFile, `{"path":"./src/../notes.txt"}`, "hello\n"},
+		{readFile, `{"path":"image.png"}`, "error: image.png is not a text file"},
+		{readFile, `{"path":"src"}`, "error: src is a directory; use list_dir"},
+		{readFile, `{"path":"../secret"}`, "error: ../secret is outside the working directory"},
+		{readFile, `{"path":"` + filepath.ToSlash(filepath.Join(outside, "secret")) + `"}`, "error: " + filepath.ToSlash(filepath.Join(outside, "secret")) + " is outside the working directory"},
+		{listDir, `{}`, "escape/\nimage.png (6 bytes)\nnotes.txt (6 bytes)\nsrc/\n"},
+		{listDir, `{"path":"src"}`, "(empty)"},
+		{writeFile, `{"path":"src/new/main.go","content":"package main\n"}`, "wrote 13 bytes to src/new/main.go"},
+		{readFile, `{"path":"src/new/main.go"}`, "package main\n"},
+		{writeFile, `{"path":"../evil","content":"x"}`, "error: ../evil is outside the working directory"},
+	}
+	if _, err := os.Lstat("escape"); err == nil {
+		tests = append(tests, []struct {
+			tool       ai.Tool
+			args, want string
+		}{
+			{readFile, `{"path":"escape/secret"}`, "error: escape/secret is outside the working directory"},
+			{writeFile, `{"path":"escape/new","content":"x"}`, "error: escape/new is outside the working directory"},
+		}...)
+	} else {
+		tests[6].want = strings.TrimPrefix(tests[6].want, "escape/\n")
+	}
+	for _, tt := range tests {
+		if got := run(tt.tool, tt.args); got != tt.want {
+			t.Errorf("%s %s = %q, want %q", tt.tool.Name, tt.args, got, tt.want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new")); err == nil {
+		t.Error("write_file wrote through a symlink out of the working directory")
+	}
+}
+
+func TestShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are for sh")
+	}
+	t.Chdir(t.TempDir())
+	tests := []struct{ command, want string }{
+		{"echo hi; echo err >&2", "hi\nerr\n"},
+		{"true", "(no output)"},
+		{"echo nope; exit 3", "nope\n\n[exit status 3]"},
+		{"pwd", ""}, // checked below
+		{"", "error: no command given"},
+	}
+	for _, tt := range tests {
+		args, _ := json.Marshal(map[string]string{"command": tt.command})
+		got := run(runShell, string(args))
+		if tt.command == "pwd" {
+			if wd, _ := os.Getwd(); strings.TrimSpace(got) != wd {
+				t.Errorf("pwd = %q, want %q", got, wd)
+			}
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+
+	got := run(runShell, `{"command":"yes | head -c 100000"}`)
+	if !strings.HasSuffix(got, "\n[output truncated]") || len(got) > maxRead+100 {
+		t.Errorf("long output: %d bytes ending %q", len(got), got[len(got)-20:])
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 / 4", 2.5},
+		{"10 % 4", 2},
+		{"-2^2", -4},
+		{"2^3^2", 512},
+		{"2 ^ -1", 0.5},
+		{"--3", 3},
+		{"1.5e3 + .5", 1500.5},
+		{"sqrt(16) + abs(-2)", 6},
+		{"max(1, 7, 3) - min(4, 2)", 5},
+		{"round(pi * 100) / 100", 3.14},
+		{"ln(e)", 1},
+		{"log(1000)", 3},
+		{"floor(2.7) + ceil(2.1)", 5},
+	}
+	for _, tt := range tests {
+		got, err := eval(tt.expr)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("eval(%q) = %v, %v, want %v", tt.expr, got, err, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "1 +", "(1 + 2", "1 / 0", "5 % 0", "foo", "sqrt", "sqrt(1, 2)", "min()", "2 $ 3", "2e", "sqrt(-1)", "1..2"} {
+		if v, err := eval(expr); err == nil {
+			t.Errorf("eval(%q) = %v, want an error", expr, v)
+		}
+	}
+
+	if got := run(calculate, `{"expression":"0.1 + 0.2"}`); got != "0.30000000000000004" {
+		t.Errorf("calculate = %q", got)
+	}
+}
+
+func TestCurrentTime(t *testing.T) {
+	t.Cleanup(func() { now = time.Now })
+	now = func() time.Time { return time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC) }
+
+	if got, want := run(currentTime, `{"timezone":"UTC"}`), "Monday 19 October 2026, 12:30:00 UTC (UTC+00:00)"; got != want {
+		t.Errorf("UTC: %q, want %q", got, want)
+	}
+	if got, want := run(currentTime, `{"timezone":"Asia/Tokyo"}`), "Monday 19 October 2026, 21:30:00 JST (UTC+09:00)"; got != want {
+		t.Errorf("Tokyo: %q, want %q", got, want)
+	}
+	if got := run(currentTime, `{"timezone":"Mars/Olympus"}`); got != `error: unknown time zone "Mars/Olympus"` {
+		t.Errorf("unknown zone: %q", got)
+	}
+}
+
+func TestTodos(t *testing.T) {
+	dir := t.TempDir()
+	items := `[
+		{"Text": "Buy milk", "Priority": 1, "Due": "2026-10-20T00:00:00Z"},
+		{"Text": "File taxes", "Priority": 2, "Done": true},
+		{"Text": "Call the bank", "Priority": 3, "State": "waiting", "Assignee": "sam"}
+	]`
+	v1 := filepath.Join(dir, "v1.json")
+	v2 := filepath.Join(dir, "v2.json")
+	os.WriteFile(v1, []byte(items), 0644)
+	os.WriteFile(v2, []byte(`{"Version": 2, "Items": `+items+`}`), 0644)
+
+	tests := []struct{ args, want string }{
+		{`{}`, "1. [ ] Buy milk (high priority, due 2026-10-20)\n3. [ ] Call the bank (low priority, waiting, assigned to sam)\n"},
+		{`{"status":"done"}`, "2. [x] File taxes\n"},
+		{`{"status":"all","search":"BANK"}`, "3. [ ] Call the bank (low priority, waiting, assigned to sam)\n"},
+		{`{"search":"taxes"}`, "no matching items"},
+		{`{"status":"later"}`, `error: unknown status "later": use open, done or all`},
+	}
+	for _, file := range []string{v1, v2} {
+		t.Setenv("TODO_FILE", file)
+		for _, tt := range tests {
+			if got := run(todos, tt.args); got != tt.want {
+				t.Errorf("%s: todos %s = %q, want %q", filepath.Base(file), tt.args, got, tt.want)
+			}
+		}
+	}
+
+	t.Setenv("TODO_FILE", filepath.Join(dir, "missing.json"))
+	if got := run(todos, `{}`); !strings.HasPrefix(got, "error: there is no todo list at ") {
+		t.Errorf("missing file: %q", got)
+	}
+}
+
+func TestTodoFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TODO_FILE", "")
+	t.Setenv("CLI_COBRA_DATAFILE", "")
+	check := func(what, want string) {
+		t.Helper()
+		if got, err := todoFile(); err != nil || got != want {
+			t.Errorf("%s: todoFile = %q, %v, want %q", what, got, err, want)
+		}
+	}
+
+	check("default", filepath.Join(home, ".todo.json"))
+
+	os.WriteFile(filepath.Join(home, ".cli-cobra.yaml"), []byte("color: never\ndatafile: ~/work/todos.json\n"), 0644)
+	check("config file", filepath.Join(home, "work", "todos.json"))
+
+	t.Setenv("CLI_COBRA_DATAFILE", "/srv/todos.json")
+	check("CLI_COBRA_DATAFILE", "/srv/todos.json")
+
+	t.Setenv("TODO_FILE", "/tmp/todos.json")
+	check("TODO_FILE", "/tmp/todos.json")
+
+	t.Setenv("TODO_FILE", "")
+	t.Setenv("CLI_COBRA_DATAFILE", "")
+	os.WriteFile(filepath.Join(home, ".cli-cobra.yaml"), []byte("datafile: [not, a, path\n"), 0644)
+	check("broken config file", filepath.Join(home, ".todo.json"))
+}
+
+func TestAll(t *testing.T) {
+	r := ai.NewToolRegistry(All()...)
+	for _, name := range []string{"write_file", "run_shell"} {
+		if tool, ok := r.Lookup(name); !ok || !tool.Dangerous {
+			t.Errorf("%s is not a dangerous tool", name)
+		}
+	}
+	for _, tool := range All() {
+		if tool.Description == "" || tool.Parameters["type"] != "object" {
+			t.Errorf("%s: description %q, parameters %v", tool.Name, tool.Description, tool.Parameters)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardTools are tried in order; the first one installed is used.
+func clipboardTools() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip.exe"}}
+	}
+	tools := [][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append([][]string{{"wl-copy"}}, tools...)
+	}
+	return tools
+}
+
+// CopyToClipboard puts text on the system clipboard with the first
+// clipboard tool it finds. Without one, as over SSH, it asks the terminal
+// to do it with the OSC 52 escape sequence, written to w. It returns what
+// did the copying.
+func CopyToClipboard(text string, w io.Writer) (string, error) {
+	for _, tool := range clipboardTools() {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(tool[0], tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s: %w", tool[0], err)
+		}
+		return tool[0], nil
+	}
+	fmt.Fprintf(w, "\033]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return "the terminal", nil
+}